@@ -12,14 +12,17 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/zmcp/odata-mcp/internal/auth"
 	"github.com/zmcp/odata-mcp/internal/bridge"
 	"github.com/zmcp/odata-mcp/internal/config"
 	"github.com/zmcp/odata-mcp/internal/debug"
+	"github.com/zmcp/odata-mcp/internal/logging"
 	"github.com/zmcp/odata-mcp/internal/transport"
 	"github.com/zmcp/odata-mcp/internal/transport/http"
 	"github.com/zmcp/odata-mcp/internal/transport/stdio"
@@ -27,6 +30,8 @@ import (
 
 var cfg *config.Config
 
+var logger = logging.Logger("main")
+
 var rootCmd = &cobra.Command{
 	Use:   "odata-mcp [service-url]",
 	Short: "OData to MCP Bridge - Universal OData v2 to Model Context Protocol bridge",
@@ -81,6 +86,9 @@ func init() {
 	rootCmd.Flags().BoolVar(&cfg.Debug, "debug", false, "Alias for --verbose")
 	rootCmd.Flags().BoolVar(&cfg.SortTools, "sort-tools", true, "Sort tools alphabetically in the output")
 	rootCmd.Flags().BoolVar(&cfg.Trace, "trace", false, "Initialize MCP service and print all tools and parameters, then exit (useful for debugging)")
+	rootCmd.Flags().StringVar(&cfg.LogLevel, "log-level", "", "Minimum level of log records on stderr and in the log file: debug, info, notice, warning, error (default: warning, debug with --verbose)")
+	rootCmd.Flags().StringVar(&cfg.LogFile, "log-file", "", "Also append log records to this file")
+	rootCmd.Flags().StringVar(&cfg.LogFormat, "log-format", "text", "Log record format: text or json")
 
 	// Response enhancement options
 	rootCmd.Flags().BoolVar(&cfg.PaginationHints, "pagination-hints", false, "Add pagination support with suggested_next_call and has_more indicators")
@@ -102,7 +110,10 @@ func init() {
 	// Transport options
 	rootCmd.Flags().String("transport", "stdio", "Transport type: 'stdio', 'http' (SSE), or 'streamable-http' (modern MCP)")
 	rootCmd.Flags().String("http-addr", "localhost:8080", "HTTP server address (used with --transport http/streamable-http, defaults to localhost only for security)")
-	rootCmd.Flags().Bool("i-am-security-expert-i-know-what-i-am-doing", false, "DANGEROUS: Allow non-localhost HTTP transport. MCP has no authentication!")
+	rootCmd.Flags().IntVar(&cfg.SessionTimeout, "session-timeout", 1800, "Seconds an idle streamable HTTP session is kept before it expires")
+	rootCmd.Flags().IntVar(&cfg.EventRetention, "event-retention", 300, "Seconds streamable HTTP events are kept for clients resuming with Last-Event-ID")
+	rootCmd.Flags().StringVar(&cfg.EventStoreDir, "event-store-dir", "", "Directory to keep streamable HTTP events in instead of memory")
+	rootCmd.Flags().Bool("i-am-security-expert-i-know-what-i-am-doing", false, "DANGEROUS: Allow non-localhost HTTP transport without --oauth-issuer or --api-key authentication")
 
 	// Debug options
 	rootCmd.Flags().Bool("trace-mcp", false, "Enable trace logging to debug MCP communication")
@@ -118,8 +129,9 @@ func init() {
 	// Claude Code compatibility
 	rootCmd.Flags().BoolVarP(&cfg.ClaudeCodeFriendly, "claude-code-friendly", "c", false, "Remove $ prefix from OData parameters for Claude Code CLI compatibility")
 
-	// Protocol version override (for AI Foundry compatibility)
-	rootCmd.Flags().StringVar(&cfg.ProtocolVersion, "protocol-version", "", "Override MCP protocol version (e.g., '2025-06-18' for AI Foundry)")
+	// Protocol version and compatibility
+	rootCmd.Flags().StringVar(&cfg.ProtocolVersion, "protocol-version", "", "MCP protocol version offered to clients that ask for an unsupported one: 2025-06-18, 2025-03-26 or 2024-11-05 (default: 2025-06-18)")
+	rootCmd.Flags().BoolVar(&cfg.NullIDCompat, "null-id-compat", false, "Answer requests with a null ID with ID 0, for clients that reject null IDs")
 
 	// Retry configuration
 	rootCmd.Flags().IntVar(&cfg.RetryMaxAttempts, "retry-max-attempts", 3, "Maximum retry attempts for failed requests (default: 3)")
@@ -131,23 +143,121 @@ func init() {
 	rootCmd.Flags().IntVar(&cfg.HTTPTimeout, "http-timeout", 30, "HTTP request timeout in seconds (default: 30)")
 	rootCmd.Flags().IntVar(&cfg.MetadataTimeout, "metadata-timeout", 60, "Metadata fetch timeout in seconds (default: 60)")
 
+	// Concurrency
+	rootCmd.Flags().IntVar(&cfg.MaxConcurrentRequests, "max-concurrent-requests", 4, "Requests handled at once by the stdio transport (default: 4)")
+
+	// Resources
+	rootCmd.Flags().IntVar(&cfg.ResourcePollInterval, "resource-poll-interval", 30, "Seconds between ETag checks of subscribed resources (default: 30)")
+
+	// Tool listing
+	rootCmd.Flags().IntVar(&cfg.ToolsPageSize, "tools-page-size", 0, "Tools per tools/list response; clients fetch the rest with nextCursor (0 = all at once)")
+
 	// Lazy metadata mode (token optimization)
 	rootCmd.Flags().BoolVar(&cfg.LazyMetadata, "lazy-metadata", false, "Enable lazy metadata mode: generate 10 generic tools instead of per-entity tools (reduces tokens by ~99%)")
 	rootCmd.Flags().IntVar(&cfg.LazyThreshold, "lazy-threshold", 0, "Auto-enable lazy mode if estimated tool count exceeds this threshold (0 = disabled)")
 
+	// Aggregation
+	rootCmd.Flags().IntVar(&cfg.AggregateMaxRows, "aggregate-max-rows", 10000, "Maximum rows (or $apply groups) read by the aggregate tool (default: 10000)")
+	rootCmd.Flags().IntVar(&cfg.SummarizeMaxRows, "summarize-max-rows", 10000, "Maximum rows read by filter/list tools in summarize mode (default: 10000)")
+
+	// Export
+	rootCmd.Flags().StringVar(&cfg.ExportDir, "export-dir", "", "Directory for export tools to write CSV/JSONL/Parquet files into (export tools are disabled when unset)")
+
+	// Bulk import
+	rootCmd.Flags().StringVar(&cfg.ImportDir, "import-dir", "", "Directory bulk tools read CSV/JSONL files from and write reports into (bulk tools are disabled when unset)")
+	rootCmd.Flags().IntVar(&cfg.BulkChunkSize, "bulk-chunk-size", 50, "Rows per $batch changeset for bulk tools (default: 50)")
+
+	// Dry run
+	rootCmd.Flags().BoolVar(&cfg.DryRun, "dry-run", false, "Return the resolved HTTP request of create/update/delete/function calls instead of sending it")
+
+	// Human confirmation
+	rootCmd.Flags().StringVar(&cfg.Confirm, "confirm", "", "Operations that need user approval before they run: comma-separated delete, update, function, or all (uses MCP elicitation, falls back to a confirm token)")
+
+	// Access policy
+	rootCmd.Flags().StringVar(&cfg.PolicyFile, "policy", "", "Path to an access policy file (YAML or JSON) with per-entity-set and per-function rules")
+
+	// PII redaction
+	rootCmd.Flags().StringVar(&cfg.RedactProperties, "redact-properties", "", "Comma-separated property name globs whose values are redacted in responses (e.g., '*Salary*,IBAN,Bank*')")
+	rootCmd.Flags().StringVar(&cfg.RedactTypes, "redact-types", "", "Comma-separated Edm types whose values are redacted in responses (e.g., 'Edm.Binary')")
+	rootCmd.Flags().StringArrayVar(&cfg.RedactPatterns, "redact-pattern", nil, "Regex for values to redact inside strings, or a built-in name: email, iban (repeatable)")
+	rootCmd.Flags().StringVar(&cfg.RedactMode, "redact-mode", "mask", "How redacted values are replaced: mask or hash (keyed SHA-256, equal values stay comparable)")
+
+	// Audit log
+	rootCmd.Flags().StringVar(&cfg.AuditLog, "audit-log", "", "Write a hash-chained audit record of every tool call to a file, or to syslog (syslog, syslog+udp://host:port, syslog+tcp://host:port)")
+	rootCmd.Flags().IntVar(&cfg.AuditMaxSize, "audit-max-size", 100, "Rotate the audit log file at this size in MB (0 = never)")
+	rootCmd.Flags().IntVar(&cfg.AuditMaxBackups, "audit-max-backups", 0, "Number of rotated audit log files to keep (0 = all)")
+
+	// Change journal
+	rootCmd.Flags().StringVar(&cfg.JournalFile, "journal", "", "Record the before/after state of every create, update and delete in this file and add list_changes and undo_change tools")
+
+	// HTTP transport authentication
+	rootCmd.Flags().StringVar(&cfg.OAuthIssuer, "oauth-issuer", "", "Require bearer JWTs issued by this OAuth authorization server on the HTTP transports")
+	rootCmd.Flags().StringVar(&cfg.OAuthJWKSURL, "oauth-jwks-url", "", "URL of the issuer's signing keys (default: discovered from the issuer metadata)")
+	rootCmd.Flags().StringVar(&cfg.OAuthAudience, "oauth-audience", "", "Audience tokens must be issued for (default: --oauth-resource)")
+	rootCmd.Flags().StringVar(&cfg.OAuthResource, "oauth-resource", "", "Public URL of the MCP endpoint, advertised in the protected resource metadata (e.g., 'https://mcp.example.com/mcp')")
+	rootCmd.Flags().StringVar(&cfg.OAuthScopes, "oauth-scopes", "", "Comma-separated scopes every token must carry")
+	rootCmd.Flags().StringArrayVar(&cfg.APIKeys, "api-key", nil, "Accept this static API key on the HTTP transports, as KEY or NAME=KEY (repeatable)")
+
+	// Per-user credential passthrough
+	rootCmd.Flags().StringVar(&cfg.Passthrough, "passthrough", "", "Call the OData service as the authenticated HTTP caller: forward (their bearer token), exchange (RFC 8693 token exchange) or credentials (--user-credentials)")
+	rootCmd.Flags().StringVar(&cfg.TokenExchangeURL, "token-exchange-url", "", "Token endpoint for --passthrough exchange")
+	rootCmd.Flags().StringVar(&cfg.TokenExchangeClientID, "token-exchange-client-id", "", "Client ID for the token exchange")
+	rootCmd.Flags().StringVar(&cfg.TokenExchangeClientSecret, "token-exchange-client-secret", "", "Client secret for the token exchange (prefer ODATA_TOKEN_EXCHANGE_CLIENT_SECRET)")
+	rootCmd.Flags().StringVar(&cfg.TokenExchangeAudience, "token-exchange-audience", "", "Audience of exchanged tokens (the OData service)")
+	rootCmd.Flags().StringVar(&cfg.TokenExchangeScope, "token-exchange-scope", "", "Scope of exchanged tokens")
+	rootCmd.Flags().StringVar(&cfg.UserCredentialsFile, "user-credentials", "", "YAML/JSON file mapping callers to OData basic credentials for --passthrough credentials")
+
 	// Bind flags to viper for environment variable support
 	viper.BindPFlag("service", rootCmd.Flags().Lookup("service"))
 	viper.BindPFlag("username", rootCmd.Flags().Lookup("user"))
 	viper.BindPFlag("password", rootCmd.Flags().Lookup("password"))
 	viper.BindPFlag("verbose", rootCmd.Flags().Lookup("verbose"))
+	viper.BindPFlag("log_level", rootCmd.Flags().Lookup("log-level"))
+	viper.BindPFlag("log_file", rootCmd.Flags().Lookup("log-file"))
+	viper.BindPFlag("log_format", rootCmd.Flags().Lookup("log-format"))
 	viper.BindPFlag("retry_max_attempts", rootCmd.Flags().Lookup("retry-max-attempts"))
 	viper.BindPFlag("retry_initial_backoff_ms", rootCmd.Flags().Lookup("retry-initial-backoff-ms"))
 	viper.BindPFlag("retry_max_backoff_ms", rootCmd.Flags().Lookup("retry-max-backoff-ms"))
 	viper.BindPFlag("retry_backoff_multiplier", rootCmd.Flags().Lookup("retry-backoff-multiplier"))
 	viper.BindPFlag("http_timeout", rootCmd.Flags().Lookup("http-timeout"))
 	viper.BindPFlag("metadata_timeout", rootCmd.Flags().Lookup("metadata-timeout"))
+	viper.BindPFlag("max_concurrent_requests", rootCmd.Flags().Lookup("max-concurrent-requests"))
+	viper.BindPFlag("resource_poll_interval", rootCmd.Flags().Lookup("resource-poll-interval"))
 	viper.BindPFlag("lazy_metadata", rootCmd.Flags().Lookup("lazy-metadata"))
 	viper.BindPFlag("lazy_threshold", rootCmd.Flags().Lookup("lazy-threshold"))
+	viper.BindPFlag("tools_page_size", rootCmd.Flags().Lookup("tools-page-size"))
+	viper.BindPFlag("aggregate_max_rows", rootCmd.Flags().Lookup("aggregate-max-rows"))
+	viper.BindPFlag("summarize_max_rows", rootCmd.Flags().Lookup("summarize-max-rows"))
+	viper.BindPFlag("export_dir", rootCmd.Flags().Lookup("export-dir"))
+	viper.BindPFlag("import_dir", rootCmd.Flags().Lookup("import-dir"))
+	viper.BindPFlag("bulk_chunk_size", rootCmd.Flags().Lookup("bulk-chunk-size"))
+	viper.BindPFlag("dry_run", rootCmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("confirm", rootCmd.Flags().Lookup("confirm"))
+	viper.BindPFlag("policy_file", rootCmd.Flags().Lookup("policy"))
+	viper.BindPFlag("redact_properties", rootCmd.Flags().Lookup("redact-properties"))
+	viper.BindPFlag("redact_types", rootCmd.Flags().Lookup("redact-types"))
+	viper.BindPFlag("redact_patterns", rootCmd.Flags().Lookup("redact-pattern"))
+	viper.BindPFlag("redact_mode", rootCmd.Flags().Lookup("redact-mode"))
+	viper.BindPFlag("audit_log", rootCmd.Flags().Lookup("audit-log"))
+	viper.BindPFlag("audit_max_size", rootCmd.Flags().Lookup("audit-max-size"))
+	viper.BindPFlag("audit_max_backups", rootCmd.Flags().Lookup("audit-max-backups"))
+	viper.BindPFlag("journal_file", rootCmd.Flags().Lookup("journal"))
+	viper.BindPFlag("session_timeout", rootCmd.Flags().Lookup("session-timeout"))
+	viper.BindPFlag("event_retention", rootCmd.Flags().Lookup("event-retention"))
+	viper.BindPFlag("event_store_dir", rootCmd.Flags().Lookup("event-store-dir"))
+	viper.BindPFlag("oauth_issuer", rootCmd.Flags().Lookup("oauth-issuer"))
+	viper.BindPFlag("oauth_jwks_url", rootCmd.Flags().Lookup("oauth-jwks-url"))
+	viper.BindPFlag("oauth_audience", rootCmd.Flags().Lookup("oauth-audience"))
+	viper.BindPFlag("oauth_resource", rootCmd.Flags().Lookup("oauth-resource"))
+	viper.BindPFlag("oauth_scopes", rootCmd.Flags().Lookup("oauth-scopes"))
+	viper.BindPFlag("api_keys", rootCmd.Flags().Lookup("api-key"))
+	viper.BindPFlag("passthrough", rootCmd.Flags().Lookup("passthrough"))
+	viper.BindPFlag("token_exchange_url", rootCmd.Flags().Lookup("token-exchange-url"))
+	viper.BindPFlag("token_exchange_client_id", rootCmd.Flags().Lookup("token-exchange-client-id"))
+	viper.BindPFlag("token_exchange_client_secret", rootCmd.Flags().Lookup("token-exchange-client-secret"))
+	viper.BindPFlag("token_exchange_audience", rootCmd.Flags().Lookup("token-exchange-audience"))
+	viper.BindPFlag("token_exchange_scope", rootCmd.Flags().Lookup("token-exchange-scope"))
+	viper.BindPFlag("user_credentials_file", rootCmd.Flags().Lookup("user-credentials"))
 
 	// Set up environment variable mapping
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -161,18 +271,32 @@ func runBridge(cmd *cobra.Command, args []string) error {
 		cfg.Verbose = true
 	}
 
+	// Set up the log sinks before anything logs
+	logLevel := logging.DefaultLevel
+	if cfg.Verbose {
+		logLevel = logging.LevelDebug
+	}
+	if cfg.LogLevel != "" {
+		level, err := logging.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return err
+		}
+		logLevel = level
+	}
+	logCloser, err := logging.Setup(logging.Options{Level: logLevel, Format: cfg.LogFormat, File: cfg.LogFile})
+	if err != nil {
+		return err
+	}
+	defer logCloser.Close()
+
 	// Handle legacy dates flags
 	if cfg.NoLegacyDates {
 		cfg.LegacyDates = false
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Legacy date format conversion disabled.\n")
-		}
+		logger.Info("Legacy date format conversion disabled")
 	} else if !cmd.Flags().Changed("legacy-dates") {
 		// Default to legacy dates for SAP compatibility
 		cfg.LegacyDates = true
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Legacy date format enabled by default for SAP compatibility. Use --no-legacy-dates to disable.\n")
-		}
+		logger.Info("Legacy date format enabled by default for SAP compatibility. Use --no-legacy-dates to disable.")
 	}
 
 	// Handle read-only mode flags
@@ -185,29 +309,39 @@ func runBridge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot use both --enable and --disable flags at the same time")
 	}
 
-	if cfg.EnableOps != "" && cfg.Verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Operation filtering enabled. Only these operations will be available: %s\n", strings.ToUpper(cfg.EnableOps))
+	if cfg.EnableOps != "" {
+		logger.Info("Operation filtering enabled, only these operations will be available", "operations", strings.ToUpper(cfg.EnableOps))
 	}
-	if cfg.DisableOps != "" && cfg.Verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Operation filtering enabled. These operations will be disabled: %s\n", strings.ToUpper(cfg.DisableOps))
+	if cfg.DisableOps != "" {
+		logger.Info("Operation filtering enabled, these operations will be disabled", "operations", strings.ToUpper(cfg.DisableOps))
 	}
 
-	if cfg.IsReadOnly() {
-		if cfg.Verbose {
-			if cfg.ReadOnly {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Read-only mode enabled. All modifying operations (create, update, delete, and functions) will be hidden.\n")
-			} else if cfg.ReadOnlyButFunctions {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Read-only mode enabled with function exception. Create, update, and delete operations will be hidden, but function imports will be available.\n")
-			}
-		}
+	if cfg.ReadOnly {
+		logger.Info("Read-only mode enabled. All modifying operations (create, update, delete, and functions) will be hidden.")
+	} else if cfg.ReadOnlyButFunctions {
+		logger.Info("Read-only mode enabled with function exception. Create, update, and delete operations will be hidden, but function imports will be available.")
+	}
+
+	// Validate the confirmation policy
+	if err := cfg.ValidateConfirm(); err != nil {
+		return err
+	}
+	if cfg.Confirm != "" {
+		logger.Info("User confirmation required", "operations", cfg.Confirm)
+	}
+
+	// Credential passthrough needs authenticated callers, which only the HTTP transports have
+	if err := cfg.ValidatePassthrough(); err != nil {
+		return err
+	}
+	if transportType, _ := cmd.Flags().GetString("transport"); cfg.Passthrough != "" && (transportType == "stdio" || transportType == "") {
+		return fmt.Errorf("--passthrough requires an HTTP transport with authentication")
 	}
 
 	// Determine service URL with priority: --service flag > positional arg > env vars
 	if cfg.ServiceURL == "" && len(args) > 0 {
 		cfg.ServiceURL = args[0]
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Using OData service URL from positional argument.\n")
-		}
+		logger.Info("Using OData service URL from positional argument")
 	}
 
 	if cfg.ServiceURL == "" {
@@ -215,8 +349,8 @@ func runBridge(cmd *cobra.Command, args []string) error {
 		if cfg.ServiceURL == "" {
 			cfg.ServiceURL = viper.GetString("SERVICE_URL")
 		}
-		if cfg.ServiceURL != "" && cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Using ODATA_URL from environment.\n")
+		if cfg.ServiceURL != "" {
+			logger.Info("Using ODATA_URL from environment")
 		}
 	}
 
@@ -240,16 +374,12 @@ func runBridge(cmd *cobra.Command, args []string) error {
 	// Parse entity and function filters
 	if cfg.Entities != "" {
 		cfg.AllowedEntities = parseCommaSeparated(cfg.Entities)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Filtering tools to only these entities: %v\n", cfg.AllowedEntities)
-		}
+		logger.Info("Filtering tools to only these entities", "entities", cfg.AllowedEntities)
 	}
 
 	if cfg.Functions != "" {
 		cfg.AllowedFunctions = parseCommaSeparated(cfg.Functions)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Filtering tools to only these functions: %v\n", cfg.AllowedFunctions)
-		}
+		logger.Info("Filtering tools to only these functions", "functions", cfg.AllowedFunctions)
 	}
 
 	// Set up signal handling
@@ -275,6 +405,7 @@ func runBridge(cmd *cobra.Command, args []string) error {
 	if mcpServer == nil {
 		return fmt.Errorf("failed to get MCP server from bridge")
 	}
+	logging.SetNotifier(mcpServer)
 
 	// Set up tracing if requested
 	enableTrace, _ := cmd.Flags().GetBool("trace-mcp")
@@ -299,9 +430,13 @@ func runBridge(cmd *cobra.Command, args []string) error {
 	case "streamable-http", "streamable":
 		httpAddr, _ := cmd.Flags().GetString("http-addr")
 		expertMode, _ := cmd.Flags().GetBool("i-am-security-expert-i-know-what-i-am-doing")
+		authenticator, err := newHTTPAuthenticator(cfg)
+		if err != nil {
+			return err
+		}
 
-		// Security check: ensure localhost-only unless expert mode
-		if !expertMode && !isLocalhostAddr(httpAddr) {
+		// Security check: ensure localhost-only unless authenticated or expert mode
+		if authenticator == nil && !expertMode && !isLocalhostAddr(httpAddr) {
 			fmt.Fprintf(os.Stderr, "\n⚠️  SECURITY WARNING ⚠️\n")
 			fmt.Fprintf(os.Stderr, "Streamable HTTP transport is UNPROTECTED - no authentication!\n")
 			fmt.Fprintf(os.Stderr, "For security, HTTP transport is restricted to localhost only.\n")
@@ -310,12 +445,13 @@ func runBridge(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "  --http-addr localhost:8080\n")
 			fmt.Fprintf(os.Stderr, "  --http-addr 127.0.0.1:8080\n")
 			fmt.Fprintf(os.Stderr, "  --http-addr [::1]:8080\n\n")
-			fmt.Fprintf(os.Stderr, "If you REALLY need to expose this service (DANGEROUS!), use:\n")
+			fmt.Fprintf(os.Stderr, "To expose it with authentication, use --oauth-issuer or --api-key.\n\n")
+			fmt.Fprintf(os.Stderr, "If you REALLY need to expose this service without authentication (DANGEROUS!), use:\n")
 			fmt.Fprintf(os.Stderr, "  --i-am-security-expert-i-know-what-i-am-doing\n\n")
 			return fmt.Errorf("refusing to start unprotected HTTP transport on non-localhost address")
 		}
 
-		if expertMode && !isLocalhostAddr(httpAddr) {
+		if authenticator == nil && expertMode && !isLocalhostAddr(httpAddr) {
 			fmt.Fprintf(os.Stderr, "\n🚨 EXTREME SECURITY WARNING 🚨\n")
 			fmt.Fprintf(os.Stderr, "You are exposing an UNPROTECTED MCP service to the network!\n")
 			fmt.Fprintf(os.Stderr, "MCP has NO authentication mechanism - anyone can connect!\n")
@@ -324,18 +460,28 @@ func runBridge(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "Press Ctrl+C NOW if this is not intentional!\n\n")
 		}
 
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Starting Streamable HTTP transport (protocol 2024-11-05) on %s\n", httpAddr)
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Main endpoint: http://%s/mcp\n", httpAddr)
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Health endpoint: http://%s/health\n", httpAddr)
+		logger.Info("Starting Streamable HTTP transport", "addr", httpAddr,
+			"endpoint", "http://"+httpAddr+"/mcp", "health", "http://"+httpAddr+"/health")
+		streamTrans := http.NewStreamableHTTP(httpAddr, handler, expertMode)
+		streamTrans.SetSessionTimeout(time.Duration(cfg.SessionTimeout) * time.Second)
+		streamTrans.OnSessionClosed(mcpServer.CloseSession)
+		if err := streamTrans.SetEventStore(cfg.EventStoreDir, time.Duration(cfg.EventRetention)*time.Second); err != nil {
+			return err
+		}
+		if authenticator != nil {
+			streamTrans.SetAuthenticator(authenticator)
 		}
-		trans = http.NewStreamableHTTP(httpAddr, handler, expertMode)
+		trans = streamTrans
 	case "http", "sse":
 		httpAddr, _ := cmd.Flags().GetString("http-addr")
 		expertMode, _ := cmd.Flags().GetBool("i-am-security-expert-i-know-what-i-am-doing")
+		authenticator, err := newHTTPAuthenticator(cfg)
+		if err != nil {
+			return err
+		}
 
-		// Security check: ensure localhost-only unless expert mode
-		if !expertMode && !isLocalhostAddr(httpAddr) {
+		// Security check: ensure localhost-only unless authenticated or expert mode
+		if authenticator == nil && !expertMode && !isLocalhostAddr(httpAddr) {
 			fmt.Fprintf(os.Stderr, "\n⚠️  SECURITY WARNING ⚠️\n")
 			fmt.Fprintf(os.Stderr, "HTTP/SSE transport is UNPROTECTED - no authentication!\n")
 			fmt.Fprintf(os.Stderr, "For security, HTTP transport is restricted to localhost only.\n")
@@ -344,12 +490,13 @@ func runBridge(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "  --http-addr localhost:8080\n")
 			fmt.Fprintf(os.Stderr, "  --http-addr 127.0.0.1:8080\n")
 			fmt.Fprintf(os.Stderr, "  --http-addr [::1]:8080\n\n")
-			fmt.Fprintf(os.Stderr, "If you REALLY need to expose this service (DANGEROUS!), use:\n")
+			fmt.Fprintf(os.Stderr, "To expose it with authentication, use --oauth-issuer or --api-key.\n\n")
+			fmt.Fprintf(os.Stderr, "If you REALLY need to expose this service without authentication (DANGEROUS!), use:\n")
 			fmt.Fprintf(os.Stderr, "  --i-am-security-expert-i-know-what-i-am-doing\n\n")
 			return fmt.Errorf("refusing to start unprotected HTTP transport on non-localhost address")
 		}
 
-		if expertMode && !isLocalhostAddr(httpAddr) {
+		if authenticator == nil && expertMode && !isLocalhostAddr(httpAddr) {
 			fmt.Fprintf(os.Stderr, "\n🚨 EXTREME SECURITY WARNING 🚨\n")
 			fmt.Fprintf(os.Stderr, "You are exposing an UNPROTECTED MCP service to the network!\n")
 			fmt.Fprintf(os.Stderr, "MCP has NO authentication mechanism - anyone can connect!\n")
@@ -358,19 +505,18 @@ func runBridge(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "Press Ctrl+C NOW if this is not intentional!\n\n")
 		}
 
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Starting HTTP/SSE transport on %s\n", httpAddr)
-		}
+		logger.Info("Starting HTTP/SSE transport", "addr", httpAddr)
 		sseTrans := http.NewSSE(httpAddr, handler)
-		sseTrans.SetVerbose(cfg.Verbose)
+		if authenticator != nil {
+			sseTrans.SetAuthenticator(authenticator)
+		}
 		trans = sseTrans
 	case "stdio":
 		fallthrough
 	default:
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Using stdio transport\n")
-		}
+		logger.Info("Using stdio transport")
 		stdioTrans := stdio.New(handler)
+		stdioTrans.SetWorkers(cfg.MaxConcurrentRequests)
 		if tracer != nil {
 			stdioTrans.SetTracer(tracer)
 		}
@@ -397,6 +543,37 @@ func runBridge(cmd *cobra.Command, args []string) error {
 	}
 }
 
+// newHTTPAuthenticator builds the HTTP transport authenticator, or nil when none is configured
+func newHTTPAuthenticator(cfg *config.Config) (*auth.Authenticator, error) {
+	if !cfg.HasHTTPAuth() {
+		return nil, nil
+	}
+	var scopes []string
+	for _, s := range strings.Split(cfg.OAuthScopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	authenticator, err := auth.New(auth.Config{
+		Issuer:   cfg.OAuthIssuer,
+		JWKSURL:  cfg.OAuthJWKSURL,
+		Audience: cfg.OAuthAudience,
+		Resource: cfg.OAuthResource,
+		Scopes:   scopes,
+		APIKeys:  cfg.APIKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP authentication configuration: %w", err)
+	}
+	if cfg.OAuthIssuer != "" {
+		logger.Info("Requiring OAuth bearer tokens", "issuer", cfg.OAuthIssuer)
+	}
+	if len(cfg.APIKeys) > 0 {
+		logger.Info("Accepting API keys", "count", len(cfg.APIKeys))
+	}
+	return authenticator, nil
+}
+
 // isLocalhostAddr checks if the given address is localhost-only
 func isLocalhostAddr(addr string) bool {
 	// Handle cases like ":8080" which bind to all interfaces
@@ -448,9 +625,7 @@ func processAuthentication(cfg *config.Config) error {
 		}
 
 		cfg.Cookies = cookies
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Loaded %d cookies from file: %s\n", len(cookies), cfg.CookieFile)
-		}
+		logger.Info("Loaded cookies from file", "count", len(cookies), "file", cfg.CookieFile)
 	} else if cfg.CookieString != "" {
 		// Process cookie string authentication
 		cookies := parseCookieString(cfg.CookieString)
@@ -459,9 +634,7 @@ func processAuthentication(cfg *config.Config) error {
 		}
 
 		cfg.Cookies = cookies
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Parsed %d cookies from string\n", len(cookies))
-		}
+		logger.Info("Parsed cookies from string", "count", len(cookies))
 	} else {
 		// Handle basic authentication from environment if not provided via flags
 		if cfg.Username == "" {
@@ -488,29 +661,23 @@ func processAuthentication(cfg *config.Config) error {
 					cookies, err := loadCookiesFromFile(envCookieFile)
 					if err == nil {
 						cfg.Cookies = cookies
-						if cfg.Verbose {
-							fmt.Fprintf(os.Stderr, "[VERBOSE] Loaded %d cookies from environment ODATA_COOKIE_FILE\n", len(cookies))
-						}
+						logger.Info("Loaded cookies from environment ODATA_COOKIE_FILE", "count", len(cookies))
 					}
 				}
 			} else if envCookieString != "" {
 				cookies := parseCookieString(envCookieString)
 				if len(cookies) > 0 {
 					cfg.Cookies = cookies
-					if cfg.Verbose {
-						fmt.Fprintf(os.Stderr, "[VERBOSE] Parsed %d cookies from environment ODATA_COOKIE_STRING\n", len(cookies))
-					}
+					logger.Info("Parsed cookies from environment ODATA_COOKIE_STRING", "count", len(cookies))
 				}
 			}
 		}
 
 		// Set up basic auth if credentials are available
 		if cfg.Username != "" && cfg.Password != "" {
-			if cfg.Verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Using basic authentication for user: %s\n", cfg.Username)
-			}
-		} else if cfg.Verbose && len(cfg.Cookies) == 0 {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] No authentication provided or configured. Attempting anonymous access.\n")
+			logger.Info("Using basic authentication", "user", cfg.Username)
+		} else if len(cfg.Cookies) == 0 {
+			logger.Info("No authentication provided or configured. Attempting anonymous access.")
 		}
 	}
 
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zmcp/odata-mcp/internal/audit"
+)
+
+var verifyAuditCmd = &cobra.Command{
+	Use:   "verify-audit FILE...",
+	Short: "Verify the hash chain of an audit log",
+	Long: `Verify the hash chain of an audit log written with --audit-log.
+
+Files are checked in the order given, oldest first. When a single file is given,
+its rotated backups (FILE.<timestamp>) are checked before it.
+
+Exits with a non-zero status if any record was modified, removed, reordered or inserted.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runVerifyAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyAuditCmd)
+}
+
+func runVerifyAudit(cmd *cobra.Command, args []string) error {
+	paths := args
+	if len(args) == 1 {
+		backups, err := audit.Backups(args[0])
+		if err != nil {
+			return err
+		}
+		paths = append(backups, args[0])
+	}
+
+	report, err := audit.Verify(paths)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, note := range report.Notes {
+		fmt.Fprintf(out, "note: %s\n", note)
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintf(out, "FAIL: %s\n", problem)
+	}
+	if !report.OK() {
+		return fmt.Errorf("audit log verification failed: %d problem(s) in %d record(s)", len(report.Problems), report.Records)
+	}
+	fmt.Fprintf(out, "OK: %d record(s) in %d file(s), chain intact\n", report.Records, len(paths))
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package audit writes a hash-chained JSON line for every tool call, to a rotating file
+// or to syslog, and verifies such logs.
+//
+// Each line is the JSON record followed by a "hash" field: the hex SHA-256 of the line
+// without that field. Every record carries the hash of the record before it in
+// "prev_hash", so changing, removing or reordering lines breaks the chain.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/client"
+)
+
+// GenesisHash is the prev_hash of the first record of a chain
+var GenesisHash = strings.Repeat("0", 64)
+
+// Record is one audited tool call
+type Record struct {
+	Seq        int64                  `json:"seq"`
+	Time       string                 `json:"ts"`
+	Client     map[string]interface{} `json:"client,omitempty"` // clientInfo from initialize
+	User       string                 `json:"user,omitempty"`   // Authenticated caller of an HTTP transport
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"` // Secrets and redacted fields masked
+	Requests   []client.LoggedRequest `json:"requests,omitempty"`  // OData requests sent, in order
+	Affected   []string               `json:"affected,omitempty"`  // Entities written, e.g. Products(7)
+	Status     string                 `json:"status"`              // ok or error
+	Error      string                 `json:"error,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	PrevHash   string                 `json:"prev_hash"`
+}
+
+// Record statuses
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// sink stores finished lines
+type sink interface {
+	write(line []byte) error
+	close() error
+}
+
+// Logger chains and writes audit records; it is safe for concurrent use
+type Logger struct {
+	mu       sync.Mutex
+	sink     sink
+	seq      int64
+	lastHash string
+}
+
+// Open creates a logger for a target: a file path, "syslog" for the local syslog daemon,
+// or syslog+udp://host:port / syslog+tcp://host:port for a remote one. Files rotate at
+// maxSizeMB (0 = never) and keep maxBackups rotated files (0 = all); a file logger
+// continues the chain of an existing file.
+func Open(target string, maxSizeMB, maxBackups int) (*Logger, error) {
+	l := &Logger{lastHash: GenesisHash}
+
+	if target == "syslog" || strings.HasPrefix(target, "syslog+") {
+		s, err := openSyslog(target)
+		if err != nil {
+			return nil, err
+		}
+		l.sink = s
+		return l, nil
+	}
+
+	f, last, err := openFile(target, int64(maxSizeMB)*1024*1024, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	l.sink = f
+	if last != nil {
+		l.seq = last.Seq
+		l.lastHash = last.Hash
+	}
+	return l, nil
+}
+
+// Write completes a record with its sequence number, time and chain hash, and stores it
+func (l *Logger) Write(rec *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rec.Seq = l.seq + 1
+	if rec.Time == "" {
+		rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	rec.PrevHash = l.lastHash
+
+	line, hash, err := encode(rec)
+	if err != nil {
+		return err
+	}
+	if err := l.sink.write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.seq = rec.Seq
+	l.lastHash = hash
+	return nil
+}
+
+// Close flushes and closes the log
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.close()
+}
+
+// encode renders a record as a chained line (without the trailing newline)
+func encode(rec *Record) ([]byte, string, error) {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	hash := hashOf(body)
+	line := make([]byte, 0, len(body)+len(hashField)+len(hash)+2)
+	line = append(line, body[:len(body)-1]...)
+	line = append(line, hashField...)
+	line = append(line, hash...)
+	line = append(line, '"', '}')
+	return line, hash, nil
+}
+
+// hashField separates the record body from its hash
+const hashField = `,"hash":"`
+
+func hashOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
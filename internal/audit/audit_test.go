@@ -0,0 +1,104 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRecords(t *testing.T, path string, maxSizeMB, maxBackups, n int) {
+	t.Helper()
+	logger, err := Open(path, maxSizeMB, maxBackups)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := logger.Write(&Record{Tool: "filter_Products", Status: StatusOK}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func verify(t *testing.T, paths ...string) *Report {
+	t.Helper()
+	report, err := Verify(paths)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	return report
+}
+
+func TestChainContinuesAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeRecords(t, path, 0, 0, 3)
+	writeRecords(t, path, 0, 0, 2)
+
+	report := verify(t, path)
+	if !report.OK() || report.Records != 5 || len(report.Notes) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"seq":5,`) || !strings.Contains(string(data), `"prev_hash":"`+GenesisHash+`"`) {
+		t.Errorf("unexpected log:\n%s", data)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeRecords(t, path, 0, 0, 4)
+	data, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(bytes.TrimRight(data, "\n"), []byte("\n"))
+
+	tests := []struct {
+		name  string
+		lines [][]byte
+		want  string
+	}{
+		{"modified", [][]byte{lines[0], bytes.Replace(lines[1], []byte(`"ok"`), []byte(`"error"`), 1), lines[2], lines[3]}, "hash mismatch"},
+		{"removed", [][]byte{lines[0], lines[2], lines[3]}, "prev_hash does not match"},
+		{"reordered", [][]byte{lines[0], lines[2], lines[1], lines[3]}, "prev_hash does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "audit.log")
+			os.WriteFile(tampered, bytes.Join(tt.lines, nil), 0o600)
+			report := verify(t, tampered)
+			if report.OK() || !strings.Contains(report.Problems[0].String(), tt.want) {
+				t.Errorf("Problems = %v, want %q", report.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := Open(path, 1, 1)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// Records of ~300 KB rotate the 1 MB file several times
+	big := strings.Repeat("x", 300*1024)
+	for i := 0; i < 8; i++ {
+		if err := logger.Write(&Record{Tool: "create_Products", Error: big, Status: StatusError}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	logger.Close()
+
+	backups, err := Backups(path)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Backups() = %v, %v; want exactly one kept", backups, err)
+	}
+	report := verify(t, append(backups, path)...)
+	if !report.OK() || len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "continues from earlier files") {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
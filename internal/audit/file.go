@@ -0,0 +1,171 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// backupTimeFormat names rotated files; it sorts in time order
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// tailChunk is how much of a file is read at a time when looking for its last record
+const tailChunk = 64 * 1024
+
+// chainTail is the link an existing log file leaves for the next record
+type chainTail struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// fileSink appends lines to a file and rotates it by size
+type fileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// openFile opens a log file for appending and returns the last record already in it, if any
+func openFile(path string, maxBytes int64, maxBackups int) (*fileSink, *chainTail, error) {
+	s := &fileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, nil, err
+	}
+	last, err := readTail(s.file, s.size)
+	if err != nil {
+		s.file.Close()
+		return nil, nil, fmt.Errorf("failed to read last audit record of %s: %w", path, err)
+	}
+	return s, last, nil
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) write(line []byte) error {
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line))+1 > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(append(line, '\n'))
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// rotate moves the current file aside with a timestamp suffix and prunes old backups
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	backup := s.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(s.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// prune removes the oldest backups beyond maxBackups
+func (s *fileSink) prune() error {
+	if s.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := Backups(s.path)
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove old audit log: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
+
+// Backups lists the rotated files of a log, oldest first
+func Backups(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log backups: %w", err)
+	}
+	var backups []string
+	for _, match := range matches {
+		suffix := match[len(path)+1:]
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// readTail finds the last non-empty line of a file by reading backwards from the end
+func readTail(file io.ReaderAt, size int64) (*chainTail, error) {
+	var tail []byte
+	for offset := size; offset > 0; {
+		n := int64(tailChunk)
+		if n > offset {
+			n = offset
+		}
+		offset -= n
+		chunk := make([]byte, n)
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+
+		trimmed := bytes.TrimRight(tail, "\r\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			tail = trimmed[i+1:]
+			break
+		}
+		if offset == 0 {
+			tail = trimmed
+		}
+	}
+	if len(bytes.TrimSpace(tail)) == 0 {
+		return nil, nil
+	}
+
+	var last chainTail
+	if err := json.Unmarshal(tail, &last); err != nil {
+		return nil, fmt.Errorf("last line is not an audit record: %w", err)
+	}
+	if last.Hash == "" {
+		return nil, fmt.Errorf("last line has no hash")
+	}
+	return &last, nil
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+//go:build windows || plan9
+
+package audit
+
+import "fmt"
+
+func openSyslog(target string) (sink, error) {
+	return nil, fmt.Errorf("syslog audit target '%s' is not supported on this platform", target)
+}
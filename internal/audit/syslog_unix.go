@@ -0,0 +1,53 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"strings"
+)
+
+// syslogTag identifies audit records in syslog
+const syslogTag = "odata-mcp-audit"
+
+// syslogSink sends each line as one syslog message. The chain starts anew with every run,
+// since the last record cannot be read back.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// openSyslog connects to the local daemon ("syslog") or a remote one (syslog+udp://host:port,
+// syslog+tcp://host:port)
+func openSyslog(target string) (sink, error) {
+	priority := syslog.LOG_INFO | syslog.LOG_AUTH
+	var network, addr string
+	if target != "syslog" {
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog target '%s' (use syslog, syslog+udp://host:port or syslog+tcp://host:port)", target)
+		}
+		network = strings.TrimPrefix(u.Scheme, "syslog+")
+		if network != "udp" && network != "tcp" {
+			return nil, fmt.Errorf("unsupported syslog network '%s' (valid: udp, tcp)", network)
+		}
+		addr = u.Host
+	}
+	writer, err := syslog.Dial(network, addr, priority, syslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) write(line []byte) error {
+	return s.writer.Info(string(line))
+}
+
+func (s *syslogSink) close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// maxLineSize bounds a single audit line when verifying
+const maxLineSize = 64 * 1024 * 1024
+
+// Problem is a broken link or altered record
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// Report is the outcome of verifying one chain spread over one or more files
+type Report struct {
+	Records  int
+	Notes    []string  // Expected events: chain restarts, a chain continuing from pruned files
+	Problems []Problem // Tampering or corruption
+}
+
+// OK reports whether the chain is intact
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify checks the files of one log, oldest first (rotated backups before the live file):
+// every line's hash, sequence numbers, and that each record links to the one before it
+func Verify(paths []string) (*Report, error) {
+	report := &Report{}
+	var lastSeq int64
+	var lastHash string
+	first := true
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, tailChunk), maxLineSize)
+
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := bytes.TrimRight(scanner.Bytes(), "\r")
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			fail := func(format string, args ...interface{}) {
+				report.Problems = append(report.Problems, Problem{File: path, Line: lineNo, Message: fmt.Sprintf(format, args...)})
+			}
+
+			body, hash, ok := splitLine(line)
+			if !ok {
+				fail("not an audit record")
+				continue
+			}
+			if hashOf(body) != hash {
+				fail("hash mismatch: record was modified")
+			}
+			var rec struct {
+				Seq      int64  `json:"seq"`
+				PrevHash string `json:"prev_hash"`
+			}
+			if err := json.Unmarshal(body, &rec); err != nil {
+				fail("invalid record: %v", err)
+				continue
+			}
+			report.Records++
+
+			switch {
+			case first && rec.PrevHash != GenesisHash:
+				report.Notes = append(report.Notes, fmt.Sprintf("%s:%d: chain continues from earlier files (seq %d)", path, lineNo, rec.Seq))
+			case !first && rec.PrevHash == GenesisHash && rec.Seq == 1:
+				report.Notes = append(report.Notes, fmt.Sprintf("%s:%d: new chain started", path, lineNo))
+			case !first && rec.PrevHash != lastHash:
+				fail("prev_hash does not match the previous record: records were removed, reordered or inserted")
+			case !first && rec.Seq != lastSeq+1:
+				fail("seq %d follows seq %d", rec.Seq, lastSeq)
+			}
+			first = false
+			lastSeq = rec.Seq
+			lastHash = hash
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+		}
+	}
+	return report, nil
+}
+
+// splitLine separates a line into the hashed body and the hash it carries
+func splitLine(line []byte) ([]byte, string, bool) {
+	i := bytes.LastIndex(line, []byte(hashField))
+	if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, "", false
+	}
+	hash := string(line[i+len(hashField) : len(line)-2])
+	if len(hash) != len(GenesisHash) {
+		return nil, "", false
+	}
+	body := make([]byte, 0, i+1)
+	body = append(body, line[:i]...)
+	body = append(body, '}')
+	return body, hash, true
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package auth authenticates callers of the HTTP transports. It makes the MCP endpoint an
+// OAuth 2.1 protected resource (bearer JWTs checked against the authorization server's
+// JWKS, RFC 9728 metadata, RFC 6750 challenges) and supports static API keys.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication methods
+const (
+	MethodOAuth  = "oauth"
+	MethodAPIKey = "api-key"
+)
+
+// Bearer token error codes (RFC 6750 section 3.1)
+const (
+	ErrInvalidRequest    = "invalid_request"
+	ErrInvalidToken      = "invalid_token"
+	ErrInsufficientScope = "insufficient_scope"
+)
+
+// MetadataPath is where the protected resource metadata (RFC 9728) is served
+const MetadataPath = "/.well-known/oauth-protected-resource"
+
+// Config configures the accepted credentials. OAuth is enabled by Issuer; API keys by APIKeys.
+type Config struct {
+	Issuer   string   // Trusted authorization server (iss claim)
+	JWKSURL  string   // Signing keys; discovered from the issuer when empty
+	Audience string   // Required aud claim; defaults to Resource
+	Resource string   // Canonical URL of the MCP endpoint; derived from the request when empty
+	Scopes   []string // Scopes every token must carry
+	APIKeys  []string // Static keys, "key" or "name=key"
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Subject string                 // sub claim, or the API key's name
+	Method  string                 // MethodOAuth or MethodAPIKey
+	Scopes  []string               // Scopes granted to the token
+	Claims  map[string]interface{} // All token claims (OAuth only)
+	Token   string                 // The bearer token as presented (OAuth only)
+}
+
+// Error is an authentication failure, reported to the client as a WWW-Authenticate challenge
+type Error struct {
+	Status      int    // 401, or 403 for insufficient scope
+	Code        string // RFC 6750 error code; empty when no credentials were sent
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Description
+	}
+	return e.Code + ": " + e.Description
+}
+
+type apiKey struct {
+	name string
+	key  []byte
+}
+
+// Authenticator checks the credentials of HTTP requests
+type Authenticator struct {
+	cfg     Config
+	apiKeys []apiKey
+
+	keysMu sync.Mutex
+	keys   *keySet // Created on first use, after JWKS discovery
+	now    func() time.Time
+}
+
+// New validates the configuration. It does not contact the authorization server.
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{cfg: cfg, now: time.Now}
+
+	if cfg.JWKSURL != "" && cfg.Issuer == "" {
+		return nil, fmt.Errorf("an OAuth issuer is required with a JWKS URL")
+	}
+	if cfg.Issuer != "" && cfg.Audience == "" && cfg.Resource == "" {
+		return nil, fmt.Errorf("OAuth needs an audience or resource URL, so tokens issued for other services are rejected")
+	}
+	if a.cfg.Audience == "" {
+		a.cfg.Audience = cfg.Resource
+	}
+
+	for i, entry := range cfg.APIKeys {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name := fmt.Sprintf("api-key-%d", i+1)
+		key := entry
+		if n, k, ok := strings.Cut(entry, "="); ok && n != "" {
+			name, key = n, k
+		}
+		if len(key) < 16 {
+			return nil, fmt.Errorf("API key '%s' is too short (minimum 16 characters)", name)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{name: name, key: []byte(key)})
+	}
+
+	if cfg.Issuer == "" && len(a.apiKeys) == 0 {
+		return nil, fmt.Errorf("no OAuth issuer or API keys configured")
+	}
+	return a, nil
+}
+
+// OAuthEnabled reports whether bearer JWTs are accepted
+func (a *Authenticator) OAuthEnabled() bool {
+	return a.cfg.Issuer != ""
+}
+
+// Authenticate checks a request's Authorization (or X-API-Key) header
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" && len(a.apiKeys) > 0 {
+		if p := a.matchAPIKey(key); p != nil {
+			return p, nil
+		}
+		return nil, &Error{Status: http.StatusUnauthorized, Code: ErrInvalidToken, Description: "invalid API key"}
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, &Error{Status: http.StatusUnauthorized, Description: "authentication required"}
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, &Error{Status: http.StatusBadRequest, Code: ErrInvalidRequest, Description: "expected a Bearer token in the Authorization header"}
+	}
+
+	if p := a.matchAPIKey(token); p != nil {
+		return p, nil
+	}
+	if !a.OAuthEnabled() {
+		return nil, &Error{Status: http.StatusUnauthorized, Code: ErrInvalidToken, Description: "invalid API key"}
+	}
+	return a.verifyToken(r.Context(), token)
+}
+
+// matchAPIKey compares against every key in constant time
+func (a *Authenticator) matchAPIKey(presented string) *Principal {
+	var match *Principal
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(presented), k.key) == 1 {
+			match = &Principal{Subject: k.name, Method: MethodAPIKey}
+		}
+	}
+	return match
+}
+
+func (a *Authenticator) verifyToken(ctx context.Context, token string) (*Principal, error) {
+	keys, err := a.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifyJWT(ctx, token, keys, a.cfg.Issuer, a.cfg.Audience, a.now())
+	if err != nil {
+		return nil, &Error{Status: http.StatusUnauthorized, Code: ErrInvalidToken, Description: err.Error()}
+	}
+
+	scopes := tokenScopes(claims)
+	granted := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		granted[s] = true
+	}
+	for _, required := range a.cfg.Scopes {
+		if !granted[required] {
+			return nil, &Error{Status: http.StatusForbidden, Code: ErrInsufficientScope, Description: fmt.Sprintf("token lacks scope '%s'", required)}
+		}
+	}
+
+	subject, _ := claims["sub"].(string)
+	return &Principal{Subject: subject, Method: MethodOAuth, Scopes: scopes, Claims: claims, Token: token}, nil
+}
+
+// keySet returns the JWKS cache, discovering the JWKS URL from the issuer on first use
+func (a *Authenticator) keySet(ctx context.Context) (*keySet, error) {
+	a.keysMu.Lock()
+	defer a.keysMu.Unlock()
+	if a.keys != nil {
+		return a.keys, nil
+	}
+	url := a.cfg.JWKSURL
+	if url == "" {
+		discovered, err := discoverJWKS(ctx, a.cfg.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		url = discovered
+	}
+	a.keys = newKeySet(url)
+	return a.keys, nil
+}
+
+// Middleware rejects unauthenticated requests with a challenge and passes the principal
+// of authenticated ones on in the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// CORS preflight requests carry no credentials
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			a.challenge(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// challenge answers a failed authentication with WWW-Authenticate (RFC 6750, RFC 9728)
+func (a *Authenticator) challenge(w http.ResponseWriter, r *http.Request, err error) {
+	var authErr *Error
+	if !errors.As(err, &authErr) {
+		// The authorization server could not be reached; this is not the client's fault
+		http.Error(w, fmt.Sprintf("authentication unavailable: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	params := []string{`realm="odata-mcp"`}
+	if a.OAuthEnabled() {
+		params = append(params, fmt.Sprintf(`resource_metadata="%s"`, a.origin(r)+MetadataPath))
+	}
+	if authErr.Code != "" {
+		params = append(params, fmt.Sprintf(`error="%s"`, authErr.Code), fmt.Sprintf(`error_description="%s"`, quoteParam(authErr.Description)))
+	}
+	if authErr.Code == ErrInsufficientScope || (authErr.Code == "" && len(a.cfg.Scopes) > 0) {
+		params = append(params, fmt.Sprintf(`scope="%s"`, strings.Join(a.cfg.Scopes, " ")))
+	}
+
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(authErr.Status)
+	code := authErr.Code
+	if code == "" {
+		code = "unauthorized"
+	}
+	fmt.Fprintf(w, `{"error":"%s","error_description":"%s"}`, code, quoteParam(authErr.Description))
+}
+
+// ServeMetadata serves the protected resource metadata (RFC 9728)
+func (a *Authenticator) ServeMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	metadata := map[string]interface{}{
+		"resource":                 a.resource(r),
+		"authorization_servers":    []string{a.cfg.Issuer},
+		"bearer_methods_supported": []string{"header"},
+		"resource_name":            "OData MCP Bridge",
+	}
+	if len(a.cfg.Scopes) > 0 {
+		metadata["scopes_supported"] = a.cfg.Scopes
+	}
+	writeJSON(w, metadata)
+}
+
+// resource is the canonical URL of the MCP endpoint
+func (a *Authenticator) resource(r *http.Request) string {
+	if a.cfg.Resource != "" {
+		return a.cfg.Resource
+	}
+	return a.origin(r) + "/mcp"
+}
+
+// origin is scheme://host of the resource, as configured or as the client reached it
+func (a *Authenticator) origin(r *http.Request) string {
+	if a.cfg.Resource != "" {
+		if i := strings.Index(a.cfg.Resource, "://"); i >= 0 {
+			if j := strings.Index(a.cfg.Resource[i+3:], "/"); j >= 0 {
+				return a.cfg.Resource[:i+3+j]
+			}
+		}
+		return strings.TrimRight(a.cfg.Resource, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func quoteParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `'`).Replace(s)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller, or nil for unauthenticated transports
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// testIssuer is an authorization server publishing an RSA and an EC signing key
+type testIssuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"issuer": issuer.server.URL, "jwks_uri": issuer.server.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// token signs claims, filling in iss, aud and exp unless given
+func (i *testIssuer) token(t *testing.T, alg string, claims map[string]interface{}) string {
+	t.Helper()
+	full := map[string]interface{}{"iss": i.server.URL, "aud": testResource, "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	for k, v := range claims {
+		if v == nil {
+			delete(full, k)
+		} else {
+			full[k] = v
+		}
+	}
+	kid := "rsa-1"
+	if strings.HasPrefix(alg, "ES") {
+		kid = "ec-1"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(full)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		r, s, signErr := ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		err = signErr
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		signature = []byte("unsigned")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(signature)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"nothing configured", Config{}, "no OAuth issuer or API keys"},
+		{"issuer without audience", Config{Issuer: "https://idp"}, "audience or resource"},
+		{"JWKS without issuer", Config{JWKSURL: "https://idp/jwks", APIKeys: []string{"0123456789abcdef"}}, "issuer is required"},
+		{"short API key", Config{APIKeys: []string{"ci=short"}}, "'ci' is too short"},
+		{"API keys only", Config{APIKeys: []string{"ci=0123456789abcdef"}}, ""},
+		{"issuer with resource", Config{Issuer: "https://idp", Resource: testResource}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	issuer := newTestIssuer(t)
+	a, err := New(Config{
+		Issuer:   issuer.server.URL,
+		Resource: testResource,
+		Scopes:   []string{"odata.read"},
+		APIKeys:  []string{"ci=0123456789abcdef"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := PrincipalFromContext(r.Context())
+		w.Write([]byte(p.Method + ":" + p.Subject))
+	}))
+
+	valid := map[string]interface{}{"scope": "odata.read odata.write"}
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+		wantError  string // error parameter of the challenge
+	}{
+		{"RS256 token", "Authorization", "Bearer " + issuer.token(t, "RS256", valid), 200, "oauth:alice", ""},
+		{"ES256 token", "Authorization", "Bearer " + issuer.token(t, "ES256", valid), 200, "oauth:alice", ""},
+		{"scp array", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scp": []string{"odata.read"}}), 200, "oauth:alice", ""},
+		{"API key as bearer", "Authorization", "Bearer 0123456789abcdef", 200, "api-key:ci", ""},
+		{"API key header", "X-API-Key", "0123456789abcdef", 200, "api-key:ci", ""},
+		{"no credentials", "", "", 401, "", ""},
+		{"wrong API key", "X-API-Key", "fedcba9876543210", 401, "", "invalid_token"},
+		{"basic scheme", "Authorization", "Basic dXNlcjpwYXNz", 400, "", "invalid_request"},
+		{"expired", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scope": "odata.read", "exp": time.Now().Add(-time.Hour).Unix()}), 401, "", "invalid_token"},
+		{"no expiry", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scope": "odata.read", "exp": nil}), 401, "", "invalid_token"},
+		{"other audience", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scope": "odata.read", "aud": "https://other.example.com"}), 401, "", "invalid_token"},
+		{"other issuer", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scope": "odata.read", "iss": "https://evil.example.com"}), 401, "", "invalid_token"},
+		{"alg none", "Authorization", "Bearer " + issuer.token(t, "none", valid), 401, "", "invalid_token"},
+		{"alg HS256", "Authorization", "Bearer " + issuer.token(t, "HS256", valid), 401, "", "invalid_token"},
+		{"tampered", "Authorization", "Bearer " + issuer.token(t, "RS256", valid) + "x", 401, "", "invalid_token"},
+		{"missing scope", "Authorization", "Bearer " + issuer.token(t, "RS256", map[string]interface{}{"scope": "odata.write"}), 403, "", "insufficient_scope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://mcp.example.com/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == 200 {
+				if rec.Body.String() != tt.wantBody {
+					t.Errorf("principal = %s, want %s", rec.Body.String(), tt.wantBody)
+				}
+				return
+			}
+
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if !strings.HasPrefix(challenge, "Bearer ") || !strings.Contains(challenge, `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`) {
+				t.Errorf("WWW-Authenticate = %s", challenge)
+			}
+			if tt.wantError == "" && strings.Contains(challenge, "error=") {
+				t.Errorf("challenge without credentials has an error code: %s", challenge)
+			}
+			if tt.wantError != "" && !strings.Contains(challenge, `error="`+tt.wantError+`"`) {
+				t.Errorf("WWW-Authenticate = %s, want error %s", challenge, tt.wantError)
+			}
+			if tt.wantError == "insufficient_scope" && !strings.Contains(challenge, `scope="odata.read"`) {
+				t.Errorf("WWW-Authenticate = %s, want the required scope", challenge)
+			}
+		})
+	}
+}
+
+func TestMiddlewarePreflight(t *testing.T) {
+	a, _ := New(Config{APIKeys: []string{"0123456789abcdef"}})
+	called := false
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodOptions, "/mcp", nil))
+	if !called {
+		t.Error("CORS preflight was rejected")
+	}
+}
+
+func TestServeMetadata(t *testing.T) {
+	a, _ := New(Config{Issuer: "https://idp.example.com", Audience: "api://odata", Scopes: []string{"odata.read"}})
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080"+MetadataPath, nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	a.ServeMetadata(rec, req)
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &metadata); err != nil {
+		t.Fatalf("metadata is not JSON: %v", err)
+	}
+	if metadata["resource"] != "https://localhost:8080/mcp" {
+		t.Errorf("resource = %v", metadata["resource"])
+	}
+	if servers, _ := metadata["authorization_servers"].([]interface{}); len(servers) != 1 || servers[0] != "https://idp.example.com" {
+		t.Errorf("authorization_servers = %v", metadata["authorization_servers"])
+	}
+	if scopes, _ := metadata["scopes_supported"].([]interface{}); len(scopes) != 1 || scopes[0] != "odata.read" {
+		t.Errorf("scopes_supported = %v", metadata["scopes_supported"])
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zmcp/odata-mcp/internal/client"
+)
+
+// Passthrough modes: how an authenticated caller's identity becomes OData credentials
+const (
+	PassthroughForward     = "forward"     // Send the caller's bearer token to the service
+	PassthroughExchange    = "exchange"    // Exchange the caller's token for one issued for the service (RFC 8693)
+	PassthroughCredentials = "credentials" // Look up the caller's basic credentials in a file
+)
+
+// Token exchange grant and token types (RFC 8693)
+const (
+	grantTokenExchange   = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// exchangeMargin renews exchanged tokens this long before they expire
+const exchangeMargin = 30 * time.Second
+
+// PassthroughConfig configures principal propagation
+type PassthroughConfig struct {
+	Mode            string // PassthroughForward, PassthroughExchange or PassthroughCredentials
+	TokenURL        string // Token endpoint for the exchange
+	ClientID        string // Client authenticating the exchange
+	ClientSecret    string
+	Audience        string // Audience of the exchanged token (the OData service)
+	Scope           string // Scope of the exchanged token
+	CredentialsFile string // YAML or JSON file mapping callers to basic credentials
+}
+
+// userCredential is one entry of the credentials file
+type userCredential struct {
+	Username    string `yaml:"username" json:"username"`
+	Password    string `yaml:"password" json:"password"`
+	PasswordEnv string `yaml:"password_env" json:"password_env"` // Read the password from this environment variable
+}
+
+// exchangedToken is a cached token exchange result
+type exchangedToken struct {
+	token   string
+	expires time.Time
+}
+
+// Passthrough maps authenticated callers to the credentials their OData requests use
+type Passthrough struct {
+	cfg    PassthroughConfig
+	users  map[string]userCredential // By principal subject (credentials mode)
+	client *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]exchangedToken // By hash of the subject token
+	now    func() time.Time
+}
+
+// NewPassthrough validates the configuration and loads the credentials file
+func NewPassthrough(cfg PassthroughConfig) (*Passthrough, error) {
+	p := &Passthrough{
+		cfg:    cfg,
+		client: &http.Client{Timeout: fetchTimeout},
+		tokens: make(map[string]exchangedToken),
+		now:    time.Now,
+	}
+	switch cfg.Mode {
+	case PassthroughForward:
+	case PassthroughExchange:
+		if cfg.TokenURL == "" {
+			return nil, fmt.Errorf("token exchange requires a token endpoint URL")
+		}
+	case PassthroughCredentials:
+		if cfg.CredentialsFile == "" {
+			return nil, fmt.Errorf("credential passthrough requires a user credentials file")
+		}
+		users, err := loadUserCredentials(cfg.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		p.users = users
+	default:
+		return nil, fmt.Errorf("invalid passthrough mode %q (valid: forward, exchange, credentials)", cfg.Mode)
+	}
+	return p, nil
+}
+
+// Mode returns the configured passthrough mode
+func (p *Passthrough) Mode() string {
+	return p.cfg.Mode
+}
+
+// Credentials returns the OData credentials of a caller
+func (p *Passthrough) Credentials(ctx context.Context, principal *Principal) (*client.Credentials, error) {
+	if principal == nil {
+		return nil, fmt.Errorf("no authenticated caller to pass on to the OData service")
+	}
+	id := principal.Method + ":" + principal.Subject
+
+	switch p.cfg.Mode {
+	case PassthroughCredentials:
+		user, ok := p.users[principal.Subject]
+		if !ok {
+			return nil, fmt.Errorf("no OData credentials are mapped for user '%s'", principal.Subject)
+		}
+		return &client.Credentials{ID: id, Username: user.Username, Password: user.Password}, nil
+	case PassthroughExchange:
+		if principal.Token == "" {
+			return nil, fmt.Errorf("user '%s' has no OAuth token to exchange (API key callers cannot be passed on)", principal.Subject)
+		}
+		token, err := p.exchange(ctx, principal.Token)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Credentials{ID: id, Bearer: token}, nil
+	default:
+		if principal.Token == "" {
+			return nil, fmt.Errorf("user '%s' has no OAuth token to forward (API key callers cannot be passed on)", principal.Subject)
+		}
+		return &client.Credentials{ID: id, Bearer: principal.Token}, nil
+	}
+}
+
+// exchange trades the caller's token for one issued for the OData service, caching the
+// result until shortly before it expires
+func (p *Passthrough) exchange(ctx context.Context, subjectToken string) (string, error) {
+	sum := sha256.Sum256([]byte(subjectToken))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	p.mu.Lock()
+	cached, ok := p.tokens[cacheKey]
+	p.mu.Unlock()
+	if ok && p.now().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":           {grantTokenExchange},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {tokenTypeAccessToken},
+		"requested_token_type": {tokenTypeAccessToken},
+	}
+	if p.cfg.Audience != "" {
+		form.Set("audience", p.cfg.Audience)
+	}
+	if p.cfg.Scope != "" {
+		form.Set("scope", p.cfg.Scope)
+	}
+	if p.cfg.ClientID != "" && p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("failed to parse token exchange response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		if result.Error != "" {
+			return "", fmt.Errorf("token exchange rejected: %s %s", result.Error, result.ErrorDescription)
+		}
+		return "", fmt.Errorf("token exchange failed with HTTP %d", resp.StatusCode)
+	}
+
+	// Without expires_in the token is used for this call only
+	if result.ExpiresIn > 0 {
+		now := p.now()
+		p.mu.Lock()
+		for key, t := range p.tokens {
+			if now.After(t.expires) {
+				delete(p.tokens, key)
+			}
+		}
+		p.tokens[cacheKey] = exchangedToken{
+			token:   result.AccessToken,
+			expires: now.Add(time.Duration(result.ExpiresIn)*time.Second - exchangeMargin),
+		}
+		p.mu.Unlock()
+	}
+	return result.AccessToken, nil
+}
+
+// loadUserCredentials reads a YAML or JSON map of principal subject to basic credentials
+func loadUserCredentials(path string) (map[string]userCredential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user credentials file: %w", err)
+	}
+
+	var file struct {
+		Users map[string]userCredential `yaml:"users" json:"users"`
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user credentials file %s: %w", path, err)
+	}
+
+	for subject, user := range file.Users {
+		if user.PasswordEnv != "" {
+			user.Password = os.Getenv(user.PasswordEnv)
+		}
+		if user.Username == "" || user.Password == "" {
+			return nil, fmt.Errorf("invalid user credentials file %s: user '%s' needs a username and a password", path, subject)
+		}
+		file.Users[subject] = user
+	}
+	return file.Users, nil
+}
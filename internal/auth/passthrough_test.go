@@ -0,0 +1,112 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPassthroughForward(t *testing.T) {
+	p, err := NewPassthrough(PassthroughConfig{Mode: PassthroughForward})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := p.Credentials(context.Background(), &Principal{Subject: "alice", Method: MethodOAuth, Token: "alice-token"})
+	if err != nil || creds.Bearer != "alice-token" || creds.ID != "oauth:alice" {
+		t.Fatalf("Credentials() = %+v, %v", creds, err)
+	}
+	if _, err := p.Credentials(context.Background(), &Principal{Subject: "ci", Method: MethodAPIKey}); err == nil {
+		t.Error("API key caller was forwarded without a token")
+	}
+	if _, err := p.Credentials(context.Background(), nil); err == nil {
+		t.Error("unauthenticated call was not refused")
+	}
+}
+
+func TestPassthroughExchange(t *testing.T) {
+	exchanges := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		r.ParseForm()
+		id, secret, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != grantTokenExchange || r.Form.Get("subject_token_type") != tokenTypeAccessToken ||
+			r.Form.Get("audience") != "sap-prod" || id != "bridge" || secret != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_request","error_description":"unexpected exchange"}`))
+			return
+		}
+		if r.Form.Get("subject_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","error_description":"token revoked"}`))
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"access_token":      "sap-" + r.Form.Get("subject_token"),
+			"issued_token_type": tokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        300,
+		})
+	}))
+	defer server.Close()
+
+	p, err := NewPassthrough(PassthroughConfig{Mode: PassthroughExchange, TokenURL: server.URL, ClientID: "bridge", ClientSecret: "s3cret", Audience: "sap-prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &Principal{Subject: "alice", Method: MethodOAuth, Token: "alice-token"}
+	for i := 0; i < 2; i++ {
+		creds, err := p.Credentials(context.Background(), alice)
+		if err != nil || creds.Bearer != "sap-alice-token" {
+			t.Fatalf("Credentials() = %+v, %v", creds, err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("exchanges = %d, want 1 (cached)", exchanges)
+	}
+
+	_, err = p.Credentials(context.Background(), &Principal{Subject: "bob", Method: MethodOAuth, Token: "revoked"})
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("revoked token error = %v", err)
+	}
+}
+
+func TestPassthroughCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	os.WriteFile(path, []byte(`users:
+  alice@example.com:
+    username: ALICE
+    password_env: TEST_ALICE_PASSWORD
+  ci:
+    username: CI_USER
+    password: ci-password
+`), 0600)
+	t.Setenv("TEST_ALICE_PASSWORD", "alice-password")
+
+	p, err := NewPassthrough(PassthroughConfig{Mode: PassthroughCredentials, CredentialsFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := p.Credentials(context.Background(), &Principal{Subject: "alice@example.com", Method: MethodOAuth})
+	if err != nil || creds.Username != "ALICE" || creds.Password != "alice-password" {
+		t.Fatalf("Credentials(alice) = %+v, %v", creds, err)
+	}
+	creds, err = p.Credentials(context.Background(), &Principal{Subject: "ci", Method: MethodAPIKey})
+	if err != nil || creds.Username != "CI_USER" || creds.ID != "api-key:ci" {
+		t.Fatalf("Credentials(ci) = %+v, %v", creds, err)
+	}
+	if _, err := p.Credentials(context.Background(), &Principal{Subject: "mallory", Method: MethodOAuth}); err == nil || !strings.Contains(err.Error(), "no OData credentials") {
+		t.Errorf("unmapped user error = %v", err)
+	}
+
+	os.WriteFile(path, []byte("users:\n  bob:\n    username: BOB\n"), 0600)
+	if _, err := NewPassthrough(PassthroughConfig{Mode: PassthroughCredentials, CredentialsFile: path}); err == nil {
+		t.Error("user without password was accepted")
+	}
+}
@@ -0,0 +1,751 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/utils"
+)
+
+// aliasPattern is an OData simple identifier; aliases are written into $apply verbatim
+var aliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Aggregation strategies, in order of preference
+const (
+	aggregateStrategyApply     = "apply"      // OData v4 $apply, aggregated by the server
+	aggregateStrategySAPSelect = "sap_select" // SAP v2 analytical: $select of dimensions and measures
+	aggregateStrategyClient    = "client"     // Paged read, aggregated in the bridge
+)
+
+// aggregateExpression is a single aggregate such as "NetValue with sum as TotalNet"
+type aggregateExpression struct {
+	Property string
+	With     string
+	As       string
+}
+
+// aggregateSpec is the parsed groupby/aggregate request
+type aggregateSpec struct {
+	GroupBy    []string
+	Aggregates []aggregateExpression
+	Filter     string
+}
+
+// aggregateInputProperties returns the schema properties shared by the eager and lazy aggregate tools
+func (b *ODataMCPBridge) aggregateInputProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"groupby": map[string]interface{}{
+			"type":        "array",
+			"description": "Properties to group by (omit for a grand total)",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"aggregate": map[string]interface{}{
+			"type":        "array",
+			"description": "Aggregates to compute. 'count' needs no property and counts rows per group",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"property": map[string]interface{}{
+						"type":        "string",
+						"description": "Property to aggregate",
+					},
+					"with": map[string]interface{}{
+						"type":        "string",
+						"description": "Aggregation method",
+						"enum":        constants.AggregationMethods,
+					},
+					"as": map[string]interface{}{
+						"type":        "string",
+						"description": "Result column name (default: <property>_<with>)",
+					},
+				},
+				"required": []string{"with"},
+			},
+		},
+		b.getParameterName("$filter"): map[string]interface{}{
+			"type":        "string",
+			"description": "OData filter expression applied before aggregation",
+		},
+	}
+}
+
+// generateAggregateTool creates an aggregate tool for an entity set
+func (b *ODataMCPBridge) generateAggregateTool(entitySetName string, entitySet *models.EntitySet, entityType *models.EntityType) {
+	opName := constants.GetToolOperationName(constants.OpAggregate, b.config.ToolShrink)
+	toolName := b.formatToolName(opName, entitySetName)
+
+	description := fmt.Sprintf("Group and aggregate %s entities (sum, min, max, average, count, countdistinct)", entitySetName) + b.policyToolNote(entitySetName)
+
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": b.aggregateInputProperties(),
+			"required":   []string{"aggregate"},
+		},
+	}
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleEntityAggregate(ctx, entitySetName, entityType, args)
+	}
+
+	b.server.AddTool(tool, handler)
+
+	// Track tool info
+	b.tools[toolName] = &models.ToolInfo{
+		Name:        toolName,
+		Description: description,
+		EntitySet:   entitySetName,
+		Operation:   constants.OpAggregate,
+	}
+}
+
+// generateLazyAggregateEntitiesTool creates the aggregate_entities generic tool
+func (b *ODataMCPBridge) generateLazyAggregateEntitiesTool() error {
+	toolName := b.formatToolName("aggregate_entities", "")
+
+	properties := b.aggregateInputProperties()
+	properties["entity_set"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Name of the entity set to aggregate (e.g., 'Orders', 'SalesOrderItems')",
+	}
+
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: "Group and aggregate entities of any entity set (sum, min, max, average, count, countdistinct)",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"entity_set", "aggregate"},
+		},
+	}
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleLazyAggregateEntities(ctx, args)
+	}
+
+	b.server.AddTool(tool, handler)
+
+	// Track tool info
+	b.tools[toolName] = &models.ToolInfo{
+		Name:        toolName,
+		Description: tool.Description,
+		Operation:   constants.OpAggregate,
+	}
+
+	return nil
+}
+
+// handleLazyAggregateEntities handles lazy mode aggregate operations
+func (b *ODataMCPBridge) handleLazyAggregateEntities(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Extract entity_set parameter
+	entitySet, ok := args["entity_set"].(string)
+	if !ok || entitySet == "" {
+		return nil, fmt.Errorf("missing required parameter: entity_set")
+	}
+
+	// Validate entity set and get entity type
+	_, entityType, err := b.validateEntitySet(entitySet)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove entity_set from args before delegating
+	delete(args, "entity_set")
+
+	// Delegate to existing handler
+	return b.handleEntityAggregate(ctx, entitySet, entityType, args)
+}
+
+// parseAggregateSpec validates the tool arguments against the entity type
+func (b *ODataMCPBridge) parseAggregateSpec(entityType *models.EntityType, args map[string]interface{}) (*aggregateSpec, error) {
+	spec := &aggregateSpec{}
+
+	propTypes := make(map[string]string, len(entityType.Properties))
+	for _, prop := range entityType.Properties {
+		propTypes[prop.Name] = prop.Type
+	}
+
+	if raw, ok := args["groupby"]; ok && raw != nil {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("groupby must be an array of property names")
+		}
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("groupby must be an array of property names")
+			}
+			if _, exists := propTypes[name]; !exists {
+				return nil, fmt.Errorf("unknown groupby property: %s", name)
+			}
+			spec.GroupBy = append(spec.GroupBy, name)
+		}
+	}
+
+	list, ok := args["aggregate"].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("missing required parameter: aggregate")
+	}
+
+	aliases := make(map[string]bool)
+	for _, g := range spec.GroupBy {
+		aliases[g] = true
+	}
+
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("each aggregate must be an object with 'with' and optional 'property' and 'as'")
+		}
+
+		expr := aggregateExpression{}
+		expr.Property, _ = obj["property"].(string)
+		expr.With, _ = obj["with"].(string)
+		expr.As, _ = obj["as"].(string)
+		expr.With = strings.ToLower(expr.With)
+
+		if !isAggregationMethod(expr.With) {
+			return nil, fmt.Errorf("unsupported aggregation method '%s' (supported: %s)", expr.With, strings.Join(constants.AggregationMethods, ", "))
+		}
+
+		if expr.With == "count" {
+			// Row count per group; a property is accepted but ignored
+			expr.Property = ""
+		} else {
+			if expr.Property == "" {
+				return nil, fmt.Errorf("aggregation '%s' requires a property", expr.With)
+			}
+			propType, exists := propTypes[expr.Property]
+			if !exists {
+				return nil, fmt.Errorf("unknown aggregate property: %s", expr.Property)
+			}
+			if (expr.With == "sum" || expr.With == "average") && !isNumericEdmType(propType) {
+				return nil, fmt.Errorf("cannot %s non-numeric property %s (%s)", expr.With, expr.Property, propType)
+			}
+		}
+
+		if expr.As == "" {
+			if expr.Property == "" {
+				expr.As = "count"
+			} else {
+				expr.As = fmt.Sprintf("%s_%s", expr.Property, expr.With)
+			}
+		}
+		if !aliasPattern.MatchString(expr.As) {
+			return nil, fmt.Errorf("invalid result column name '%s': use letters, digits and underscores", expr.As)
+		}
+		if aliases[expr.As] {
+			return nil, fmt.Errorf("duplicate result column name: %s", expr.As)
+		}
+		aliases[expr.As] = true
+
+		spec.Aggregates = append(spec.Aggregates, expr)
+	}
+
+	// Map arguments to handle both Claude-friendly and standard parameter names
+	for key, value := range args {
+		if b.mapParameterToOData(key) == "$filter" {
+			if filter, ok := value.(string); ok {
+				// The filter becomes a filter() transformation of $apply; a stray
+				// parenthesis would let it add transformations of its own
+				if err := checkBalancedExpression(filter); err != nil {
+					return nil, fmt.Errorf("invalid filter: %w", err)
+				}
+				spec.Filter = filter
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// handleEntityAggregate groups and aggregates an entity set using the best strategy the service supports
+func (b *ODataMCPBridge) handleEntityAggregate(ctx context.Context, entitySetName string, entityType *models.EntityType, args map[string]interface{}) (interface{}, error) {
+	spec, err := b.parseAggregateSpec(entityType, args)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Filter != "" {
+		spec.Filter = b.transformFilterForSAP(spec.Filter, entitySetName)
+	}
+	if err := b.applyAggregatePolicy(entitySetName, spec); err != nil {
+		return nil, err
+	}
+
+	strategy := b.selectAggregateStrategy(entityType, spec)
+
+	var groups []map[string]interface{}
+	result := map[string]interface{}{
+		"entity_set": entitySetName,
+	}
+
+	switch strategy {
+	case aggregateStrategyApply:
+		var truncated bool
+		groups, truncated, err = b.aggregateWithApply(ctx, entitySetName, spec)
+		if err != nil {
+			// Plenty of v4 services do not implement $apply; fall back rather than fail
+			logger.InfoContext(ctx, "$apply rejected, aggregating client-side", "entity_set", entitySetName, "error", err)
+			result["apply_error"] = err.Error()
+			strategy = aggregateStrategyClient
+		} else if truncated {
+			truncateGroups(result, len(groups))
+		}
+	case aggregateStrategySAPSelect:
+		var truncated bool
+		groups, truncated, err = b.aggregateWithSAPSelect(ctx, entitySetName, spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate entities: %w", err)
+		}
+		if truncated {
+			truncateGroups(result, len(groups))
+		}
+	}
+
+	if strategy == aggregateStrategyClient {
+		var scanned int
+		var truncated bool
+		groups, scanned, truncated, err = b.aggregateClientSide(ctx, entitySetName, spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate entities: %w", err)
+		}
+		result["rows_scanned"] = scanned
+		if truncated {
+			result["truncated"] = true
+			result["warning"] = fmt.Sprintf("Client-side aggregation stopped after %d rows (--aggregate-max-rows); results cover only the rows read. Narrow the filter or raise the limit.", scanned)
+		}
+	}
+
+	// Aggregates of redacted properties are redacted too; their aliases do not match the rules
+	var redacted []string
+	for _, expr := range spec.Aggregates {
+		if expr.Property == "" || expr.With == "countdistinct" || !b.redactor.IsRedacted(expr.Property) {
+			continue
+		}
+		for _, group := range groups {
+			group[expr.As] = b.redactor.Value(group[expr.As])
+		}
+		redacted = append(redacted, expr.As)
+	}
+	if len(redacted) > 0 {
+		result["@odata.metadata"] = map[string]interface{}{"redacted_fields": redacted}
+	}
+
+	if b.config.LegacyDates {
+		for i, group := range groups {
+			groups[i] = utils.ConvertDatesInMap(group, true)
+		}
+	}
+
+	result["strategy"] = strategy
+	result["group_count"] = len(groups)
+	if b.config.MaxItems > 0 && len(groups) > b.config.MaxItems {
+		result["truncated"] = true
+		result["warning"] = fmt.Sprintf("Showing %d of %d groups due to size limits; group by fewer properties or add a filter", b.config.MaxItems, len(groups))
+		groups = groups[:b.config.MaxItems]
+	}
+	result["groups"] = groups
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(response), nil
+}
+
+// selectAggregateStrategy picks server-side aggregation when the service can do it
+func (b *ODataMCPBridge) selectAggregateStrategy(entityType *models.EntityType, spec *aggregateSpec) string {
+	if b.metadata != nil && strings.HasPrefix(b.metadata.Version, "4") {
+		return aggregateStrategyApply
+	}
+
+	if b.supportsSAPAggregation(entityType, spec) {
+		return aggregateStrategySAPSelect
+	}
+
+	return aggregateStrategyClient
+}
+
+// supportsSAPAggregation reports whether a v2 SAP analytical entity can answer the spec
+// by itself: every group property must be a dimension and every aggregate a summed measure,
+// because SAP aggregates measures with their default (sum) over the selected dimensions.
+func (b *ODataMCPBridge) supportsSAPAggregation(entityType *models.EntityType, spec *aggregateSpec) bool {
+	roles := make(map[string]string, len(entityType.Properties))
+	hasMeasure := false
+	for _, prop := range entityType.Properties {
+		roles[prop.Name] = prop.AggregationRole
+		if prop.AggregationRole == constants.AggregationRoleMeasure {
+			hasMeasure = true
+		}
+	}
+	if !hasMeasure {
+		return false
+	}
+
+	for _, g := range spec.GroupBy {
+		if roles[g] != constants.AggregationRoleDimension {
+			return false
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, agg := range spec.Aggregates {
+		if agg.With != "sum" || roles[agg.Property] != constants.AggregationRoleMeasure {
+			return false
+		}
+		// The same measure can only be selected once
+		if seen[agg.Property] {
+			return false
+		}
+		seen[agg.Property] = true
+	}
+
+	return true
+}
+
+// buildApplyExpression renders the spec as an OData v4 $apply transformation
+func buildApplyExpression(spec *aggregateSpec) string {
+	aggregates := make([]string, 0, len(spec.Aggregates))
+	for _, agg := range spec.Aggregates {
+		if agg.With == "count" {
+			aggregates = append(aggregates, fmt.Sprintf("$count as %s", agg.As))
+		} else {
+			aggregates = append(aggregates, fmt.Sprintf("%s with %s as %s", agg.Property, agg.With, agg.As))
+		}
+	}
+	aggregate := fmt.Sprintf("aggregate(%s)", strings.Join(aggregates, ","))
+
+	transformation := aggregate
+	if len(spec.GroupBy) > 0 {
+		transformation = fmt.Sprintf("groupby((%s),%s)", strings.Join(spec.GroupBy, ","), aggregate)
+	}
+
+	if spec.Filter != "" {
+		transformation = fmt.Sprintf("filter(%s)/%s", spec.Filter, transformation)
+	}
+
+	return transformation
+}
+
+// aggregateWithApply lets the server aggregate via $apply. The groups are paged like
+// rows ($top and $skip apply to the result of $apply), up to --aggregate-max-rows groups;
+// truncated reports that more followed.
+func (b *ODataMCPBridge) aggregateWithApply(ctx context.Context, entitySetName string, spec *aggregateSpec) ([]map[string]interface{}, bool, error) {
+	options := map[string]string{
+		constants.QueryApply: buildApplyExpression(spec),
+	}
+	groups := make([]map[string]interface{}, 0)
+	_, truncated, err := b.forEachPage(ctx, entitySetName, options, b.aggregateMaxRows(), func(rows []interface{}) error {
+		for _, row := range rows {
+			entity, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group := make(map[string]interface{}, len(entity))
+			for k, v := range entity {
+				// Drop control information such as @odata.id
+				if strings.HasPrefix(k, "@") || k == "__metadata" {
+					continue
+				}
+				group[k] = v
+			}
+			groups = append(groups, group)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return groups, truncated, nil
+}
+
+// aggregateMaxRows is the number of rows, or of groups aggregated by the service, the
+// aggregate tool reads at most (--aggregate-max-rows)
+func (b *ODataMCPBridge) aggregateMaxRows() int {
+	if b.config.AggregateMaxRows > 0 {
+		return b.config.AggregateMaxRows
+	}
+	return constants.DefaultAggregateMaxRows
+}
+
+// truncateGroups flags a result whose groups were cut off at --aggregate-max-rows
+func truncateGroups(result map[string]interface{}, groups int) {
+	result["truncated"] = true
+	result["warning"] = fmt.Sprintf("The service returned more than %d groups (--aggregate-max-rows); only the first %d were read. Group by fewer properties or add a filter.", groups, groups)
+}
+
+// aggregateWithSAPSelect relies on SAP analytical services aggregating measures
+// over the dimensions named in $select, reading up to --aggregate-max-rows groups
+func (b *ODataMCPBridge) aggregateWithSAPSelect(ctx context.Context, entitySetName string, spec *aggregateSpec) ([]map[string]interface{}, bool, error) {
+	selectProps := append([]string{}, spec.GroupBy...)
+	for _, agg := range spec.Aggregates {
+		selectProps = append(selectProps, agg.Property)
+	}
+
+	options := map[string]string{
+		constants.QuerySelect: strings.Join(selectProps, ","),
+	}
+	if spec.Filter != "" {
+		options[constants.QueryFilter] = spec.Filter
+	}
+
+	groups := make([]map[string]interface{}, 0)
+	_, truncated, err := b.forEachPage(ctx, entitySetName, options, b.aggregateMaxRows(), func(rows []interface{}) error {
+		for _, row := range rows {
+			entity, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group := make(map[string]interface{}, len(spec.GroupBy)+len(spec.Aggregates))
+			for _, g := range spec.GroupBy {
+				group[g] = entity[g]
+			}
+			for _, agg := range spec.Aggregates {
+				group[agg.As] = entity[agg.Property]
+			}
+			groups = append(groups, group)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return groups, truncated, nil
+}
+
+// aggregateAccumulator collects the running state of one aggregate within one group
+type aggregateAccumulator struct {
+	expr     aggregateExpression
+	rows     int
+	values   int
+	sum      float64
+	min      interface{}
+	max      interface{}
+	distinct map[string]struct{}
+}
+
+func (a *aggregateAccumulator) add(entity map[string]interface{}) {
+	a.rows++
+	if a.expr.With == "count" {
+		return
+	}
+
+	value, exists := entity[a.expr.Property]
+	if !exists || value == nil {
+		return
+	}
+	a.values++
+
+	switch a.expr.With {
+	case "sum", "average":
+		if n, ok := toFloat(value); ok {
+			a.sum += n
+		}
+	case "min":
+		if a.min == nil || compareValues(value, a.min) < 0 {
+			a.min = value
+		}
+	case "max":
+		if a.max == nil || compareValues(value, a.max) > 0 {
+			a.max = value
+		}
+	case "countdistinct":
+		if a.distinct == nil {
+			a.distinct = make(map[string]struct{})
+		}
+		a.distinct[fmt.Sprintf("%v", value)] = struct{}{}
+	}
+}
+
+func (a *aggregateAccumulator) result() interface{} {
+	switch a.expr.With {
+	case "count":
+		return a.rows
+	case "sum":
+		return a.sum
+	case "average":
+		if a.values == 0 {
+			return nil
+		}
+		return a.sum / float64(a.values)
+	case "min":
+		return a.min
+	case "max":
+		return a.max
+	case "countdistinct":
+		return len(a.distinct)
+	}
+	return nil
+}
+
+// aggregateClientSide pages through the entity set and aggregates in memory
+func (b *ODataMCPBridge) aggregateClientSide(ctx context.Context, entitySetName string, spec *aggregateSpec) ([]map[string]interface{}, int, bool, error) {
+	options := make(map[string]string)
+	if spec.Filter != "" {
+		options[constants.QueryFilter] = spec.Filter
+	}
+
+	// Only read the columns we need
+	needed := make([]string, 0, len(spec.GroupBy)+len(spec.Aggregates))
+	seen := make(map[string]bool)
+	for _, g := range spec.GroupBy {
+		if !seen[g] {
+			needed = append(needed, g)
+			seen[g] = true
+		}
+	}
+	for _, agg := range spec.Aggregates {
+		if agg.Property != "" && !seen[agg.Property] {
+			needed = append(needed, agg.Property)
+			seen[agg.Property] = true
+		}
+	}
+	if len(needed) > 0 {
+		options[constants.QuerySelect] = strings.Join(needed, ",")
+	}
+
+	maxRows := b.aggregateMaxRows()
+
+	type group struct {
+		keyValues    []interface{}
+		accumulators []*aggregateAccumulator
+	}
+	groups := make(map[string]*group)
+
+	scanned, truncated, err := b.forEachPage(ctx, entitySetName, options, maxRows, func(rows []interface{}) error {
+		for _, row := range rows {
+			entity, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			keyValues := make([]interface{}, len(spec.GroupBy))
+			for i, g := range spec.GroupBy {
+				keyValues[i] = entity[g]
+			}
+			keyBytes, _ := json.Marshal(keyValues)
+			key := string(keyBytes)
+
+			grp, exists := groups[key]
+			if !exists {
+				grp = &group{keyValues: keyValues}
+				for _, agg := range spec.Aggregates {
+					grp.accumulators = append(grp.accumulators, &aggregateAccumulator{expr: agg})
+				}
+				groups[key] = grp
+			}
+			for _, acc := range grp.accumulators {
+				acc.add(entity)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, scanned, false, err
+	}
+
+	// Deterministic output order
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]map[string]interface{}, 0, len(groups))
+	for _, key := range keys {
+		grp := groups[key]
+		row := make(map[string]interface{}, len(spec.GroupBy)+len(spec.Aggregates))
+		for i, g := range spec.GroupBy {
+			row[g] = grp.keyValues[i]
+		}
+		for _, acc := range grp.accumulators {
+			row[acc.expr.As] = acc.result()
+		}
+		result = append(result, row)
+	}
+
+	return result, scanned, truncated, nil
+}
+
+// isAggregationMethod checks a method name against the supported list
+func isAggregationMethod(method string) bool {
+	for _, m := range constants.AggregationMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumericEdmType reports whether an Edm type holds numbers
+func isNumericEdmType(edmType string) bool {
+	switch edmType {
+	case "Edm.Int16", "Edm.Int32", "Edm.Int64", "Edm.Byte", "Edm.SByte",
+		"Edm.Single", "Edm.Double", "Edm.Decimal":
+		return true
+	}
+	return false
+}
+
+// toFloat converts JSON numbers and SAP decimal strings to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := utils.ParseDecimalString(v)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// compareValues orders two values numerically when both are numeric, otherwise as strings.
+// ISO dates compare correctly as strings; legacy /Date(...)/ values compare by their epoch.
+func compareValues(a, b interface{}) int {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	sa, sb := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+	if ma, _, ok := utils.ParseODataLegacyDate(sa); ok {
+		if mb, _, ok := utils.ParseODataLegacyDate(sb); ok {
+			switch {
+			case ma < mb:
+				return -1
+			case ma > mb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(sa, sb)
+}
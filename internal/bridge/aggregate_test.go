@@ -0,0 +1,344 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+// aggregateTestHandler serves rows as an OData v2 collection honouring $top/$skip
+// and records the query of every request
+func aggregateTestHandler(rows []map[string]interface{}, queries *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.RawQuery)
+		q := r.URL.Query()
+		skip, _ := strconv.Atoi(q.Get("$skip"))
+		top, err := strconv.Atoi(q.Get("$top"))
+		if err != nil {
+			top = len(rows)
+		}
+		page := []map[string]interface{}{}
+		for i := skip; i < len(rows) && i < skip+top; i++ {
+			page = append(page, rows[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"d": map[string]interface{}{"results": page},
+		})
+	}
+}
+
+func TestParseAggregateSpec(t *testing.T) {
+	bridge := createTestBridge(&config.Config{})
+	entityType := bridge.metadata.EntityTypes["Product"]
+
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "valid sum and count",
+			args: map[string]interface{}{
+				"groupby": []interface{}{"ProductName"},
+				"aggregate": []interface{}{
+					map[string]interface{}{"property": "Price", "with": "sum", "as": "Total"},
+					map[string]interface{}{"with": "count"},
+				},
+			},
+		},
+		{
+			name:    "missing aggregate",
+			args:    map[string]interface{}{"groupby": []interface{}{"ProductName"}},
+			wantErr: "missing required parameter: aggregate",
+		},
+		{
+			name: "unknown groupby property",
+			args: map[string]interface{}{
+				"groupby":   []interface{}{"Color"},
+				"aggregate": []interface{}{map[string]interface{}{"with": "count"}},
+			},
+			wantErr: "unknown groupby property",
+		},
+		{
+			name: "unsupported method",
+			args: map[string]interface{}{
+				"aggregate": []interface{}{map[string]interface{}{"property": "Price", "with": "median"}},
+			},
+			wantErr: "unsupported aggregation method",
+		},
+		{
+			name: "sum of string property",
+			args: map[string]interface{}{
+				"aggregate": []interface{}{map[string]interface{}{"property": "ProductName", "with": "sum"}},
+			},
+			wantErr: "cannot sum non-numeric property",
+		},
+		{
+			name: "duplicate alias",
+			args: map[string]interface{}{
+				"aggregate": []interface{}{
+					map[string]interface{}{"property": "Price", "with": "min", "as": "X"},
+					map[string]interface{}{"property": "Price", "with": "max", "as": "X"},
+				},
+			},
+			wantErr: "duplicate result column name",
+		},
+		{
+			name: "alias injecting a transformation",
+			args: map[string]interface{}{
+				"aggregate": []interface{}{
+					map[string]interface{}{"with": "count", "as": "x)),filter(Price gt 0"},
+				},
+			},
+			wantErr: "invalid result column name",
+		},
+		{
+			name: "filter injecting a transformation",
+			args: map[string]interface{}{
+				"filter":    "Price gt 0)/groupby((ProductName),aggregate($count as n))/filter(true",
+				"aggregate": []interface{}{map[string]interface{}{"with": "count"}},
+			},
+			wantErr: "invalid filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := bridge.parseAggregateSpec(entityType, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parseAggregateSpec() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseAggregateSpec() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildApplyExpression(t *testing.T) {
+	spec := &aggregateSpec{
+		GroupBy: []string{"Country", "City"},
+		Aggregates: []aggregateExpression{
+			{Property: "Amount", With: "sum", As: "Total"},
+			{With: "count", As: "Orders"},
+		},
+		Filter: "Year eq 2024",
+	}
+
+	got := buildApplyExpression(spec)
+	want := "filter(Year eq 2024)/groupby((Country,City),aggregate(Amount with sum as Total,$count as Orders))"
+	if got != want {
+		t.Errorf("buildApplyExpression() = %q, want %q", got, want)
+	}
+
+	spec.GroupBy = nil
+	spec.Filter = ""
+	got = buildApplyExpression(spec)
+	want = "aggregate(Amount with sum as Total,$count as Orders)"
+	if got != want {
+		t.Errorf("buildApplyExpression() without groupby = %q, want %q", got, want)
+	}
+}
+
+func TestSelectAggregateStrategy(t *testing.T) {
+	bridge := createTestBridge(&config.Config{})
+	entityType := &models.EntityType{
+		Name: "SalesCube",
+		Properties: []*models.EntityProperty{
+			{Name: "Region", Type: "Edm.String", AggregationRole: constants.AggregationRoleDimension},
+			{Name: "Revenue", Type: "Edm.Decimal", AggregationRole: constants.AggregationRoleMeasure},
+			{Name: "Comment", Type: "Edm.String"},
+		},
+	}
+
+	sapSpec := &aggregateSpec{
+		GroupBy:    []string{"Region"},
+		Aggregates: []aggregateExpression{{Property: "Revenue", With: "sum", As: "Revenue_sum"}},
+	}
+	if got := bridge.selectAggregateStrategy(entityType, sapSpec); got != aggregateStrategySAPSelect {
+		t.Errorf("selectAggregateStrategy() = %s, want %s", got, aggregateStrategySAPSelect)
+	}
+
+	clientSpec := &aggregateSpec{
+		GroupBy:    []string{"Comment"},
+		Aggregates: []aggregateExpression{{Property: "Revenue", With: "sum", As: "Revenue_sum"}},
+	}
+	if got := bridge.selectAggregateStrategy(entityType, clientSpec); got != aggregateStrategyClient {
+		t.Errorf("selectAggregateStrategy() for non-dimension groupby = %s, want %s", got, aggregateStrategyClient)
+	}
+
+	bridge.metadata.Version = "4.0"
+	if got := bridge.selectAggregateStrategy(entityType, clientSpec); got != aggregateStrategyApply {
+		t.Errorf("selectAggregateStrategy() for v4 = %s, want %s", got, aggregateStrategyApply)
+	}
+}
+
+func TestHandleEntityAggregateClientSide(t *testing.T) {
+	rows := []map[string]interface{}{}
+	for i := 0; i < 25; i++ {
+		name := "A"
+		if i%2 == 1 {
+			name = "B"
+		}
+		rows = append(rows, map[string]interface{}{
+			"ProductID":   i,
+			"ProductName": name,
+			"Price":       strconv.Itoa(i) + ".50", // SAP style decimal string
+		})
+	}
+
+	var queries []string
+	bridge := newServiceTestBridge(t, &config.Config{AggregateMaxRows: 20}, aggregateTestHandler(rows, &queries))
+
+	args := map[string]interface{}{
+		"groupby": []interface{}{"ProductName"},
+		"aggregate": []interface{}{
+			map[string]interface{}{"property": "Price", "with": "sum", "as": "Total"},
+			map[string]interface{}{"with": "count", "as": "Rows"},
+			map[string]interface{}{"property": "ProductID", "with": "max"},
+		},
+	}
+
+	result, err := bridge.handleEntityAggregate(context.Background(), "Products", bridge.metadata.EntityTypes["Product"], args)
+	if err != nil {
+		t.Fatalf("handleEntityAggregate() error = %v", err)
+	}
+
+	var out struct {
+		Strategy    string                   `json:"strategy"`
+		RowsScanned int                      `json:"rows_scanned"`
+		Truncated   bool                     `json:"truncated"`
+		Warning     string                   `json:"warning"`
+		Groups      []map[string]interface{} `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+
+	if out.Strategy != aggregateStrategyClient {
+		t.Errorf("strategy = %s, want %s", out.Strategy, aggregateStrategyClient)
+	}
+	if out.RowsScanned != 20 || !out.Truncated || out.Warning == "" {
+		t.Errorf("expected truncation at 20 rows with warning, got scanned=%d truncated=%v warning=%q", out.RowsScanned, out.Truncated, out.Warning)
+	}
+	if len(out.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(out.Groups))
+	}
+
+	// Rows 0,2,...,18 are "A": sum of i+0.5 = 90 + 5 = 95
+	a := out.Groups[0]
+	if a["ProductName"] != "A" || a["Total"] != 95.0 || a["Rows"] != 10.0 || a["ProductID_max"] != 18.0 {
+		t.Errorf("unexpected group A: %v", a)
+	}
+
+	for _, q := range queries {
+		if !strings.Contains(q, "%24select=ProductName%2CPrice%2CProductID") {
+			t.Errorf("expected $select of needed columns, got query %s", q)
+		}
+	}
+}
+
+func TestHandleEntityAggregateSAPSelect(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"Region": "EMEA", "Revenue": "100.00"},
+		{"Region": "APJ", "Revenue": "40.00"},
+	}
+
+	var queries []string
+	bridge := newServiceTestBridge(t, &config.Config{AggregateMaxRows: 2}, aggregateTestHandler(rows, &queries))
+	entityType := &models.EntityType{
+		Name: "SalesCube",
+		Properties: []*models.EntityProperty{
+			{Name: "Region", Type: "Edm.String", AggregationRole: constants.AggregationRoleDimension},
+			{Name: "Revenue", Type: "Edm.Decimal", AggregationRole: constants.AggregationRoleMeasure},
+		},
+	}
+
+	args := map[string]interface{}{
+		"groupby":   []interface{}{"Region"},
+		"aggregate": []interface{}{map[string]interface{}{"property": "Revenue", "with": "sum", "as": "TotalRevenue"}},
+	}
+
+	result, err := bridge.handleEntityAggregate(context.Background(), "SalesCube", entityType, args)
+	if err != nil {
+		t.Fatalf("handleEntityAggregate() error = %v", err)
+	}
+
+	if !strings.Contains(result.(string), `"strategy":"sap_select"`) || !strings.Contains(result.(string), `"TotalRevenue":"100.00"`) {
+		t.Errorf("unexpected result: %s", result)
+	}
+	if len(queries) == 0 || !strings.Contains(queries[0], "%24select=Region%2CRevenue") {
+		t.Errorf("expected $select of dimensions and measures, got %v", queries)
+	}
+	// Exactly --aggregate-max-rows groups are complete
+	if strings.Contains(result.(string), `"truncated"`) {
+		t.Errorf("groups at the cap reported as truncated: %s", result)
+	}
+
+	// Past the cap the result is flagged like the $apply path
+	bridge.config.AggregateMaxRows = 1
+	result, err = bridge.handleEntityAggregate(context.Background(), "SalesCube", entityType, args)
+	if err != nil {
+		t.Fatalf("handleEntityAggregate() error = %v", err)
+	}
+	if !strings.Contains(result.(string), `"truncated":true`) || !strings.Contains(result.(string), `"group_count":1`) {
+		t.Errorf("expected one group flagged as truncated: %s", result)
+	}
+}
+
+func TestHandleEntityAggregateApplyPaging(t *testing.T) {
+	// The handler pages the grouped result the way a service pages $apply output
+	groups := []map[string]interface{}{}
+	for i := 0; i < 5; i++ {
+		groups = append(groups, map[string]interface{}{"ProductName": "P" + strconv.Itoa(i), "Rows": i})
+	}
+
+	var queries []string
+	bridge := newServiceTestBridge(t, &config.Config{AggregateMaxRows: 3}, aggregateTestHandler(groups, &queries))
+	bridge.metadata.Version = "4.0"
+
+	args := map[string]interface{}{
+		"groupby":   []interface{}{"ProductName"},
+		"aggregate": []interface{}{map[string]interface{}{"with": "count", "as": "Rows"}},
+	}
+	result, err := bridge.handleEntityAggregate(context.Background(), "Products", bridge.metadata.EntityTypes["Product"], args)
+	if err != nil {
+		t.Fatalf("handleEntityAggregate() error = %v", err)
+	}
+
+	var out struct {
+		Strategy  string                   `json:"strategy"`
+		Truncated bool                     `json:"truncated"`
+		Warning   string                   `json:"warning"`
+		Groups    []map[string]interface{} `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+	if out.Strategy != aggregateStrategyApply {
+		t.Fatalf("strategy = %s, want %s", out.Strategy, aggregateStrategyApply)
+	}
+	// Partial groups are never shown as complete
+	if len(out.Groups) != 3 || !out.Truncated || out.Warning == "" {
+		t.Errorf("expected 3 groups flagged as truncated, got %d groups, truncated=%v warning=%q", len(out.Groups), out.Truncated, out.Warning)
+	}
+	for _, q := range queries {
+		if !strings.Contains(q, "%24apply=groupby") {
+			t.Errorf("expected $apply on every page, got query %s", q)
+		}
+	}
+}
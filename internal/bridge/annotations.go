@@ -0,0 +1,175 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+// annotateTools sets the behaviour hints of every generated tool from its operation, an
+// output schema for the per-entity get and filter tools, and the group tools/list can be
+// limited to: the entity set or function import of the tool
+func (b *ODataMCPBridge) annotateTools() {
+	for name, info := range b.tools {
+		b.server.SetToolAnnotations(name, b.toolAnnotations(info))
+		if info.EntitySet != "" {
+			b.server.SetToolGroup(name, info.EntitySet)
+		} else if info.Function != "" {
+			b.server.SetToolGroup(name, info.Function)
+		}
+
+		entityType := b.entityTypeOf(info.EntitySet)
+		if info.EntitySet == "" || entityType == nil {
+			continue
+		}
+		switch info.Operation {
+		case constants.OpGet:
+			b.server.SetToolOutputSchema(name, b.entitySchema(info.EntitySet, entityType))
+		case constants.OpFilter:
+			b.server.SetToolOutputSchema(name, b.collectionSchema(info.EntitySet, entityType))
+		}
+	}
+}
+
+// toolAnnotations derives the hints of a tool. The tools only ever talk to the one
+// configured service, so none of them is open-world.
+func (b *ODataMCPBridge) toolAnnotations(info *models.ToolInfo) *mcp.ToolAnnotations {
+	var readOnly, destructive, idempotent bool
+	switch info.Operation {
+	case constants.OpCreate:
+		// Creating never changes existing data, but each call adds another entity
+	case constants.OpUpdate, constants.OpDelete:
+		destructive, idempotent = true, true
+	case constants.OpBulk, constants.OpUndo:
+		destructive = true
+	case constants.OpExport:
+		// Writes a file to the export directory, replacing one of the same name
+		destructive, idempotent = true, true
+	case "call_function":
+		readOnly = !b.allowsModifyingFunctions()
+		destructive = !readOnly
+	case "":
+		// Function import tools
+		function := b.metadata.FunctionImports[info.Function]
+		readOnly = function == nil || !b.isFunctionModifying(function)
+		destructive = !readOnly
+	default:
+		// info, filter, count, search, get, aggregate and the lazy schema/list_functions tools
+		readOnly = true
+	}
+
+	annotations := &mcp.ToolAnnotations{
+		ReadOnlyHint:  boolPtr(readOnly),
+		OpenWorldHint: boolPtr(false),
+	}
+	// The other hints are only meaningful for tools that modify data
+	if !readOnly {
+		annotations.DestructiveHint = boolPtr(destructive)
+		annotations.IdempotentHint = boolPtr(idempotent)
+	}
+	return annotations
+}
+
+// allowsModifyingFunctions checks whether the generic call_function tool can reach a
+// function import that modifies data
+func (b *ODataMCPBridge) allowsModifyingFunctions() bool {
+	if b.config.ReadOnly || !b.config.AllowModifyingFunctions() {
+		return false
+	}
+	for name, function := range b.metadata.FunctionImports {
+		if b.shouldIncludeFunction(name) && b.policy.AllowsFunction(name) && b.isFunctionModifying(function) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionSchema is the output schema of a filter tool: the OData collection response.
+// value is not required because summarize mode returns column statistics instead.
+func (b *ODataMCPBridge) collectionSchema(entitySetName string, entityType *models.EntityType) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value": map[string]interface{}{
+				"type":  "array",
+				"items": b.entityObjectSchema(entitySetName, entityType),
+			},
+			"@odata.count":    map[string]interface{}{"type": "integer"},
+			"@odata.nextLink": map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// entitySchema is the output schema of a get tool: the entity wrapped in value
+func (b *ODataMCPBridge) entitySchema(entitySetName string, entityType *models.EntityType) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value": b.entityObjectSchema(entitySetName, entityType),
+		},
+	}
+}
+
+// entityObjectSchema describes the readable properties of an entity. Nothing is required,
+// since $select may leave properties out, and expanded navigation properties and service
+// annotations are allowed as additional properties. Redacted properties are left untyped
+// because masking turns their values into strings.
+func (b *ODataMCPBridge) entityObjectSchema(entitySetName string, entityType *models.EntityType) map[string]interface{} {
+	rule := b.policy.EntitySet(entitySetName)
+	properties := make(map[string]interface{})
+	for _, name := range readableProperties(rule, entityType) {
+		if b.redactor.IsRedacted(name) {
+			continue
+		}
+		if prop := propertyOf(entityType, name); prop != nil {
+			properties[name] = propertySchema(prop)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// propertySchema maps an EDM property type to a JSON schema. 64-bit and decimal numbers
+// may arrive as strings (OData v2, IEEE754Compatible), and doubles may be "NaN" or "INF".
+func propertySchema(prop *models.EntityProperty) map[string]interface{} {
+	var types []string
+	switch prop.Type {
+	case "Edm.Boolean":
+		types = []string{"boolean"}
+	case "Edm.Byte", "Edm.SByte", "Edm.Int16", "Edm.Int32":
+		types = []string{"integer"}
+	case "Edm.Int64", "Edm.Decimal", "Edm.Double", "Edm.Single":
+		types = []string{"number", "string"}
+	case "Edm.String", "Edm.Guid", "Edm.Binary", "Edm.Date", "Edm.DateTime", "Edm.DateTimeOffset",
+		"Edm.Time", "Edm.TimeOfDay", "Edm.Duration":
+		types = []string{"string"}
+	}
+
+	schema := map[string]interface{}{}
+	if prop.Description != nil && *prop.Description != "" {
+		schema["description"] = *prop.Description
+	}
+	if types == nil {
+		// Spatial, stream and complex types are not constrained
+		return schema
+	}
+	if prop.Nullable {
+		types = append(types, "null")
+	}
+	if len(types) == 1 {
+		schema["type"] = types[0]
+	} else {
+		schema["type"] = types
+	}
+	return schema
+}
+
+// boolPtr returns a pointer to a bool, for optional hints
+func boolPtr(v bool) *bool {
+	return &v
+}
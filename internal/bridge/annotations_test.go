@@ -0,0 +1,169 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+)
+
+// listTools returns the tools of tools/list by name
+func listTools(t *testing.T, bridge *ODataMCPBridge) map[string]map[string]interface{} {
+	t.Helper()
+	result, rpcErr := resourceRequest(t, bridge, "tools/list", nil)
+	if rpcErr != nil {
+		t.Fatalf("tools/list failed: %+v", rpcErr)
+	}
+	tools := make(map[string]map[string]interface{})
+	for _, tool := range result["tools"].([]interface{}) {
+		tool := tool.(map[string]interface{})
+		tools[tool["name"].(string)] = tool
+	}
+	return tools
+}
+
+func TestToolAnnotations(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+	bridge.annotateTools()
+	initializeClient(t, bridge, "2025-06-18")
+	tools := listTools(t, bridge)
+
+	filterName := toolName(t, bridge, constants.OpFilter)
+	updateName := toolName(t, bridge, constants.OpUpdate)
+	wantHints := map[string]map[string]interface{}{
+		filterName: {"readOnlyHint": true, "openWorldHint": false},
+		updateName: {"readOnlyHint": false, "destructiveHint": true, "idempotentHint": true, "openWorldHint": false},
+	}
+	for name, info := range bridge.tools {
+		if info.EntitySet == "OrderDetails" && info.Operation == constants.OpCreate {
+			wantHints[name] = map[string]interface{}{"readOnlyHint": false, "destructiveHint": false, "idempotentHint": false, "openWorldHint": false}
+		}
+	}
+	for name, want := range wantHints {
+		if got := tools[name]["annotations"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("annotations of %s = %v, want %v", name, got, want)
+		}
+	}
+
+	// The output schema describes readable properties only
+	schema, _ := tools[filterName]["outputSchema"].(map[string]interface{})
+	value, _ := schema["properties"].(map[string]interface{})["value"].(map[string]interface{})
+	entity, _ := value["items"].(map[string]interface{})
+	properties, _ := entity["properties"].(map[string]interface{})
+	wantProperties := map[string]interface{}{
+		"ProductID":   map[string]interface{}{"type": "integer"},
+		"ProductName": map[string]interface{}{"type": "string", "description": "Test product description"},
+	}
+	if !reflect.DeepEqual(properties, wantProperties) {
+		t.Errorf("output schema properties = %v, want %v", properties, wantProperties)
+	}
+	if _, exists := tools[updateName]["outputSchema"]; exists {
+		t.Errorf("update tool has an output schema")
+	}
+
+	// Results come back as structured content as well as text
+	result, rpcErr := resourceRequest(t, bridge, "tools/call", map[string]interface{}{"name": filterName, "arguments": map[string]interface{}{}})
+	if rpcErr != nil {
+		t.Fatalf("tools/call failed: %+v", rpcErr)
+	}
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	structured, _ := result["structuredContent"].(map[string]interface{})
+	rows, _ := structured["value"].([]interface{})
+	if !strings.Contains(text, "Chai") || len(rows) != 1 || rows[0].(map[string]interface{})["ProductName"] != "Chai" {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	// Older clients see neither annotations, output schemas nor structured content
+	initializeClient(t, bridge, "2024-11-05")
+	tool := listTools(t, bridge)[filterName]
+	if _, exists := tool["annotations"]; exists {
+		t.Errorf("annotations sent to a 2024-11-05 client: %v", tool)
+	}
+	if _, exists := tool["outputSchema"]; exists {
+		t.Errorf("output schema sent to a 2024-11-05 client: %v", tool)
+	}
+	result, _ = resourceRequest(t, bridge, "tools/call", map[string]interface{}{"name": filterName, "arguments": map[string]interface{}{}})
+	if _, exists := result["structuredContent"]; exists {
+		t.Errorf("structured content sent to a 2024-11-05 client: %v", result)
+	}
+
+	initializeClient(t, bridge, "2025-03-26")
+	tool = listTools(t, bridge)[filterName]
+	if _, exists := tool["outputSchema"]; exists || tool["annotations"] == nil {
+		t.Errorf("unexpected tool for a 2025-03-26 client: %v", tool)
+	}
+}
+
+func TestToolsListPaging(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+	bridge.annotateTools()
+	initializeClient(t, bridge, "2024-11-05")
+	all := listTools(t, bridge)
+
+	// Paging through returns every tool exactly once
+	bridge.server.SetToolsPageSize(2)
+	seen := make(map[string]bool)
+	params := map[string]interface{}{}
+	for pages := 0; ; pages++ {
+		if pages > len(all) {
+			t.Fatal("tools/list does not stop paging")
+		}
+		result, rpcErr := resourceRequest(t, bridge, "tools/list", params)
+		if rpcErr != nil {
+			t.Fatalf("tools/list failed: %+v", rpcErr)
+		}
+		page := result["tools"].([]interface{})
+		if len(page) > 2 {
+			t.Fatalf("page has %d tools, want at most 2", len(page))
+		}
+		for _, tool := range page {
+			name := tool.(map[string]interface{})["name"].(string)
+			if seen[name] {
+				t.Errorf("%s listed twice", name)
+			}
+			seen[name] = true
+		}
+		cursor, ok := result["nextCursor"].(string)
+		if !ok {
+			break
+		}
+		params = map[string]interface{}{"cursor": cursor}
+	}
+	if len(seen) != len(all) {
+		t.Errorf("paging listed %d tools, want %d", len(seen), len(all))
+	}
+
+	// Groups limit the list to the tools of those entity sets, across pages
+	bridge.server.SetToolsPageSize(0)
+	result, rpcErr := resourceRequest(t, bridge, "tools/list", map[string]interface{}{"groups": []string{"Products"}})
+	if rpcErr != nil {
+		t.Fatalf("tools/list failed: %+v", rpcErr)
+	}
+	grouped := result["tools"].([]interface{})
+	if len(grouped) == 0 || len(grouped) >= len(all) {
+		t.Fatalf("groups listed %d of %d tools", len(grouped), len(all))
+	}
+	for _, tool := range grouped {
+		name := tool.(map[string]interface{})["name"].(string)
+		if info := bridge.tools[name]; info != nil && info.EntitySet != "Products" && (info.EntitySet != "" || info.Function != "") {
+			t.Errorf("%s of %s listed for group Products", name, info.EntitySet)
+		}
+	}
+
+	if _, rpcErr := resourceRequest(t, bridge, "tools/list", map[string]interface{}{"cursor": "not-a-cursor"}); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("expected invalid params for a bad cursor, got %+v", rpcErr)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/audit"
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/client"
+	"github.com/zmcp/odata-mcp/internal/debug"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+)
+
+// auditToolCall is the tool middleware that writes an audit record for every tool call,
+// with the OData requests it sent and the entities it changed
+func (b *ODataMCPBridge) auditToolCall(name string, next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		// Handlers consume their arguments, so copy and mask them first
+		rec := &audit.Record{
+			Time:      time.Now().UTC().Format(time.RFC3339Nano),
+			Client:    b.server.ClientInfo(ctx),
+			Tool:      name,
+			Arguments: b.auditArguments(args),
+			Status:    audit.StatusOK,
+		}
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			rec.User = principal.Subject
+		}
+
+		ctx, requests := client.WithRequestLog(ctx)
+		start := time.Now()
+		result, err := next(ctx, args)
+		rec.DurationMs = time.Since(start).Milliseconds()
+
+		rec.Requests = requests.Requests()
+		rec.Affected = affectedEntities(rec.Requests)
+		if err != nil {
+			rec.Status = audit.StatusError
+			rec.Error = err.Error()
+		}
+		if writeErr := b.audit.Write(rec); writeErr != nil {
+			logger.WarnContext(ctx, "Failed to write audit record", "error", writeErr)
+		}
+		return result, err
+	}
+}
+
+// auditArguments copies tool arguments with credentials masked and the redaction rules applied
+func (b *ODataMCPBridge) auditArguments(args map[string]interface{}) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	masked, _ := maskArgument("", args).(map[string]interface{})
+	b.redactor.Apply(masked)
+	return masked
+}
+
+func maskArgument(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = maskArgument(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = maskArgument(key, item)
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		if key != "" && debug.IsSensitiveKey(key) {
+			return debug.MaskPassword(fmt.Sprint(v))
+		}
+		return v
+	}
+}
+
+// affectedEntities lists the entities written by successful requests: the addressed entity
+// of updates and deletes, and the Location of created ones
+func affectedEntities(requests []client.LoggedRequest) []string {
+	var affected []string
+	seen := make(map[string]bool)
+	add := func(rawURL string) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return
+		}
+		entity, err := url.PathUnescape(path.Base(u.EscapedPath()))
+		if err != nil || !strings.HasSuffix(entity, ")") || seen[entity] {
+			return
+		}
+		seen[entity] = true
+		affected = append(affected, entity)
+	}
+	for _, req := range requests {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Status < 200 || req.Status >= 300 {
+			continue
+		}
+		if req.Location != "" {
+			add(req.Location)
+		} else {
+			add(req.URL)
+		}
+	}
+	return affected
+}
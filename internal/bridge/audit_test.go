@@ -0,0 +1,100 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/audit"
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/redact"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+func TestAuditToolCalls(t *testing.T) {
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			w.Header().Set("X-CSRF-Token", "token")
+			w.Write([]byte(`{"d":{"ProductID":7,"ProductName":"Chai","Price":"18.00"}}`))
+		case http.MethodPost:
+			w.Header().Set("Location", "http://"+r.Host+"/Products(8)")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"d":{"ProductID":8}}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("audit.Open() error = %v", err)
+	}
+	bridge.hintManager = hint.NewManager()
+	bridge.audit = logger
+	bridge.redactor, _ = redact.New([]string{"price"}, nil, nil, redact.ModeMask)
+	bridge.server.Use(bridge.auditToolCall)
+	bridge.server.Use(bridge.redactToolCall)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"clientInfo":      map[string]interface{}{"name": "test-client", "version": "1.0"},
+	})
+	bridge.server.HandleMessage(context.Background(), &transport.Message{JSONRPC: "2.0", ID: json.RawMessage("0"), Method: "initialize", Params: params})
+
+	callTool(t, bridge, constants.OpCreate, map[string]interface{}{"ProductName": "Chang", "Price": 4711.25, "api_key": "s3cret-key"})
+	callTool(t, bridge, constants.OpUpdate, map[string]interface{}{"ProductID": 7, "ProductName": "Chai Tea"})
+	logger.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit records, got %d:\n%s", len(lines), data)
+	}
+	if strings.Contains(string(data), "4711") || strings.Contains(string(data), "s3cret") {
+		t.Errorf("audit log leaks secrets:\n%s", data)
+	}
+
+	var created audit.Record
+	if err := json.Unmarshal([]byte(lines[0]), &created); err != nil {
+		t.Fatalf("invalid audit record: %v", err)
+	}
+	if created.Client["name"] != "test-client" || created.Status != audit.StatusOK || created.Arguments["ProductName"] != "Chang" {
+		t.Errorf("unexpected record: %s", lines[0])
+	}
+	if len(created.Affected) != 1 || created.Affected[0] != "Products(8)" {
+		t.Errorf("Affected = %v, want [Products(8)]", created.Affected)
+	}
+	last := created.Requests[len(created.Requests)-1]
+	if last.Method != http.MethodPost || last.Status != http.StatusCreated || !strings.HasSuffix(last.URL, "/Products") {
+		t.Errorf("unexpected request: %+v", last)
+	}
+
+	var updated audit.Record
+	json.Unmarshal([]byte(lines[1]), &updated)
+	if len(updated.Affected) != 1 || updated.Affected[0] != "Products(7)" {
+		t.Errorf("Affected = %v, want [Products(7)]", updated.Affected)
+	}
+
+	report, err := audit.Verify([]string{path})
+	if err != nil || !report.OK() {
+		t.Errorf("Verify() = %+v, %v", report, err)
+	}
+}
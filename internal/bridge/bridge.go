@@ -6,24 +6,33 @@ package bridge
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/zmcp/odata-mcp/internal/audit"
+	"github.com/zmcp/odata-mcp/internal/auth"
 	"github.com/zmcp/odata-mcp/internal/client"
 	"github.com/zmcp/odata-mcp/internal/config"
 	"github.com/zmcp/odata-mcp/internal/constants"
 	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/journal"
+	"github.com/zmcp/odata-mcp/internal/logging"
 	"github.com/zmcp/odata-mcp/internal/mcp"
 	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
+	"github.com/zmcp/odata-mcp/internal/redact"
 	"github.com/zmcp/odata-mcp/internal/transport"
 	"github.com/zmcp/odata-mcp/internal/utils"
 )
 
+var logger = logging.Logger("bridge")
+
 // ODataMCPBridge connects OData services to MCP
 type ODataMCPBridge struct {
 	config      *config.Config
@@ -32,9 +41,20 @@ type ODataMCPBridge struct {
 	metadata    *models.ODataMetadata
 	tools       map[string]*models.ToolInfo
 	hintManager *hint.Manager
+	policy      *policy.Policy    // Access policy (nil = unrestricted)
+	redactor    *redact.Redactor  // PII redaction rules (nil = no redaction)
+	audit       *audit.Logger     // Tool call audit log (nil = not audited)
+	journal     *journal.Journal  // Change journal for undo (nil = not journaled)
+	passthrough *auth.Passthrough // Per-user OData credentials (nil = configured credentials)
 	mu          sync.RWMutex
 	running     bool
 	stopChan    chan struct{}
+
+	confirmMu     sync.Mutex
+	confirmTokens map[string]*pendingConfirmation // Issued confirm tokens awaiting approval
+
+	completionMu    sync.Mutex
+	completionCache map[string]completionEntry // Completed values by caller, property and prefix
 }
 
 // NewODataMCPBridge creates a new bridge instance
@@ -52,9 +72,7 @@ func NewODataMCPBridge(cfg *config.Config) (*ODataMCPBridge, error) {
 	// Apply timeout configuration
 	if cfg.HTTPTimeout > 0 {
 		odataClient.SetTimeout(time.Duration(cfg.HTTPTimeout) * time.Second)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] HTTP timeout set to %d seconds\n", cfg.HTTPTimeout)
-		}
+		logger.Info("HTTP timeout set", "seconds", cfg.HTTPTimeout)
 	}
 
 	// Apply retry configuration (was defined but never applied - bug fix)
@@ -65,21 +83,26 @@ func NewODataMCPBridge(cfg *config.Config) (*ODataMCPBridge, error) {
 			cfg.RetryMaxBackoffMs,
 			cfg.RetryBackoffMultiplier,
 		)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Retry configured: max=%d, initialBackoff=%dms, maxBackoff=%dms, multiplier=%.1f\n",
-				cfg.RetryMaxAttempts, cfg.RetryInitialBackoffMs, cfg.RetryMaxBackoffMs, cfg.RetryBackoffMultiplier)
-		}
+		logger.Info("Retry configured", "max_attempts", cfg.RetryMaxAttempts, "initial_backoff_ms", cfg.RetryInitialBackoffMs,
+			"max_backoff_ms", cfg.RetryMaxBackoffMs, "multiplier", cfg.RetryBackoffMultiplier)
 	}
 
 	// Create MCP server
 	mcpServer := mcp.NewServer(constants.MCPServerName, constants.MCPServerVersion)
 
-	// Set protocol version if specified
+	// Set the protocol version offered to clients that ask for an unsupported one
 	if cfg.ProtocolVersion != "" {
-		mcpServer.SetProtocolVersion(cfg.ProtocolVersion)
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Using MCP protocol version: %s\n", cfg.ProtocolVersion)
+		if err := mcpServer.SetProtocolVersion(cfg.ProtocolVersion); err != nil {
+			return nil, err
 		}
+		logger.Info("Offering MCP protocol version", "version", cfg.ProtocolVersion)
+	}
+	mcpServer.SetNullIDCompat(cfg.NullIDCompat)
+	if cfg.ResourcePollInterval > 0 {
+		mcpServer.SetResourcePollInterval(time.Duration(cfg.ResourcePollInterval) * time.Second)
+	}
+	if cfg.ToolsPageSize > 0 {
+		mcpServer.SetToolsPageSize(cfg.ToolsPageSize)
 	}
 
 	// Create hint manager
@@ -87,18 +110,77 @@ func NewODataMCPBridge(cfg *config.Config) (*ODataMCPBridge, error) {
 
 	// Load hints from file if specified or default location
 	if err := hintMgr.LoadFromFile(cfg.HintsFile); err != nil {
-		if cfg.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to load hints file: %v\n", err)
-		}
+		logger.Info("Failed to load hints file", "error", err)
 	}
 
 	// Set CLI hint if provided
 	if cfg.Hint != "" {
 		if err := hintMgr.SetCLIHint(cfg.Hint); err != nil {
-			if cfg.Verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to parse CLI hint: %v\n", err)
-			}
+			logger.Info("Failed to parse CLI hint", "error", err)
+		}
+	}
+
+	// Load the access policy; a policy that cannot be read must not be silently ignored
+	var accessPolicy *policy.Policy
+	if cfg.PolicyFile != "" {
+		p, err := policy.Load(cfg.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load access policy: %w", err)
+		}
+		accessPolicy = p
+		logger.Info("Access policy loaded", "file", cfg.PolicyFile)
+	}
+
+	// Compile the redaction rules; every tool result passes through them
+	var redactor *redact.Redactor
+	if cfg.HasRedaction() {
+		r, err := redact.New(strings.Split(cfg.RedactProperties, ","), strings.Split(cfg.RedactTypes, ","), cfg.RedactPatterns, cfg.RedactMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rules: %w", err)
+		}
+		redactor = r
+		logger.Info("Redacting responses", "mode", r.Mode())
+	}
+
+	// Open the audit log before anything can be called
+	var auditLog *audit.Logger
+	if cfg.AuditLog != "" {
+		l, err := audit.Open(cfg.AuditLog, cfg.AuditMaxSize, cfg.AuditMaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
 		}
+		auditLog = l
+		logger.Info("Auditing tool calls", "file", cfg.AuditLog)
+	}
+
+	// Open the change journal; earlier changes stay available for undo
+	var changeJournal *journal.Journal
+	if cfg.JournalFile != "" {
+		j, err := journal.Open(cfg.JournalFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open change journal: %w", err)
+		}
+		changeJournal = j
+		logger.Info("Journaling changes", "file", cfg.JournalFile)
+	}
+
+	// Map authenticated callers to their own OData credentials
+	var passthrough *auth.Passthrough
+	if cfg.Passthrough != "" {
+		p, err := auth.NewPassthrough(auth.PassthroughConfig{
+			Mode:            cfg.Passthrough,
+			TokenURL:        cfg.TokenExchangeURL,
+			ClientID:        cfg.TokenExchangeClientID,
+			ClientSecret:    cfg.TokenExchangeClientSecret,
+			Audience:        cfg.TokenExchangeAudience,
+			Scope:           cfg.TokenExchangeScope,
+			CredentialsFile: cfg.UserCredentialsFile,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential passthrough: %w", err)
+		}
+		passthrough = p
+		logger.Info("Passing caller identities on to the OData service", "mode", cfg.Passthrough)
 	}
 
 	bridge := &ODataMCPBridge{
@@ -107,11 +189,32 @@ func NewODataMCPBridge(cfg *config.Config) (*ODataMCPBridge, error) {
 		server:      mcpServer,
 		tools:       make(map[string]*models.ToolInfo),
 		hintManager: hintMgr,
+		policy:      accessPolicy,
+		redactor:    redactor,
+		audit:       auditLog,
+		journal:     changeJournal,
+		passthrough: passthrough,
 		stopChan:    make(chan struct{}),
 	}
+	// The audit middleware runs outermost, so it records errors as the client sees them
+	if auditLog != nil {
+		mcpServer.Use(bridge.auditToolCall)
+	}
+	if redactor != nil {
+		mcpServer.Use(bridge.redactToolCall)
+	}
+	if passthrough != nil {
+		mcpServer.Use(bridge.passthroughToolCall)
+	}
 
 	// Initialize metadata and tools
 	if err := bridge.initialize(); err != nil {
+		if auditLog != nil {
+			auditLog.Close()
+		}
+		if changeJournal != nil {
+			changeJournal.Close()
+		}
 		return nil, fmt.Errorf("failed to initialize bridge: %w", err)
 	}
 
@@ -138,10 +241,19 @@ func (b *ODataMCPBridge) initialize() error {
 
 	b.metadata = metadata
 
+	if err := b.validatePolicy(); err != nil {
+		return err
+	}
+	b.redactor.ResolveTypes(metadata)
+
 	// Generate tools
 	if err := b.generateTools(); err != nil {
 		return fmt.Errorf("failed to generate tools: %w", err)
 	}
+	b.annotateTools()
+	b.generateResources()
+	b.generatePrompts()
+	b.server.SetCompleter(b.complete)
 
 	return nil
 }
@@ -157,10 +269,7 @@ func (b *ODataMCPBridge) shouldUseLazyMode() bool {
 	if b.config.LazyThreshold > 0 {
 		estimatedTools := b.estimateToolCount()
 		if estimatedTools > b.config.LazyThreshold {
-			if b.config.Verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Auto-enabling lazy mode: estimated %d tools exceeds threshold %d\n",
-					estimatedTools, b.config.LazyThreshold)
-			}
+			logger.Info("Auto-enabling lazy mode", "estimated_tools", estimatedTools, "threshold", b.config.LazyThreshold)
 			return true
 		}
 	}
@@ -173,15 +282,18 @@ func (b *ODataMCPBridge) estimateToolCount() int {
 	count := 1 // service_info tool
 
 	for name, entitySet := range b.metadata.EntitySets {
-		if !b.shouldIncludeEntity(name) {
+		if !b.shouldIncludeEntity(name) || !b.policy.AllowsEntitySet(name) {
 			continue
 		}
 
-		// Each entity can have up to 7 tools: filter, count, search, get, create, update, delete
+		// Each entity can have up to 8 tools: filter, count, aggregate, search, get, create, update, delete
 		toolsPerEntity := 0
 
 		if b.config.IsOperationEnabled('F') {
-			toolsPerEntity += 2 // filter + count
+			toolsPerEntity += 3 // filter + count + aggregate
+			if b.config.ExportDir != "" {
+				toolsPerEntity++ // export
+			}
 		}
 		if entitySet.Searchable && b.config.IsOperationEnabled('S') {
 			toolsPerEntity++
@@ -202,9 +314,16 @@ func (b *ODataMCPBridge) estimateToolCount() int {
 		count += toolsPerEntity
 	}
 
+	if len(b.bulkOperations()) > 0 {
+		count++ // bulk_import
+	}
+	if b.journal != nil && !b.config.IsReadOnly() {
+		count += 2 // list_changes + undo_change
+	}
+
 	// Add function imports
 	for name, function := range b.metadata.FunctionImports {
-		if !b.shouldIncludeFunction(name) {
+		if !b.shouldIncludeFunction(name) || !b.policy.AllowsFunction(name) {
 			continue
 		}
 		if !b.config.IsOperationEnabled('A') {
@@ -223,16 +342,13 @@ func (b *ODataMCPBridge) estimateToolCount() int {
 func (b *ODataMCPBridge) generateTools() error {
 	// Check if we should use lazy mode
 	if b.shouldUseLazyMode() {
-		if b.config.Verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Using lazy metadata mode (10 generic tools)\n")
-		}
+		logger.Info("Using lazy metadata mode (10 generic tools)")
 		return b.generateLazyTools()
 	}
 
 	// Eager mode (default) - generate per-entity tools
-	if b.config.Verbose {
-		estimatedTools := b.estimateToolCount()
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Using eager mode (generating ~%d tools)\n", estimatedTools)
+	if logger.Enabled(context.Background(), slog.LevelInfo) {
+		logger.Info("Using eager mode", "estimated_tools", b.estimateToolCount())
 	}
 
 	return b.generateEagerTools()
@@ -246,7 +362,7 @@ func (b *ODataMCPBridge) generateEagerTools() error {
 	// 2. Generate entity set tools in alphabetical order
 	entityNames := make([]string, 0, len(b.metadata.EntitySets))
 	for name := range b.metadata.EntitySets {
-		if b.shouldIncludeEntity(name) {
+		if b.shouldIncludeEntity(name) && b.policy.AllowsEntitySet(name) {
 			entityNames = append(entityNames, name)
 		}
 	}
@@ -257,10 +373,22 @@ func (b *ODataMCPBridge) generateEagerTools() error {
 		b.generateEntitySetTools(name, entitySet)
 	}
 
-	// 3. Generate function import tools in alphabetical order
+	// 3. Generate the bulk import tool (needs an import directory and a modifying operation)
+	if len(b.bulkOperations()) > 0 {
+		if err := b.generateBulkTool(); err != nil {
+			return fmt.Errorf("failed to generate bulk import tool: %w", err)
+		}
+	}
+
+	// 4. Generate the change journal tools
+	if b.journal != nil && !b.config.IsReadOnly() {
+		b.generateJournalTools()
+	}
+
+	// 5. Generate function import tools in alphabetical order
 	functionNames := make([]string, 0, len(b.metadata.FunctionImports))
 	for name := range b.metadata.FunctionImports {
-		if b.shouldIncludeFunction(name) {
+		if b.shouldIncludeFunction(name) && b.policy.AllowsFunction(name) {
 			functionNames = append(functionNames, name)
 		}
 	}
@@ -271,17 +399,13 @@ func (b *ODataMCPBridge) generateEagerTools() error {
 
 		// Check if actions are enabled
 		if !b.config.IsOperationEnabled('A') {
-			if b.config.Verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Skipping function %s - actions are disabled\n", name)
-			}
+			logger.Debug("Skipping function, actions are disabled", "function", name)
 			continue
 		}
 
 		// Skip modifying functions in read-only mode unless functions are allowed
 		if b.config.ReadOnly || (!b.config.AllowModifyingFunctions() && b.isFunctionModifying(function)) {
-			if b.config.Verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Skipping function %s in read-only mode (HTTP method: %s)\n", name, function.HTTPMethod)
-			}
+			logger.Debug("Skipping function in read-only mode", "function", name, "http_method", function.HTTPMethod)
 			continue
 		}
 		b.generateFunctionTool(name, function)
@@ -445,44 +569,54 @@ func (b *ODataMCPBridge) generateEntitySetTools(entitySetName string, entitySet
 	// Get entity type
 	entityType, exists := b.metadata.EntityTypes[entitySet.EntityType]
 	if !exists {
-		if b.config.Verbose {
-			fmt.Printf("[VERBOSE] Entity type not found for entity set %s: %s\n", entitySetName, entitySet.EntityType)
-		}
+		logger.Warn("Entity type not found for entity set", "entity_set", entitySetName, "entity_type", entitySet.EntityType)
 		return
 	}
 
+	rule := b.policy.EntitySet(entitySetName)
+
 	// Generate filter/list tool
-	if b.config.IsOperationEnabled('F') {
+	if b.config.IsOperationEnabled('F') && rule.AllowsOperation(constants.OpFilter) {
 		b.generateFilterTool(entitySetName, entitySet, entityType)
 	}
 
 	// Generate count tool (consider it part of filter/read operations)
-	if b.config.IsOperationEnabled('F') {
+	if b.config.IsOperationEnabled('F') && rule.AllowsOperation(constants.OpCount) {
 		b.generateCountTool(entitySetName, entitySet, entityType)
 	}
 
+	// Generate aggregate tool (read-only, part of filter operations)
+	if b.config.IsOperationEnabled('F') && rule.AllowsOperation(constants.OpAggregate) {
+		b.generateAggregateTool(entitySetName, entitySet, entityType)
+	}
+
+	// Generate export tool when an export directory is configured
+	if b.config.ExportDir != "" && b.config.IsOperationEnabled('F') && rule.AllowsOperation(constants.OpExport) {
+		b.generateExportTool(entitySetName, entitySet, entityType)
+	}
+
 	// Generate search tool if supported
-	if entitySet.Searchable && b.config.IsOperationEnabled('S') {
+	if entitySet.Searchable && b.config.IsOperationEnabled('S') && rule.AllowsOperation(constants.OpSearch) {
 		b.generateSearchTool(entitySetName, entitySet, entityType)
 	}
 
 	// Generate get tool
-	if b.config.IsOperationEnabled('G') {
+	if b.config.IsOperationEnabled('G') && rule.AllowsOperation(constants.OpGet) {
 		b.generateGetTool(entitySetName, entitySet, entityType)
 	}
 
 	// Generate create tool if allowed and not in read-only mode
-	if entitySet.Creatable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('C') {
+	if entitySet.Creatable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('C') && rule.AllowsOperation(constants.OpCreate) {
 		b.generateCreateTool(entitySetName, entitySet, entityType)
 	}
 
 	// Generate update tool if allowed and not in read-only mode
-	if entitySet.Updatable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('U') {
+	if entitySet.Updatable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('U') && rule.AllowsOperation(constants.OpUpdate) {
 		b.generateUpdateTool(entitySetName, entitySet, entityType)
 	}
 
 	// Generate delete tool if allowed and not in read-only mode
-	if entitySet.Deletable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('D') {
+	if entitySet.Deletable && !b.config.IsReadOnly() && b.config.IsOperationEnabled('D') && rule.AllowsOperation(constants.OpDelete) {
 		b.generateDeleteTool(entitySetName, entitySet, entityType)
 	}
 }
@@ -492,7 +626,7 @@ func (b *ODataMCPBridge) generateFilterTool(entitySetName string, entitySet *mod
 	opName := constants.GetToolOperationName(constants.OpFilter, b.config.ToolShrink)
 	toolName := b.formatToolName(opName, entitySetName)
 
-	description := fmt.Sprintf("List/filter %s entities with OData query options", entitySetName)
+	description := fmt.Sprintf("List/filter %s entities with OData query options, or summarize them column by column", entitySetName) + b.policyToolNote(entitySetName)
 
 	// Build input schema with standard OData parameters
 	properties := map[string]interface{}{
@@ -525,6 +659,10 @@ func (b *ODataMCPBridge) generateFilterTool(entitySetName string, entitySet *mod
 			"description": "Include total count of matching entities (v4) or use $inlinecount for v2",
 		},
 	}
+	for name, schema := range summaryInputProperties() {
+		properties[name] = schema
+	}
+	b.applyPolicyTopLimit(entitySetName, properties)
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -555,7 +693,7 @@ func (b *ODataMCPBridge) generateCountTool(entitySetName string, entitySet *mode
 	opName := constants.GetToolOperationName(constants.OpCount, b.config.ToolShrink)
 	toolName := b.formatToolName(opName, entitySetName)
 
-	description := fmt.Sprintf("Get count of %s entities with optional filter", entitySetName)
+	description := fmt.Sprintf("Get count of %s entities with optional filter", entitySetName) + b.policyToolNote(entitySetName)
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -591,7 +729,7 @@ func (b *ODataMCPBridge) generateSearchTool(entitySetName string, entitySet *mod
 	opName := constants.GetToolOperationName(constants.OpSearch, b.config.ToolShrink)
 	toolName := b.formatToolName(opName, entitySetName)
 
-	description := fmt.Sprintf("Full-text search %s entities", entitySetName)
+	description := fmt.Sprintf("Full-text search %s entities", entitySetName) + b.policyToolNote(entitySetName)
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -615,6 +753,7 @@ func (b *ODataMCPBridge) generateSearchTool(entitySetName string, entitySet *mod
 			"required": []string{"search"},
 		},
 	}
+	b.applyPolicyTopLimit(entitySetName, tool.InputSchema["properties"].(map[string]interface{}))
 
 	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return b.handleEntitySearch(ctx, entitySetName, args)
@@ -636,7 +775,7 @@ func (b *ODataMCPBridge) generateGetTool(entitySetName string, entitySet *models
 	opName := constants.GetToolOperationName(constants.OpGet, b.config.ToolShrink)
 	toolName := b.formatToolName(opName, entitySetName)
 
-	description := fmt.Sprintf("Get a single %s entity by key", entitySetName)
+	description := fmt.Sprintf("Get a single %s entity by key", entitySetName) + b.policyToolNote(entitySetName)
 
 	// Build key properties for input schema
 	properties := make(map[string]interface{})
@@ -705,11 +844,16 @@ func (b *ODataMCPBridge) generateCreateTool(entitySetName string, entitySet *mod
 	properties := make(map[string]interface{})
 	required := make([]string, 0)
 
+	rule := b.policy.EntitySet(entitySetName)
 	for _, prop := range entityType.Properties {
 		// Skip key properties that are auto-generated
 		if prop.IsKey {
 			continue
 		}
+		// Skip properties the access policy does not allow writing
+		if !rule.AllowsProperty(policy.UsageWrite, prop.Name) {
+			continue
+		}
 
 		properties[prop.Name] = map[string]interface{}{
 			"type":        b.getJSONSchemaType(prop.Type),
@@ -721,6 +865,8 @@ func (b *ODataMCPBridge) generateCreateTool(entitySetName string, entitySet *mod
 		}
 	}
 
+	properties["dry_run"] = dryRunInputProperty()
+
 	inputSchema := map[string]interface{}{
 		"type":       "object",
 		"properties": properties,
@@ -777,8 +923,9 @@ func (b *ODataMCPBridge) generateUpdateTool(entitySetName string, entitySet *mod
 	}
 
 	// Add updatable properties (optional)
+	rule := b.policy.EntitySet(entitySetName)
 	for _, prop := range entityType.Properties {
-		if !prop.IsKey {
+		if !prop.IsKey && rule.AllowsProperty(policy.UsageWrite, prop.Name) {
 			properties[prop.Name] = map[string]interface{}{
 				"type":        b.getJSONSchemaType(prop.Type),
 				"description": fmt.Sprintf("Property: %s", prop.Name),
@@ -793,6 +940,8 @@ func (b *ODataMCPBridge) generateUpdateTool(entitySetName string, entitySet *mod
 		"enum":        []string{"PUT", "PATCH", "MERGE"},
 		"default":     "PUT",
 	}
+	properties["dry_run"] = dryRunInputProperty()
+	description = b.applyConfirmPolicy(constants.ConfirmUpdate, description, properties)
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -824,7 +973,7 @@ func (b *ODataMCPBridge) generateDeleteTool(entitySetName string, entitySet *mod
 	opName := constants.GetToolOperationName(constants.OpDelete, b.config.ToolShrink)
 	toolName := b.formatToolName(opName, entitySetName)
 
-	description := fmt.Sprintf("Delete a %s entity", entitySetName)
+	description := fmt.Sprintf("Delete a %s entity", entitySetName) + b.policyToolNote(entitySetName)
 
 	// Build key properties for input schema
 	properties := make(map[string]interface{})
@@ -842,6 +991,8 @@ func (b *ODataMCPBridge) generateDeleteTool(entitySetName string, entitySet *mod
 			}
 		}
 	}
+	properties["dry_run"] = dryRunInputProperty()
+	description = b.applyConfirmPolicy(constants.ConfirmDelete, description, properties)
 
 	tool := &mcp.Tool{
 		Name:        toolName,
@@ -890,6 +1041,10 @@ func (b *ODataMCPBridge) generateFunctionTool(functionName string, function *mod
 			}
 		}
 	}
+	properties["dry_run"] = dryRunInputProperty()
+	if b.isFunctionModifying(function) {
+		description = b.applyConfirmPolicy(constants.ConfirmFunction, description, properties)
+	}
 
 	inputSchema := map[string]interface{}{
 		"type":       "object",
@@ -1011,6 +1166,12 @@ func (b *ODataMCPBridge) Stop() {
 	b.running = false
 	close(b.stopChan)
 	b.server.Stop()
+	if b.audit != nil {
+		b.audit.Close()
+	}
+	if b.journal != nil {
+		b.journal.Close()
+	}
 }
 
 // GetTraceInfo returns comprehensive trace information
@@ -1079,6 +1240,7 @@ func (b *ODataMCPBridge) GetTraceInfo() (*models.TraceInfo, error) {
 		OperationFilter: operationFilter,
 		Authentication:  authType,
 		ReadOnlyMode:    readOnlyMode,
+		AccessPolicy:    b.policyTraceInfo(),
 		MetadataSummary: models.MetadataSummary{
 			EntityTypes:     len(b.metadata.EntityTypes),
 			EntitySets:      len(b.metadata.EntitySets),
@@ -1120,6 +1282,24 @@ func (b *ODataMCPBridge) handleServiceInfo(ctx context.Context, args map[string]
 		info["entity_sets_detail"] = b.metadata.EntitySets
 		info["entity_types_detail"] = b.metadata.EntityTypes
 		info["function_imports_detail"] = b.metadata.FunctionImports
+
+		// Entity sets and functions hidden by the access policy are not described either
+		if b.policy != nil {
+			entitySets := make(map[string]*models.EntitySet)
+			for name, es := range b.metadata.EntitySets {
+				if b.policy.AllowsEntitySet(name) {
+					entitySets[name] = es
+				}
+			}
+			functions := make(map[string]*models.FunctionImport)
+			for name, fn := range b.metadata.FunctionImports {
+				if b.policy.AllowsFunction(name) {
+					functions[name] = fn
+				}
+			}
+			info["entity_sets_detail"] = entitySets
+			info["function_imports_detail"] = functions
+		}
 	}
 
 	response, err := json.Marshal(info)
@@ -1199,8 +1379,8 @@ func (b *ODataMCPBridge) transformFilterForSAP(filter string, entitySetName stri
 		}
 	}
 
-	if b.config.Verbose && result != filter {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Transformed SAP filter: %s -> %s\n", filter, result)
+	if result != filter {
+		logger.Debug("Transformed SAP filter", "filter", filter, "result", result)
 	}
 
 	return result
@@ -1275,6 +1455,15 @@ func (b *ODataMCPBridge) handleEntityFilter(ctx context.Context, entitySetName s
 		options[constants.QueryInlineCount] = "allpages"
 	}
 
+	if err := b.applyReadPolicy(entitySetName, constants.OpFilter, options); err != nil {
+		return nil, err
+	}
+
+	// Summarize mode returns column statistics over all pages instead of rows
+	if summarize, ok := mappedArgs["summarize"].(bool); ok && summarize {
+		return b.summarizeEntities(ctx, entitySetName, options, mappedArgs)
+	}
+
 	// Call OData client to get entity set
 	response, err := b.client.GetEntitySet(ctx, entitySetName, options)
 	if err != nil {
@@ -1507,6 +1696,10 @@ func (b *ODataMCPBridge) handleEntityCount(ctx context.Context, entitySetName st
 		options[constants.QueryFilter] = filter
 	}
 
+	if err := b.applyReadPolicy(entitySetName, constants.OpCount, options); err != nil {
+		return nil, err
+	}
+
 	// Add $inlinecount=allpages to get inline count (OData v2 syntax)
 	options[constants.QueryInlineCount] = "allpages"
 	options[constants.QueryTop] = "0" // We only want the count, not the data
@@ -1559,6 +1752,10 @@ func (b *ODataMCPBridge) handleEntitySearch(ctx context.Context, entitySetName s
 		options[constants.QuerySelect] = selectParam
 	}
 
+	if err := b.applyReadPolicy(entitySetName, constants.OpSearch, options); err != nil {
+		return nil, err
+	}
+
 	// Call OData client to search entities
 	response, err := b.client.GetEntitySet(ctx, entitySetName, options)
 	if err != nil {
@@ -1601,10 +1798,9 @@ func (b *ODataMCPBridge) handleEntityGet(ctx context.Context, entitySetName stri
 		options[constants.QueryExpand] = expand
 	}
 
-	// Call OData client to get entity
-	response, err := b.client.GetEntity(ctx, entitySetName, key, options)
+	response, err := b.getEntity(ctx, entitySetName, key, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get entity: %w", err)
+		return nil, err
 	}
 
 	// Format response as JSON string
@@ -1616,7 +1812,44 @@ func (b *ODataMCPBridge) handleEntityGet(ctx context.Context, entitySetName stri
 	return string(result), nil
 }
 
+// getEntity reads one entity by key. Mandatory policy filters apply to single reads too,
+// so those go through a filter query.
+func (b *ODataMCPBridge) getEntity(ctx context.Context, entitySetName string, key map[string]interface{}, options map[string]string) (*models.ODataResponse, error) {
+	if b.policy.EntitySet(entitySetName).MandatoryFilter() != "" {
+		response, err := b.getEntityInScope(ctx, entitySetName, key, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity: %w", err)
+		}
+		return response, nil
+	}
+
+	if err := b.applyReadPolicy(entitySetName, constants.OpGet, options); err != nil {
+		return nil, err
+	}
+	response, err := b.client.GetEntity(ctx, entitySetName, key, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	return response, nil
+}
+
+// prepareEntityPayload applies the conversions every create/update body goes through
+func (b *ODataMCPBridge) prepareEntityPayload(data map[string]interface{}) map[string]interface{} {
+	// Convert numeric fields to strings for SAP OData v2 compatibility
+	// This prevents "Failed to read property 'Quantity' at offset" errors
+	data = utils.ConvertNumericsInMap(data)
+
+	// Convert date fields to OData legacy format if needed
+	if b.config.LegacyDates {
+		data = utils.ConvertDatesInMap(data, false) // false = convert ISO to legacy
+	}
+
+	return data
+}
+
 func (b *ODataMCPBridge) handleEntityCreate(ctx context.Context, entitySetName string, args map[string]interface{}) (interface{}, error) {
+	ctx = b.withCallOptions(ctx, args)
+
 	// All arguments are the entity data (excluding system parameters)
 	entityData := make(map[string]interface{})
 	for k, v := range args {
@@ -1626,20 +1859,25 @@ func (b *ODataMCPBridge) handleEntityCreate(ctx context.Context, entitySetName s
 		}
 	}
 
-	// Convert numeric fields to strings for SAP OData v2 compatibility
-	// This prevents "Failed to read property 'Quantity' at offset" errors
-	entityData = utils.ConvertNumericsInMap(entityData)
-
-	// Convert date fields to OData legacy format if needed
-	if b.config.LegacyDates {
-		entityData = utils.ConvertDatesInMap(entityData, false) // false = convert ISO to legacy
+	if err := b.checkWritePolicy(entitySetName, constants.OpCreate, entityData); err != nil {
+		return nil, err
 	}
 
+	entityData = b.prepareEntityPayload(entityData)
+
 	// Call OData client to create entity
 	response, err := b.client.CreateEntity(ctx, entitySetName, entityData)
+	if errors.Is(err, client.ErrDryRun) {
+		return b.dryRunResult(ctx, constants.OpCreate)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create entity: %w", err)
 	}
+	if b.journaling(ctx) {
+		b.journalCreate(ctx, entitySetName, response)
+	}
+
+	b.readableWriteResponse(entitySetName, response)
 
 	// Enhance response (includes date conversion if enabled)
 	response = b.enhanceResponse(response, make(map[string]string))
@@ -1653,7 +1891,19 @@ func (b *ODataMCPBridge) handleEntityCreate(ctx context.Context, entitySetName s
 	return string(result), nil
 }
 
+// updateMethod validates the HTTP method requested for an update. Other methods are
+// rejected, since they would bypass the checks of their own operation.
+func updateMethod(method string) (string, error) {
+	switch m := strings.ToUpper(method); m {
+	case constants.PUT, constants.PATCH, constants.MERGE:
+		return m, nil
+	}
+	return "", fmt.Errorf("unsupported update method '%s' (supported: PUT, PATCH, MERGE)", method)
+}
+
 func (b *ODataMCPBridge) handleEntityUpdate(ctx context.Context, entitySetName string, entityType *models.EntityType, args map[string]interface{}) (interface{}, error) {
+	ctx = b.withCallOptions(ctx, args)
+
 	// Extract key values and method
 	key := make(map[string]interface{})
 	updateData := make(map[string]interface{})
@@ -1661,8 +1911,11 @@ func (b *ODataMCPBridge) handleEntityUpdate(ctx context.Context, entitySetName s
 
 	for k, v := range args {
 		if k == "_method" {
-			if m, ok := v.(string); ok {
-				method = m
+			if m, ok := v.(string); ok && m != "" {
+				var err error
+				if method, err = updateMethod(m); err != nil {
+					return nil, err
+				}
 			}
 			continue
 		}
@@ -1690,20 +1943,44 @@ func (b *ODataMCPBridge) handleEntityUpdate(ctx context.Context, entitySetName s
 		}
 	}
 
-	// Convert numeric fields to strings for SAP OData v2 compatibility
-	// This prevents "Failed to read property 'Quantity' at offset" errors
-	updateData = utils.ConvertNumericsInMap(updateData)
+	if err := b.checkWritePolicy(entitySetName, constants.OpUpdate, updateData); err != nil {
+		return nil, err
+	}
+	if err := b.checkPolicyScope(ctx, entitySetName, key); err != nil {
+		return nil, err
+	}
 
-	// Convert date fields to OData legacy format if needed
-	if b.config.LegacyDates {
-		updateData = utils.ConvertDatesInMap(updateData, false) // false = convert ISO to legacy
+	updateData = b.prepareEntityPayload(updateData)
+
+	if b.needsConfirmation(ctx, constants.ConfirmUpdate) {
+		if pending, err := b.confirmWrite(ctx, b.describeUpdate(ctx, entitySetName, key, updateData, method)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
+	// Snapshot the entity so the change can be undone
+	var before map[string]interface{}
+	var beforeETag string
+	if b.journaling(ctx) {
+		var err error
+		if before, beforeETag, err = b.snapshotEntity(ctx, entitySetName, key); err != nil {
+			return nil, fmt.Errorf("failed to read entity for the change journal: %w", err)
+		}
 	}
 
 	// Call OData client to update entity
 	response, err := b.client.UpdateEntity(ctx, entitySetName, key, updateData, method)
+	if errors.Is(err, client.ErrDryRun) {
+		return b.dryRunResult(ctx, constants.OpUpdate)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update entity: %w", err)
 	}
+	if b.journaling(ctx) {
+		b.journalUpdate(ctx, entitySetName, key, before, beforeETag, updateData)
+	}
+
+	b.readableWriteResponse(entitySetName, response)
 
 	// Enhance response (includes date conversion if enabled)
 	response = b.enhanceResponse(response, make(map[string]string))
@@ -1718,6 +1995,8 @@ func (b *ODataMCPBridge) handleEntityUpdate(ctx context.Context, entitySetName s
 }
 
 func (b *ODataMCPBridge) handleEntityDelete(ctx context.Context, entitySetName string, entityType *models.EntityType, args map[string]interface{}) (interface{}, error) {
+	ctx = b.withCallOptions(ctx, args)
+
 	// Build key values from arguments
 	key := make(map[string]interface{})
 	for _, keyProp := range entityType.KeyProperties {
@@ -1728,17 +2007,58 @@ func (b *ODataMCPBridge) handleEntityDelete(ctx context.Context, entitySetName s
 		}
 	}
 
+	if err := b.checkPolicyOperation(entitySetName, constants.OpDelete); err != nil {
+		return nil, err
+	}
+	if err := b.checkPolicyScope(ctx, entitySetName, key); err != nil {
+		return nil, err
+	}
+
+	if b.needsConfirmation(ctx, constants.ConfirmDelete) {
+		if pending, err := b.confirmWrite(ctx, b.describeDelete(ctx, entitySetName, key)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
+	// Snapshot the entity so it can be re-created
+	var before map[string]interface{}
+	var beforeETag string
+	if b.journaling(ctx) {
+		var err error
+		if before, beforeETag, err = b.snapshotEntity(ctx, entitySetName, key); err != nil {
+			return nil, fmt.Errorf("failed to read entity for the change journal: %w", err)
+		}
+	}
+
 	// Call OData client to delete entity
 	_, err := b.client.DeleteEntity(ctx, entitySetName, key)
+	if errors.Is(err, client.ErrDryRun) {
+		return b.dryRunResult(ctx, constants.OpDelete)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete entity: %w", err)
 	}
+	if b.journaling(ctx) {
+		b.recordChange(ctx, &journal.Change{
+			Operation:  constants.OpDelete,
+			EntitySet:  entitySetName,
+			Key:        key,
+			Before:     before,
+			BeforeETag: beforeETag,
+		})
+	}
 
 	// For successful deletes, return a simple success message
 	return `{"status": "success", "message": "Entity deleted successfully"}`, nil
 }
 
 func (b *ODataMCPBridge) handleFunctionCall(ctx context.Context, functionName string, function *models.FunctionImport, args map[string]interface{}) (interface{}, error) {
+	ctx = b.withCallOptions(ctx, args)
+
+	if !b.policy.AllowsFunction(functionName) {
+		return nil, fmt.Errorf("function not allowed: %s (restricted by access policy)", functionName)
+	}
+
 	// Build parameters from arguments
 	parameters := make(map[string]interface{})
 	for _, param := range function.Parameters {
@@ -1757,8 +2077,17 @@ func (b *ODataMCPBridge) handleFunctionCall(ctx context.Context, functionName st
 		method = constants.GET
 	}
 
+	if b.isFunctionModifying(function) && b.needsConfirmation(ctx, constants.ConfirmFunction) {
+		if pending, err := b.confirmWrite(ctx, b.describeFunction(functionName, method, parameters)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
 	// Call OData client to execute function
 	response, err := b.client.CallFunction(ctx, functionName, parameters, method)
+	if errors.Is(err, client.ErrDryRun) {
+		return b.dryRunResult(ctx, functionName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to call function: %w", err)
 	}
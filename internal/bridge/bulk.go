@@ -0,0 +1,828 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/client"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/progress"
+	"github.com/zmcp/odata-mcp/internal/utils"
+)
+
+// Bulk row outcomes recorded in the report file
+const (
+	bulkStatusValid      = "valid"       // Dry run: row would be sent
+	bulkStatusInvalid    = "invalid"     // Row failed metadata validation and was not sent
+	bulkStatusSucceeded  = "succeeded"   // Service accepted the row
+	bulkStatusFailed     = "failed"      // Service rejected the row
+	bulkStatusRolledBack = "rolled_back" // Another row in the same changeset failed, so this one was not applied
+	bulkStatusSkipped    = "skipped"     // Not executed (invalid rows present or request cancelled)
+)
+
+// bulkErrorsShown is the number of row errors echoed back in the tool result
+const bulkErrorsShown = 5
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// bulkRow is one validated input row
+type bulkRow struct {
+	Number int
+	Key    map[string]interface{}
+	Data   map[string]interface{}
+	Err    error
+}
+
+// bulkReportEntry is one line of the report file
+type bulkReportEntry struct {
+	Row        int                    `json:"row"`
+	Status     string                 `json:"status"`
+	Key        map[string]interface{} `json:"key,omitempty"`
+	HTTPStatus int                    `json:"http_status,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// bulkOperations returns the bulk operations allowed by the current configuration
+func (b *ODataMCPBridge) bulkOperations() []string {
+	if b.config.ImportDir == "" || b.config.IsReadOnly() {
+		return nil
+	}
+	var ops []string
+	if b.config.IsOperationEnabled('C') {
+		ops = append(ops, constants.OpCreate)
+	}
+	if b.config.IsOperationEnabled('U') {
+		ops = append(ops, constants.OpUpdate)
+	}
+	if b.config.IsOperationEnabled('D') {
+		ops = append(ops, constants.OpDelete)
+	}
+	return ops
+}
+
+// generateBulkTool creates the bulk_import tool used in both eager and lazy mode
+func (b *ODataMCPBridge) generateBulkTool() error {
+	toolName := b.formatToolName("bulk_import", "")
+
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: "Create, update or delete many entities from a CSV or JSONL file in the import directory. Rows are validated against metadata, sent in $batch changesets (or one by one when $batch is unavailable) and every row's outcome is written to a report file",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"entity_set": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the entity set to modify",
+				},
+				"operation": map[string]interface{}{
+					"type":        "string",
+					"description": "Operation applied to every row",
+					"enum":        b.bulkOperations(),
+				},
+				"file_name": map[string]interface{}{
+					"type":        "string",
+					"description": "CSV or JSONL file relative to the import directory",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "File format (default: from the file extension)",
+					"enum":        []string{exportFormatCSV, exportFormatJSONL},
+				},
+				"delimiter": map[string]interface{}{
+					"type":        "string",
+					"description": "CSV field delimiter (default: ',')",
+				},
+				"column_map": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Map of file column to entity property; map a column to an empty string to ignore it. Unmapped columns must match property names",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"method": map[string]interface{}{
+					"type":        "string",
+					"description": "HTTP method for updates (default: MERGE for OData v2, PATCH for v4, so columns not in the file are left unchanged)",
+					"enum":        []string{constants.PUT, constants.PATCH, constants.MERGE},
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Validate every row and write the report without changing any data",
+					"default":     false,
+				},
+				"use_batch": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Send rows in $batch changesets; each changeset succeeds or fails as a whole",
+					"default":     true,
+				},
+				"chunk_size": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Rows per changeset (default: %d, max: %d)", constants.DefaultBulkChunkSize, constants.MaxBulkChunkSize),
+				},
+				"stop_on_invalid": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Send nothing when any row fails validation (set to false to send the valid rows anyway)",
+					"default":     true,
+				},
+			},
+			"required": []string{"entity_set", "operation", "file_name"},
+		},
+	}
+
+	// Bulk creates are never confirmed; updates and deletes follow the --confirm policy
+	for _, op := range b.bulkOperations() {
+		if op != constants.OpCreate && b.config.RequiresConfirmation(op) {
+			tool.Description = b.applyConfirmPolicy(op, tool.Description, tool.InputSchema["properties"].(map[string]interface{}))
+			break
+		}
+	}
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleBulkImport(ctx, args)
+	}
+
+	b.server.AddTool(tool, handler)
+
+	// Track tool info
+	b.tools[toolName] = &models.ToolInfo{
+		Name:        toolName,
+		Description: tool.Description,
+		Operation:   constants.OpBulk,
+	}
+
+	return nil
+}
+
+// handleBulkImport validates and executes a bulk file against an entity set
+func (b *ODataMCPBridge) handleBulkImport(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	entitySetName, ok := args["entity_set"].(string)
+	if !ok || entitySetName == "" {
+		return nil, fmt.Errorf("missing required parameter: entity_set")
+	}
+	operation, ok := args["operation"].(string)
+	if !ok || operation == "" {
+		return nil, fmt.Errorf("missing required parameter: operation")
+	}
+	fileName, ok := args["file_name"].(string)
+	if !ok || fileName == "" {
+		return nil, fmt.Errorf("missing required parameter: file_name")
+	}
+
+	if b.config.ImportDir == "" {
+		return nil, fmt.Errorf("bulk import is disabled: no import directory configured (--import-dir)")
+	}
+	if b.config.IsReadOnly() {
+		return nil, fmt.Errorf("%s operation not allowed in read-only mode", operation)
+	}
+
+	allowed := false
+	for _, op := range b.bulkOperations() {
+		if op == operation {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("bulk %s is not enabled (allowed: %s)", operation, strings.Join(b.bulkOperations(), ", "))
+	}
+
+	es, entityType, err := b.validateEntitySet(entitySetName)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case operation == constants.OpCreate && !es.Creatable:
+		return nil, fmt.Errorf("entity set %s does not support create operations", entitySetName)
+	case operation == constants.OpUpdate && !es.Updatable:
+		return nil, fmt.Errorf("entity set %s is not updatable", entitySetName)
+	case operation == constants.OpDelete && !es.Deletable:
+		return nil, fmt.Errorf("entity set %s does not support delete operations", entitySetName)
+	}
+	if err := b.checkPolicyOperation(entitySetName, operation); err != nil {
+		return nil, err
+	}
+
+	isV4 := strings.HasPrefix(b.metadata.Version, "4")
+
+	method := ""
+	switch operation {
+	case constants.OpCreate:
+		method = constants.POST
+	case constants.OpUpdate:
+		method = constants.MERGE
+		if isV4 {
+			method = constants.PATCH
+		}
+		if m, ok := args["method"].(string); ok && m != "" {
+			if method, err = updateMethod(m); err != nil {
+				return nil, err
+			}
+		}
+	case constants.OpDelete:
+		method = constants.DELETE
+	}
+
+	format, _ := args["format"].(string)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+		if format == "json" || format == "ndjson" {
+			format = exportFormatJSONL
+		}
+	}
+	if format != exportFormatCSV && format != exportFormatJSONL {
+		return nil, fmt.Errorf("unsupported bulk file format '%s' (supported: csv, jsonl)", format)
+	}
+
+	delimiter := ','
+	if d, ok := args["delimiter"].(string); ok && d != "" {
+		if d == "\\t" || d == "tab" {
+			d = "\t"
+		}
+		delimiter = []rune(d)[0]
+	}
+
+	columnMap := make(map[string]string)
+	if raw, ok := args["column_map"].(map[string]interface{}); ok {
+		for column, target := range raw {
+			name, ok := target.(string)
+			if !ok {
+				return nil, fmt.Errorf("column_map values must be property names")
+			}
+			columnMap[column] = name
+		}
+	}
+
+	dryRun, _ := args["dry_run"].(bool)
+	dryRun = dryRun || b.config.DryRun
+	if token, ok := args["confirm_token"].(string); ok && token != "" {
+		ctx = context.WithValue(ctx, confirmTokenKey{}, token)
+	}
+	useBatch := true
+	if v, ok := args["use_batch"].(bool); ok {
+		useBatch = v
+	}
+	stopOnInvalid := true
+	if v, ok := args["stop_on_invalid"].(bool); ok {
+		stopOnInvalid = v
+	}
+
+	chunkSize := b.config.BulkChunkSize
+	if n, ok := args["chunk_size"].(float64); ok && n > 0 {
+		chunkSize = int(n)
+	}
+	if chunkSize <= 0 {
+		chunkSize = constants.DefaultBulkChunkSize
+	}
+	if chunkSize > constants.MaxBulkChunkSize {
+		chunkSize = constants.MaxBulkChunkSize
+	}
+
+	path, err := resolvePathInDir(b.config.ImportDir, fileName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := readBulkFile(path, format, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no rows found in %s", fileName)
+	}
+
+	// Validate every row before anything is sent
+	rows := make([]*bulkRow, len(records))
+	report := make([]bulkReportEntry, len(records))
+	invalid := 0
+	for i, record := range records {
+		rows[i] = b.buildBulkRow(operation, entityType, record, columnMap, isV4)
+		rows[i].Number = i + 1
+		if rows[i].Err == nil {
+			rows[i].Err = b.checkBulkRowPolicy(ctx, entitySetName, operation, rows[i])
+		}
+		report[i] = bulkReportEntry{Row: i + 1, Key: rows[i].Key}
+		if rows[i].Err != nil {
+			report[i].Status = bulkStatusInvalid
+			report[i].Error = rows[i].Err.Error()
+			invalid++
+		}
+	}
+
+	// Bulk updates and deletes follow the same --confirm policy as single-entity writes
+	if !dryRun && operation != constants.OpCreate && (invalid == 0 || !stopOnInvalid) && b.config.RequiresConfirmation(operation) {
+		if pending, err := b.confirmWrite(ctx, describeBulk(entitySetName, operation, method, fileName, rows)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
+	mode := "batch"
+	if !useBatch {
+		mode = "sequential"
+	}
+	var note string
+
+	switch {
+	case dryRun:
+		mode = "dry_run"
+		for i := range report {
+			if report[i].Status == "" {
+				report[i].Status = bulkStatusValid
+			}
+		}
+	case invalid > 0 && stopOnInvalid:
+		mode = "not_executed"
+		note = fmt.Sprintf("%d of %d rows failed validation; nothing was sent. Fix the rows or set stop_on_invalid to false to send the valid rows.", invalid, len(rows))
+		for i := range report {
+			if report[i].Status == "" {
+				report[i].Status = bulkStatusSkipped
+			}
+		}
+	default:
+		mode, note = b.executeBulkRows(ctx, entitySetName, method, rows, report, useBatch, chunkSize)
+	}
+
+	reportPath, err := b.writeBulkReport(fileName, operation, report)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	var rowErrors []bulkReportEntry
+	for _, entry := range report {
+		counts[entry.Status]++
+		if entry.Error != "" && len(rowErrors) < bulkErrorsShown {
+			rowErrors = append(rowErrors, entry)
+		}
+	}
+
+	result := map[string]interface{}{
+		"entity_set":  entitySetName,
+		"operation":   operation,
+		"mode":        mode,
+		"dry_run":     dryRun,
+		"rows":        len(rows),
+		"counts":      counts,
+		"report_file": reportPath,
+	}
+	if operation == constants.OpUpdate {
+		result["method"] = method
+	}
+	if len(rowErrors) > 0 {
+		result["errors"] = rowErrors
+	}
+	if note != "" {
+		result["note"] = note
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(response), nil
+}
+
+// describeBulk summarizes a bulk update or delete; the token covers exactly the valid rows read now
+func describeBulk(entitySetName, operation, method, fileName string, rows []*bulkRow) *confirmation {
+	var valid []interface{}
+	for _, row := range rows {
+		if row.Err == nil {
+			valid = append(valid, []interface{}{row.Key, row.Data})
+		}
+	}
+
+	c := &confirmation{
+		Operation: operation,
+		Target:    entitySetName,
+		Parameters: map[string]interface{}{
+			"file_name": fileName,
+			"rows":      len(valid),
+		},
+		Note:    fmt.Sprintf("Bulk %s of %d entities from %s.", operation, len(valid), fileName),
+		payload: valid,
+	}
+	if operation == constants.OpUpdate {
+		c.Method = method
+	}
+	return c
+}
+
+// executeBulkRows sends the valid rows in chunks and records each outcome in the report.
+// Every finished chunk is reported as progress of the request.
+func (b *ODataMCPBridge) executeBulkRows(ctx context.Context, entitySetName, method string, rows []*bulkRow, report []bulkReportEntry, useBatch bool, chunkSize int) (string, string) {
+	var valid []*bulkRow
+	for _, row := range rows {
+		if row.Err == nil {
+			valid = append(valid, row)
+		}
+	}
+
+	mode := "batch"
+	if !useBatch {
+		mode = "sequential"
+	}
+	note := ""
+
+	for start := 0; start < len(valid); start += chunkSize {
+		end := start + chunkSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		chunk := valid[start:end]
+		if start > 0 {
+			progress.Report(ctx, float64(start), float64(len(valid)), fmt.Sprintf("Sent %d of %d rows", start, len(valid)))
+		}
+
+		if ctx.Err() != nil {
+			for _, row := range valid[start:] {
+				report[row.Number-1].Status = bulkStatusSkipped
+				report[row.Number-1].Error = ctx.Err().Error()
+			}
+			break
+		}
+
+		if useBatch {
+			ops := make([]client.BatchOperation, len(chunk))
+			for i, row := range chunk {
+				ops[i] = client.BatchOperation{Method: method, EntitySet: entitySetName, Key: row.Key, Data: row.Data}
+			}
+
+			results, err := b.client.ExecuteChangeset(ctx, ops)
+			if errors.Is(err, client.ErrBatchNotSupported) {
+				// Fall back for this and every following chunk
+				useBatch = false
+				mode = "sequential"
+				note = "The service does not support $batch; rows were sent one by one."
+				logger.InfoContext(ctx, "Falling back to sequential requests", "error", err)
+			} else if err != nil {
+				for _, row := range chunk {
+					report[row.Number-1].Status = bulkStatusFailed
+					report[row.Number-1].Error = err.Error()
+				}
+				continue
+			} else {
+				for i, row := range chunk {
+					entry := &report[row.Number-1]
+					entry.HTTPStatus = results[i].StatusCode
+					switch {
+					case results[i].Err == nil:
+						entry.Status = bulkStatusSucceeded
+					case results[i].RolledBack:
+						entry.Status = bulkStatusRolledBack
+						entry.Error = results[i].Err.Error()
+					default:
+						entry.Status = bulkStatusFailed
+						entry.Error = results[i].Err.Error()
+					}
+				}
+				continue
+			}
+		}
+
+		for _, row := range chunk {
+			entry := &report[row.Number-1]
+			var err error
+			switch method {
+			case constants.POST:
+				_, err = b.client.CreateEntity(ctx, entitySetName, row.Data)
+			case constants.DELETE:
+				_, err = b.client.DeleteEntity(ctx, entitySetName, row.Key)
+			default:
+				_, err = b.client.UpdateEntity(ctx, entitySetName, row.Key, row.Data, method)
+			}
+			if err != nil {
+				entry.Status = bulkStatusFailed
+				entry.Error = err.Error()
+			} else {
+				entry.Status = bulkStatusSucceeded
+			}
+		}
+	}
+	if len(valid) > 0 && ctx.Err() == nil {
+		progress.Report(ctx, float64(len(valid)), float64(len(valid)), fmt.Sprintf("Sent %d of %d rows", len(valid), len(valid)))
+	}
+
+	return mode, note
+}
+
+// checkBulkRowPolicy applies the access policy to one validated row: written
+// properties must be writable, and updated or deleted rows must be in scope
+func (b *ODataMCPBridge) checkBulkRowPolicy(ctx context.Context, entitySetName, operation string, row *bulkRow) error {
+	if b.policy == nil {
+		return nil
+	}
+	if operation != constants.OpDelete {
+		if err := b.checkWritePolicy(entitySetName, operation, row.Data); err != nil {
+			return err
+		}
+	}
+	if operation != constants.OpCreate {
+		return b.checkPolicyScope(ctx, entitySetName, row.Key)
+	}
+	return nil
+}
+
+// buildBulkRow maps a raw record to key and body values, validating types against metadata
+func (b *ODataMCPBridge) buildBulkRow(operation string, entityType *models.EntityType, record map[string]interface{}, columnMap map[string]string, isV4 bool) *bulkRow {
+	row := &bulkRow{Key: make(map[string]interface{}), Data: make(map[string]interface{})}
+
+	props := make(map[string]*models.EntityProperty, len(entityType.Properties))
+	for _, prop := range entityType.Properties {
+		props[prop.Name] = prop
+	}
+
+	var problems []string
+	for column, raw := range record {
+		target := column
+		if mapped, ok := columnMap[column]; ok {
+			if mapped == "" {
+				continue
+			}
+			target = mapped
+		}
+
+		prop, exists := props[target]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("unknown property %s (column %s)", target, column))
+			continue
+		}
+
+		value, err := b.convertBulkValue(raw, prop, isV4)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+
+		if prop.IsKey {
+			row.Key[target] = value
+			if operation == constants.OpCreate {
+				row.Data[target] = value
+			}
+		} else if operation != constants.OpDelete {
+			row.Data[target] = value
+		}
+	}
+
+	if operation != constants.OpCreate {
+		for _, keyProp := range entityType.KeyProperties {
+			if _, exists := row.Key[keyProp]; !exists {
+				problems = append(problems, fmt.Sprintf("missing required key property: %s", keyProp))
+			}
+		}
+	}
+	if operation == constants.OpUpdate && len(row.Data) == 0 && len(problems) == 0 {
+		problems = append(problems, "no properties to update")
+	}
+
+	if len(problems) > 0 {
+		row.Err = errors.New(strings.Join(problems, "; "))
+		return row
+	}
+
+	switch operation {
+	case constants.OpDelete:
+		row.Data = nil
+	default:
+		row.Data = b.prepareEntityPayload(row.Data)
+	}
+	if len(row.Key) == 0 {
+		row.Key = nil
+	}
+
+	return row
+}
+
+// convertBulkValue converts a file value to the JSON representation the service expects for the property type
+func (b *ODataMCPBridge) convertBulkValue(raw interface{}, prop *models.EntityProperty, isV4 bool) (interface{}, error) {
+	if raw == nil {
+		if !prop.Nullable {
+			return nil, fmt.Errorf("value required (not nullable)")
+		}
+		return nil, nil
+	}
+
+	var text string
+	switch v := raw.(type) {
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		return nil, fmt.Errorf("unsupported value %v", raw)
+	}
+
+	switch prop.Type {
+	case "Edm.String":
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+		return text, nil
+
+	case "Edm.Int16", "Edm.Int32", "Edm.Byte", "Edm.SByte":
+		bits := map[string]int{"Edm.Int16": 16, "Edm.Int32": 32, "Edm.Byte": 9, "Edm.SByte": 8}[prop.Type]
+		n, err := strconv.ParseInt(text, 10, bits)
+		if err != nil || (prop.Type == "Edm.Byte" && (n < 0 || n > math.MaxUint8)) {
+			return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+		}
+		return n, nil
+
+	case "Edm.Int64":
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+		}
+		if isV4 {
+			return n, nil
+		}
+		// OData v2 JSON represents Edm.Int64 as a string
+		return strconv.FormatInt(n, 10), nil
+
+	case "Edm.Decimal":
+		if _, err := utils.ParseDecimalString(text); err != nil || strings.ContainsAny(text, "eE") {
+			return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+		}
+		if isV4 {
+			return json.Number(text), nil
+		}
+		// OData v2 JSON represents Edm.Decimal as a string; keep every digit
+		return text, nil
+
+	case "Edm.Single", "Edm.Double":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+		}
+		return f, nil
+
+	case "Edm.Boolean":
+		switch strings.ToLower(text) {
+		case "true", "1", "x", "yes":
+			return true, nil
+		case "false", "0", "no":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+
+	case "Edm.DateTime", "Edm.DateTimeOffset", "Edm.Date":
+		t, err := parseBulkDate(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s (use ISO 8601, e.g. 2024-01-31 or 2024-01-31T12:00:00Z)", text, prop.Type)
+		}
+		return utils.FormatDateForOData(t, prop.Type, !isV4 && b.config.LegacyDates), nil
+
+	case "Edm.Guid":
+		if !guidPattern.MatchString(text) {
+			return nil, fmt.Errorf("%q is not a valid %s", text, prop.Type)
+		}
+		return text, nil
+	}
+
+	return raw, nil
+}
+
+// parseBulkDate accepts ISO 8601 dates and timestamps as well as OData legacy /Date(...)/ values
+func parseBulkDate(text string) (time.Time, error) {
+	if ms, _, ok := utils.ParseODataLegacyDate(text); ok {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date")
+}
+
+// readBulkFile reads all records of a CSV (header row required) or JSONL file.
+// Empty CSV cells are treated as absent so they never overwrite existing values.
+func readBulkFile(path, format string, delimiter rune) ([]map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bulk file: %w", err)
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+
+	if format == exportFormatJSONL {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var record map[string]interface{}
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return nil, fmt.Errorf("invalid JSON on line %d: %w", line, err)
+			}
+			records = append(records, record)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read bulk file: %w", err)
+		}
+		return records, nil
+	}
+
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+		if len(fields) > len(header) {
+			return nil, fmt.Errorf("CSV line %d has %d fields but the header has %d", line, len(fields), len(header))
+		}
+		record := make(map[string]interface{}, len(fields))
+		for i, field := range fields {
+			if field != "" && header[i] != "" {
+				record[header[i]] = field
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// writeBulkReport writes one JSON line per row next to the input file
+func (b *ODataMCPBridge) writeBulkReport(fileName, operation string, report []bulkReportEntry) (string, error) {
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	stamp := time.Now().UTC().Format("20060102T150405.000Z")
+
+	var path string
+	var file *os.File
+	for attempt := 0; ; attempt++ {
+		reportName := fmt.Sprintf("%s.%s-report-%s.jsonl", base, operation, stamp)
+		if attempt > 0 {
+			// Two imports of the same file within one millisecond
+			reportName = fmt.Sprintf("%s.%s-report-%s-%d.jsonl", base, operation, stamp, attempt)
+		}
+
+		var err error
+		path, err = resolvePathInDir(b.config.ImportDir, reportName, true)
+		if err != nil {
+			return "", err
+		}
+
+		file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) || attempt >= 100 {
+			return "", fmt.Errorf("failed to create report file: %w", err)
+		}
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range report {
+		if err := encoder.Encode(entry); err != nil {
+			return "", fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write report file: %w", err)
+	}
+
+	return path, nil
+}
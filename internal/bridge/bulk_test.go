@@ -0,0 +1,254 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+)
+
+// bulkTestHandler records modifying requests; with batch=false the $batch endpoint answers 404
+func bulkTestHandler(batch bool, requests *[]string) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		if strings.HasSuffix(r.URL.Path, "/$batch") {
+			if !batch {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			mu.Lock()
+			*requests = append(*requests, "$batch:"+string(body))
+			mu.Unlock()
+
+			ops := strings.Count(string(body), "Content-ID:")
+			w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresp")
+			fmt.Fprint(w, "--batchresp\r\nContent-Type: multipart/mixed; boundary=csresp\r\n\r\n")
+			for i := 0; i < ops; i++ {
+				fmt.Fprint(w, "--csresp\r\nContent-Type: application/http\r\n\r\nHTTP/1.1 204 No Content\r\n\r\n\r\n")
+			}
+			fmt.Fprint(w, "--csresp--\r\n--batchresp--\r\n")
+			return
+		}
+
+		mu.Lock()
+		*requests = append(*requests, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"d":{}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type bulkResult struct {
+	Mode       string            `json:"mode"`
+	Counts     map[string]int    `json:"counts"`
+	ReportFile string            `json:"report_file"`
+	Errors     []bulkReportEntry `json:"errors"`
+	Note       string            `json:"note"`
+}
+
+func runBulkImport(t *testing.T, bridge *ODataMCPBridge, args map[string]interface{}) (bulkResult, []bulkReportEntry) {
+	t.Helper()
+	result, err := bridge.handleBulkImport(context.Background(), args)
+	if err != nil {
+		t.Fatalf("handleBulkImport() error = %v", err)
+	}
+	var out bulkResult
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+
+	file, err := os.Open(out.ReportFile)
+	if err != nil {
+		t.Fatalf("failed to open report: %v", err)
+	}
+	defer file.Close()
+	var report []bulkReportEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry bulkReportEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid report line: %v", err)
+		}
+		report = append(report, entry)
+	}
+	return out, report
+}
+
+func newBulkTestBridge(t *testing.T, batch bool, requests *[]string) (*ODataMCPBridge, string) {
+	t.Helper()
+	dir := t.TempDir()
+	bridge := newServiceTestBridge(t, &config.Config{ImportDir: dir, BulkChunkSize: 2}, bulkTestHandler(batch, requests))
+	return bridge, dir
+}
+
+func TestBulkImportCSVBatch(t *testing.T) {
+	var requests []string
+	bridge, dir := newBulkTestBridge(t, true, &requests)
+
+	csv := "\ufeffID;Name;Price;Comment\n1;Chai;18.50;first\n2;Chang;;second\n3;Tofu;2.5;\n"
+	os.WriteFile(filepath.Join(dir, "products.csv"), []byte(csv), 0o644)
+
+	out, report := runBulkImport(t, bridge, map[string]interface{}{
+		"entity_set": "Products",
+		"operation":  "create",
+		"file_name":  "products.csv",
+		"delimiter":  ";",
+		"column_map": map[string]interface{}{"ID": "ProductID", "Name": "ProductName", "Comment": ""},
+	})
+
+	if out.Mode != "batch" || out.Counts[bulkStatusSucceeded] != 3 || len(report) != 3 {
+		t.Fatalf("unexpected result: %+v, report %+v", out, report)
+	}
+	// Chunk size 2: two changesets
+	if len(requests) != 2 {
+		t.Fatalf("got %d $batch requests, want 2", len(requests))
+	}
+	if !strings.Contains(requests[0], `{"Price":"18.50","ProductID":1,"ProductName":"Chai"}`) {
+		t.Errorf("unexpected changeset body:\n%s", requests[0])
+	}
+	if strings.Contains(requests[0], "Comment") || strings.Contains(requests[0], `"ProductName":"Chang","Price"`) {
+		t.Errorf("ignored columns and empty cells must not be sent:\n%s", requests[0])
+	}
+}
+
+func TestBulkImportValidation(t *testing.T) {
+	var requests []string
+	bridge, dir := newBulkTestBridge(t, true, &requests)
+
+	jsonl := `{"ProductID": 1, "Price": "12.00"}
+{"ProductID": "abc", "Price": "1"}
+
+{"Price": "3.00"}
+{"ProductID": 4, "Color": "red"}
+`
+	os.WriteFile(filepath.Join(dir, "updates.jsonl"), []byte(jsonl), 0o644)
+
+	args := map[string]interface{}{
+		"entity_set": "Products",
+		"operation":  "update",
+		"file_name":  "updates.jsonl",
+	}
+
+	// Invalid rows stop the whole import by default
+	out, report := runBulkImport(t, bridge, args)
+	if out.Mode != "not_executed" || out.Counts[bulkStatusInvalid] != 3 || out.Counts[bulkStatusSkipped] != 1 || len(requests) != 0 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if report[2].Status != bulkStatusInvalid || !strings.Contains(report[2].Error, "missing required key property: ProductID") {
+		t.Errorf("unexpected report entry: %+v", report[2])
+	}
+	if !strings.Contains(report[3].Error, "unknown property Color") {
+		t.Errorf("unexpected report entry: %+v", report[3])
+	}
+
+	// Dry run validates without sending
+	args["dry_run"] = true
+	out, _ = runBulkImport(t, bridge, args)
+	if out.Mode != "dry_run" || out.Counts[bulkStatusValid] != 1 || len(requests) != 0 {
+		t.Errorf("unexpected dry run result: %+v", out)
+	}
+
+	// Sending the valid rows anyway
+	delete(args, "dry_run")
+	args["stop_on_invalid"] = false
+	out, _ = runBulkImport(t, bridge, args)
+	if out.Counts[bulkStatusSucceeded] != 1 || len(requests) != 1 || !strings.Contains(requests[0], "MERGE Products(1) HTTP/1.1") {
+		t.Errorf("unexpected result: %+v, requests %v", out, requests)
+	}
+}
+
+func TestBulkImportSequentialFallback(t *testing.T) {
+	var requests []string
+	bridge, dir := newBulkTestBridge(t, false, &requests)
+
+	os.WriteFile(filepath.Join(dir, "delete.csv"), []byte("ProductID\n7\n8\n9\n"), 0o644)
+
+	out, report := runBulkImport(t, bridge, map[string]interface{}{
+		"entity_set": "Products",
+		"operation":  "delete",
+		"file_name":  "delete.csv",
+	})
+
+	if out.Mode != "sequential" || out.Note == "" || out.Counts[bulkStatusSucceeded] != 3 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if len(requests) != 3 || !strings.HasPrefix(requests[0], "DELETE /Products(7)") {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+	if report[2].Key["ProductID"] != 9.0 {
+		t.Errorf("report should record the row key, got %+v", report[2])
+	}
+}
+
+func TestBulkImportRejectsUnsupportedOperations(t *testing.T) {
+	var requests []string
+	bridge, dir := newBulkTestBridge(t, true, &requests)
+	os.WriteFile(filepath.Join(dir, "categories.csv"), []byte("CategoryID\n1\n"), 0o644)
+
+	if _, err := bridge.handleBulkImport(context.Background(), map[string]interface{}{
+		"entity_set": "Categories",
+		"operation":  "delete",
+		"file_name":  "categories.csv",
+	}); err == nil {
+		t.Error("expected an error for a non-deletable entity set")
+	}
+
+	if _, err := bridge.handleBulkImport(context.Background(), map[string]interface{}{
+		"entity_set": "Products",
+		"operation":  "delete",
+		"file_name":  "../categories.csv",
+	}); err == nil {
+		t.Error("expected an error for a file outside the import directory")
+	}
+
+	// An update must not turn into a delete or create behind the checks of those operations
+	os.WriteFile(filepath.Join(dir, "products.csv"), []byte("ProductID,ProductName\n1,Chai\n"), 0o644)
+	for _, method := range []string{"DELETE", "post", "GET"} {
+		if _, err := bridge.handleBulkImport(context.Background(), map[string]interface{}{
+			"entity_set": "Products",
+			"operation":  "update",
+			"method":     method,
+			"file_name":  "products.csv",
+		}); err == nil || !strings.Contains(err.Error(), "unsupported update method") {
+			t.Errorf("bulk update with method %s: error = %v", method, err)
+		}
+		if _, err := bridge.handleEntityUpdate(context.Background(), "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{
+			"ProductID":   1,
+			"ProductName": "Chai",
+			"_method":     method,
+		}); err == nil || !strings.Contains(err.Error(), "unsupported update method") {
+			t.Errorf("update with method %s: error = %v", method, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("rejected imports sent requests: %v", requests)
+	}
+
+	bridge.config.ReadOnly = true
+	if len(bridge.bulkOperations()) != 0 {
+		t.Error("read-only mode must not offer bulk operations")
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+const (
+	// completionTop is how many rows a value completion reads from the service
+	completionTop = 20
+	// completionCacheTTL is how long completed values are reused for the same prefix
+	completionCacheTTL = time.Minute
+	// maxCompletionCacheEntries bounds the cache; it is cleared when full
+	maxCompletionCacheEntries = 1000
+)
+
+// completionEntry is a cached value completion
+type completionEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// complete answers completion/complete: entity set, function and property names from the
+// metadata, and property values from a prefix query against the service
+func (b *ODataMCPBridge) complete(ctx context.Context, req *mcp.CompletionRequest) ([]string, error) {
+	switch req.RefType {
+	case mcp.RefResource:
+		if req.RefName != b.resourceURI("{EntitySet}({key})") {
+			return nil, nil
+		}
+		switch req.Argument {
+		case "EntitySet":
+			return matchPrefix(b.resourceEntitySets(), req.Value), nil
+		case "key":
+			return b.completeKeyPredicate(ctx, req.Arguments["EntitySet"], req.Value)
+		}
+
+	case mcp.RefPrompt:
+		entitySetName, ok := strings.CutPrefix(req.RefName, "find_")
+		if !ok {
+			return nil, nil
+		}
+		switch req.Argument {
+		case "field":
+			return matchPrefix(b.completableProperties(entitySetName), req.Value), nil
+		case "value":
+			field := req.Arguments["field"]
+			if field == "" {
+				entityType := b.entityTypeOf(entitySetName)
+				if entityType == nil || len(entityType.KeyProperties) != 1 {
+					return nil, nil
+				}
+				field = entityType.KeyProperties[0]
+			}
+			return b.completeValues(ctx, entitySetName, field, req.Value)
+		}
+
+	case mcp.RefTool:
+		entitySetName := req.Arguments["entity_set"]
+		if info, ok := b.tools[req.RefName]; ok && info.EntitySet != "" {
+			entitySetName = info.EntitySet
+		}
+		switch req.Argument {
+		case "entity_set":
+			return matchPrefix(b.resourceEntitySets(), req.Value), nil
+		case "function_name":
+			return matchPrefix(b.completableFunctions(), req.Value), nil
+		case b.getParameterName("$select"):
+			// Complete the last name of the comma-separated list
+			done, last := "", req.Value
+			if i := strings.LastIndex(req.Value, ","); i >= 0 {
+				done, last = req.Value[:i+1], strings.TrimLeft(req.Value[i+1:], " ")
+			}
+			rule := b.policy.EntitySet(entitySetName)
+			var names []string
+			if entityType := b.entityTypeOf(entitySetName); entityType != nil && b.exposesEntitySet(entitySetName) {
+				names = readableProperties(rule, entityType)
+			}
+			values := matchPrefix(names, last)
+			for i, value := range values {
+				values[i] = done + value
+			}
+			return values, nil
+		default:
+			// Key arguments of the per-entity get, update and delete tools
+			if entityType := b.entityTypeOf(entitySetName); entityType != nil && containsString(entityType.KeyProperties, req.Argument) {
+				return b.completeValues(ctx, entitySetName, req.Argument, req.Value)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// exposesEntitySet checks that an entity set passes the entity filters and the access policy
+func (b *ODataMCPBridge) exposesEntitySet(entitySetName string) bool {
+	_, exists := b.metadata.EntitySets[entitySetName]
+	return exists && b.shouldIncludeEntity(entitySetName) && b.policy.AllowsEntitySet(entitySetName)
+}
+
+// completableFunctions lists the function imports that are exposed as tools
+func (b *ODataMCPBridge) completableFunctions() []string {
+	var names []string
+	for name := range b.metadata.FunctionImports {
+		if b.shouldIncludeFunction(name) && b.policy.AllowsFunction(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// completableProperties lists the properties of an entity set that may be read and filtered on
+func (b *ODataMCPBridge) completableProperties(entitySetName string) []string {
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil || !b.exposesEntitySet(entitySetName) {
+		return nil
+	}
+	rule := b.policy.EntitySet(entitySetName)
+	var names []string
+	for _, prop := range entityType.Properties {
+		if rule.AllowsProperty(policy.UsageSelect, prop.Name) && rule.AllowsProperty(policy.UsageFilter, prop.Name) {
+			names = append(names, prop.Name)
+		}
+	}
+	return names
+}
+
+// completeKeyPredicate completes the key predicate of a single-key entity set as OData
+// literals, e.g. 'ALFKI' for string keys
+func (b *ODataMCPBridge) completeKeyPredicate(ctx context.Context, entitySetName, value string) ([]string, error) {
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil || len(entityType.KeyProperties) != 1 {
+		return nil, nil
+	}
+	key := entityType.KeyProperties[0]
+	prop := propertyOf(entityType, key)
+	if prop == nil {
+		return nil, nil
+	}
+
+	values, err := b.completeValues(ctx, entitySetName, key, strings.TrimPrefix(value, "'"))
+	if err != nil || prop.Type != "Edm.String" {
+		return values, err
+	}
+	literals := make([]string, len(values))
+	for i, v := range values {
+		literals[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return literals, nil
+}
+
+// completeValues suggests values of a property starting with prefix, read with a
+// startswith filter for string properties and $top 20. The query goes through the read
+// policy like the filter tool; redacted properties are never completed. Results are
+// cached per caller, property and prefix.
+func (b *ODataMCPBridge) completeValues(ctx context.Context, entitySetName, property, prefix string) ([]string, error) {
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil || !containsString(b.completableProperties(entitySetName), property) || b.redactor.IsRedacted(property) {
+		return nil, nil
+	}
+	prop := propertyOf(entityType, property)
+
+	cacheKey := strings.Join([]string{callerIdentity(ctx), entitySetName, property, prefix}, "\x00")
+	b.completionMu.Lock()
+	if entry, ok := b.completionCache[cacheKey]; ok && time.Now().Before(entry.expires) {
+		b.completionMu.Unlock()
+		return entry.values, nil
+	}
+	b.completionMu.Unlock()
+
+	options := map[string]string{
+		constants.QuerySelect:      property,
+		constants.QueryOrderBy:     property,
+		constants.QueryTop:         strconv.Itoa(completionTop),
+		constants.QueryInlineCount: "none",
+	}
+	if prop.Type == "Edm.String" && prefix != "" {
+		options[constants.QueryFilter] = fmt.Sprintf("startswith(%s,'%s')", property, strings.ReplaceAll(prefix, "'", "''"))
+	}
+	if err := b.applyReadPolicy(entitySetName, constants.OpFilter, options); err != nil {
+		return nil, err
+	}
+	response, err := b.client.GetEntitySet(ctx, entitySetName, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete %s values: %w", property, err)
+	}
+
+	seen := make(map[string]bool)
+	values := []string{}
+	rows, _ := response.Value.([]interface{})
+	for _, row := range rows {
+		entity, _ := row.(map[string]interface{})
+		raw, exists := entity[property]
+		if !exists || raw == nil {
+			continue
+		}
+		var value string
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			value = fmt.Sprint(v)
+		}
+		// Values the redaction patterns would mask are left out rather than shown masked
+		if seen[value] || !strings.HasPrefix(value, prefix) || b.redactor.Text(value) != value {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+
+	b.completionMu.Lock()
+	if b.completionCache == nil || len(b.completionCache) >= maxCompletionCacheEntries {
+		b.completionCache = make(map[string]completionEntry)
+	}
+	b.completionCache[cacheKey] = completionEntry{values: values, expires: time.Now().Add(completionCacheTTL)}
+	b.completionMu.Unlock()
+	return values, nil
+}
+
+// matchPrefix returns the names starting with prefix, ignoring case, in alphabetical order
+func matchPrefix(names []string, prefix string) []string {
+	matches := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// containsString checks whether a slice contains a string
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
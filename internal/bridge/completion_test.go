@@ -0,0 +1,144 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/redact"
+)
+
+// completeArgument sends completion/complete and returns the completed values
+func completeArgument(t *testing.T, bridge *ODataMCPBridge, ref map[string]interface{}, name, value string, context map[string]interface{}) []string {
+	t.Helper()
+	params := map[string]interface{}{
+		"ref":      ref,
+		"argument": map[string]interface{}{"name": name, "value": value},
+	}
+	if context != nil {
+		params["context"] = map[string]interface{}{"arguments": context}
+	}
+	result, rpcErr := resourceRequest(t, bridge, "completion/complete", params)
+	if rpcErr != nil {
+		t.Fatalf("completion of %s failed: %+v", name, rpcErr)
+	}
+	completion := result["completion"].(map[string]interface{})
+	values := []string{}
+	for _, v := range completion["values"].([]interface{}) {
+		values = append(values, v.(string))
+	}
+	return values
+}
+
+// toolName returns the name of the tool for an operation on Products
+func toolName(t *testing.T, bridge *ODataMCPBridge, operation string) string {
+	t.Helper()
+	for name, info := range bridge.tools {
+		if info.EntitySet == "Products" && info.Operation == operation {
+			return name
+		}
+	}
+	t.Fatalf("no %s tool for Products", operation)
+	return ""
+}
+
+func TestCompletion(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+	bridge.generateResources()
+	bridge.generatePrompts()
+	bridge.server.SetCompleter(bridge.complete)
+	initializeClient(t, bridge, "2024-11-05")
+
+	template := map[string]interface{}{"type": "ref/resource", "uri": bridge.resourceURI("{EntitySet}({key})")}
+	if got := completeArgument(t, bridge, template, "EntitySet", "p", nil); !reflect.DeepEqual(got, []string{"Products"}) {
+		t.Errorf("EntitySet = %v", got)
+	}
+	if got := completeArgument(t, bridge, template, "EntitySet", "Cat", nil); len(got) != 0 {
+		t.Errorf("denied entity set completed: %v", got)
+	}
+
+	// Key values are read with the read policy applied
+	if got := completeArgument(t, bridge, template, "key", "", map[string]interface{}{"EntitySet": "Products"}); !reflect.DeepEqual(got, []string{"7"}) {
+		t.Errorf("key = %v", got)
+	}
+	query := lastQuery(t, requests)
+	if query.Get("$select") != "ProductID" || query.Get("$top") != "20" || query.Get("$filter") != "Price lt 100" {
+		t.Errorf("unexpected key query: %v", query)
+	}
+
+	find := map[string]interface{}{"type": "ref/prompt", "name": "find_Products"}
+	if got := completeArgument(t, bridge, find, "field", "pr", nil); !reflect.DeepEqual(got, []string{"ProductID", "ProductName"}) {
+		t.Errorf("field = %v", got)
+	}
+	if got := completeArgument(t, bridge, find, "value", "Ch", map[string]interface{}{"field": "ProductName"}); !reflect.DeepEqual(got, []string{"Chai"}) {
+		t.Errorf("value = %v", got)
+	}
+	if query := lastQuery(t, requests); query.Get("$filter") != "(startswith(ProductName,'Ch')) and (Price lt 100)" {
+		t.Errorf("unexpected prefix query: %v", query)
+	}
+	sent := len(requests)
+	completeArgument(t, bridge, find, "value", "Ch", map[string]interface{}{"field": "ProductName"})
+	if len(requests) != sent {
+		t.Error("repeated completion was not served from the cache")
+	}
+
+	filter := map[string]interface{}{"type": "ref/tool", "name": toolName(t, bridge, constants.OpFilter)}
+	if got := completeArgument(t, bridge, filter, "entity_set", "", nil); !reflect.DeepEqual(got, []string{"OrderDetails", "Products"}) {
+		t.Errorf("entity_set = %v", got)
+	}
+	if got := completeArgument(t, bridge, filter, "$select", "ProductName,Pr", nil); !reflect.DeepEqual(got, []string{"ProductName,ProductID", "ProductName,ProductName"}) {
+		t.Errorf("$select = %v", got)
+	}
+	get := map[string]interface{}{"type": "ref/tool", "name": toolName(t, bridge, constants.OpGet)}
+	if got := completeArgument(t, bridge, get, "ProductID", "7", nil); !reflect.DeepEqual(got, []string{"7"}) {
+		t.Errorf("ProductID = %v", got)
+	}
+
+	// Hidden and redacted properties are never queried
+	bridge.redactor, _ = redact.New([]string{"productname"}, nil, nil, redact.ModeMask)
+	sent = len(requests)
+	for _, field := range []string{"Price", "ProductName"} {
+		if got := completeArgument(t, bridge, find, "value", "", map[string]interface{}{"field": field}); len(got) != 0 {
+			t.Errorf("%s values completed: %v", field, got)
+		}
+	}
+	if len(requests) != sent {
+		t.Error("hidden or redacted values were queried")
+	}
+
+	if _, rpcErr := resourceRequest(t, bridge, "completion/complete", map[string]interface{}{
+		"ref":      map[string]interface{}{"type": "ref/prompt", "name": "explore_Categories"},
+		"argument": map[string]interface{}{"name": "value", "value": ""},
+	}); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("expected invalid params for an unknown prompt, got %+v", rpcErr)
+	}
+}
+
+func TestCompletionCachePerCaller(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	oauth := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Method: auth.MethodOAuth})
+	apiKey := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Method: auth.MethodAPIKey})
+
+	if _, err := bridge.completeValues(oauth, "Products", "ProductName", "Ch"); err != nil {
+		t.Fatalf("completeValues() error = %v", err)
+	}
+	sent := len(requests)
+	if _, err := bridge.completeValues(oauth, "Products", "ProductName", "Ch"); err != nil || len(requests) != sent {
+		t.Errorf("repeated completion by the same caller was not cached: %v", err)
+	}
+	// An API key named like the OAuth subject gets its own cache entries
+	if _, err := bridge.completeValues(apiKey, "Products", "ProductName", "Ch"); err != nil || len(requests) != sent+1 {
+		t.Errorf("completion was served from another caller's cache: %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/client"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/policy"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+type confirmTokenKey struct{}
+
+// confirmSummaryProperties limits the current values shown for a delete
+const confirmSummaryProperties = 20
+
+// confirmation describes a write for the user to approve
+type confirmation struct {
+	Operation  string                 `json:"operation"`
+	Target     string                 `json:"target"`
+	Method     string                 `json:"method,omitempty"`
+	Current    map[string]interface{} `json:"current,omitempty"`    // Entity as it is now (delete, update)
+	Changes    map[string]interface{} `json:"changes,omitempty"`    // Property -> {from, to} (update)
+	Parameters map[string]interface{} `json:"parameters,omitempty"` // Function parameters or bulk details
+	Note       string                 `json:"note,omitempty"`
+
+	payload interface{} // What the write sends; bound to the confirm token
+}
+
+// pendingConfirmation is a write waiting for its confirm_token to be echoed back by the
+// caller and session it was issued to
+type pendingConfirmation struct {
+	fingerprint string
+	caller      string // Authenticated caller, as method:subject
+	session     string // Transport session
+	expires     time.Time
+}
+
+// confirmTokenInputProperty is the confirm_token argument of tools covered by --confirm
+func confirmTokenInputProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Token from a previous call that returned confirmation_required. Pass it only after the user approved the summary, with otherwise identical arguments",
+	}
+}
+
+// applyConfirmPolicy adds the confirm_token argument to a tool covered by --confirm and
+// returns its description with a note telling the model approval will be asked for
+func (b *ODataMCPBridge) applyConfirmPolicy(operation, description string, properties map[string]interface{}) string {
+	if !b.config.RequiresConfirmation(operation) {
+		return description
+	}
+	properties["confirm_token"] = confirmTokenInputProperty()
+	return description + " (requires user confirmation)"
+}
+
+// needsConfirmation checks whether the --confirm policy covers an operation for this call.
+// Dry runs send nothing and need no approval.
+func (b *ODataMCPBridge) needsConfirmation(ctx context.Context, operation string) bool {
+	return b.config.RequiresConfirmation(operation) && client.DryRunFromContext(ctx) == nil
+}
+
+// confirmWrite asks the user to approve a write. It returns (nil, nil) when the write may
+// proceed, a tool result when the model must first obtain approval with a confirm token,
+// or an error when the user declined.
+func (b *ODataMCPBridge) confirmWrite(ctx context.Context, c *confirmation) (interface{}, error) {
+	if token, _ := ctx.Value(confirmTokenKey{}).(string); token != "" {
+		return nil, b.redeemConfirmToken(ctx, token, c)
+	}
+
+	if b.server != nil && b.server.SupportsElicitation(ctx) {
+		result, err := b.server.Elicit(ctx, c.message(), map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "Approve",
+					"description": "Apply this change",
+				},
+			},
+			"required": []string{"confirm"},
+		})
+		if err == nil {
+			if approved, _ := result.Content["confirm"].(bool); result.Action == mcp.ElicitAccept && approved {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%s of %s was not approved by the user", c.Operation, c.Target)
+		}
+		if !errors.Is(err, mcp.ErrElicitationUnsupported) {
+			return nil, fmt.Errorf("failed to get user confirmation: %w", err)
+		}
+	}
+
+	return b.issueConfirmToken(ctx, c)
+}
+
+// issueConfirmToken returns the two-step fallback result for clients without elicitation
+func (b *ODataMCPBridge) issueConfirmToken(ctx context.Context, c *confirmation) (interface{}, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to create confirm token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	fingerprint, err := c.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	b.confirmMu.Lock()
+	if b.confirmTokens == nil {
+		b.confirmTokens = make(map[string]*pendingConfirmation)
+	}
+	now := time.Now()
+	for t, pending := range b.confirmTokens {
+		if now.After(pending.expires) {
+			delete(b.confirmTokens, t)
+		}
+	}
+	b.confirmTokens[token] = &pendingConfirmation{
+		fingerprint: fingerprint,
+		caller:      callerIdentity(ctx),
+		session:     transport.SessionFromContext(ctx),
+		expires:     now.Add(time.Duration(constants.ConfirmTokenTTL) * time.Second),
+	}
+	b.confirmMu.Unlock()
+
+	logger.InfoContext(ctx, "Confirmation required, issued confirm token", "operation", c.Operation, "target", c.Target)
+
+	result, err := json.Marshal(map[string]interface{}{
+		"confirmation_required": true,
+		"confirm_token":         token,
+		"expires_in_seconds":    constants.ConfirmTokenTTL,
+		"confirmation":          c,
+		"message":               "Nothing was changed. Show this summary to the user. Only if they approve, call the same tool again with identical arguments plus confirm_token.",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(result), nil
+}
+
+// redeemConfirmToken consumes a token issued for exactly this write to the same caller.
+// A token presented by anyone else is left alone, so it cannot be used up by them either.
+func (b *ODataMCPBridge) redeemConfirmToken(ctx context.Context, token string, c *confirmation) error {
+	fingerprint, err := c.fingerprint()
+	if err != nil {
+		return err
+	}
+
+	b.confirmMu.Lock()
+	pending, ok := b.confirmTokens[token]
+	if ok && (pending.caller != callerIdentity(ctx) || pending.session != transport.SessionFromContext(ctx)) {
+		ok = false
+	} else {
+		delete(b.confirmTokens, token)
+	}
+	b.confirmMu.Unlock()
+
+	if !ok || time.Now().After(pending.expires) {
+		return fmt.Errorf("invalid or expired confirm_token; call again without it to request a new confirmation")
+	}
+	if pending.fingerprint != fingerprint {
+		return fmt.Errorf("confirm_token was issued for a different operation or arguments; call again without it to request a new confirmation")
+	}
+	return nil
+}
+
+// fingerprint identifies the write a token approves
+func (c *confirmation) fingerprint() (string, error) {
+	data, err := json.Marshal([]interface{}{c.Operation, c.Target, c.Method, c.payload})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint operation: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// message renders the confirmation for display in the client
+func (c *confirmation) message() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Approve %s of %s", c.Operation, c.Target)
+	if c.Method != "" {
+		fmt.Fprintf(&sb, " (%s)", c.Method)
+	}
+	sb.WriteString("?")
+
+	writeValues := func(title string, values map[string]interface{}, render func(interface{}) string) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n\n%s:", title)
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&sb, "\n- %s: %s", name, render(values[name]))
+		}
+	}
+
+	writeValues("Changes", c.Changes, func(v interface{}) string {
+		change, _ := v.(map[string]interface{})
+		from, known := change["from"]
+		if !known {
+			return "(not readable) -> " + formatConfirmValue(change["to"])
+		}
+		return formatConfirmValue(from) + " -> " + formatConfirmValue(change["to"])
+	})
+	writeValues("Current values", c.Current, formatConfirmValue)
+	writeValues("Parameters", c.Parameters, formatConfirmValue)
+
+	if c.Note != "" {
+		fmt.Fprintf(&sb, "\n\n%s", c.Note)
+	}
+	return sb.String()
+}
+
+// formatConfirmValue renders a value as JSON so strings, numbers and null are distinguishable
+func formatConfirmValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// describeDelete summarizes a delete with the entity as it currently is
+func (b *ODataMCPBridge) describeDelete(ctx context.Context, entitySetName string, key map[string]interface{}) *confirmation {
+	c := &confirmation{
+		Operation: constants.ConfirmDelete,
+		Target:    formatEntityTarget(entitySetName, key),
+		payload:   key,
+	}
+
+	current, err := b.fetchCurrentEntity(ctx, entitySetName, key)
+	if err != nil {
+		c.Note = fmt.Sprintf("The current entity could not be read: %v", err)
+		return c
+	}
+
+	if len(current) > confirmSummaryProperties {
+		names := make([]string, 0, len(current))
+		for name := range current {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names[confirmSummaryProperties:] {
+			delete(current, name)
+		}
+		c.Note = fmt.Sprintf("Showing %d of %d properties.", confirmSummaryProperties, len(names))
+	}
+	c.Current = current
+	return c
+}
+
+// describeUpdate summarizes an update as a diff against the entity as it currently is
+func (b *ODataMCPBridge) describeUpdate(ctx context.Context, entitySetName string, key, data map[string]interface{}, method string) *confirmation {
+	c := &confirmation{
+		Operation: constants.ConfirmUpdate,
+		Target:    formatEntityTarget(entitySetName, key),
+		Method:    method,
+		Changes:   make(map[string]interface{}),
+		payload:   []interface{}{key, data},
+	}
+
+	current, err := b.fetchCurrentEntity(ctx, entitySetName, key)
+	if err != nil {
+		c.Note = fmt.Sprintf("The current entity could not be read: %v", err)
+	}
+
+	rule := b.policy.EntitySet(entitySetName)
+	unchanged := 0
+	for name, value := range data {
+		if !rule.AllowsProperty(policy.UsageSelect, name) {
+			// The caller may write the property but not see its current value
+			c.Changes[name] = map[string]interface{}{"to": value}
+			continue
+		}
+		from, known := current[name]
+		if known && formatConfirmValue(from) == formatConfirmValue(value) {
+			unchanged++
+			continue
+		}
+		c.Changes[name] = map[string]interface{}{"from": from, "to": value}
+	}
+
+	var notes []string
+	if c.Note != "" {
+		notes = append(notes, c.Note)
+	}
+	if unchanged > 0 {
+		notes = append(notes, fmt.Sprintf("%d propert%s already ha%s the requested value.", unchanged, plural(unchanged, "y", "ies"), plural(unchanged, "s", "ve")))
+	}
+	if method == constants.PUT {
+		notes = append(notes, "PUT replaces the whole entity: properties not listed are reset to their defaults.")
+	}
+	c.Note = strings.Join(notes, " ")
+	return c
+}
+
+// describeFunction summarizes a modifying function import call
+func (b *ODataMCPBridge) describeFunction(functionName, method string, parameters map[string]interface{}) *confirmation {
+	return &confirmation{
+		Operation:  constants.ConfirmFunction,
+		Target:     functionName,
+		Method:     method,
+		Parameters: parameters,
+		payload:    parameters,
+	}
+}
+
+// fetchCurrentEntity reads the entity a write targets, without OData metadata or deferred
+// navigation properties, and with only the properties the access policy lets the caller read
+func (b *ODataMCPBridge) fetchCurrentEntity(ctx context.Context, entitySetName string, key map[string]interface{}) (map[string]interface{}, error) {
+	if err := b.checkPolicyScope(ctx, entitySetName, key); err != nil {
+		return nil, err
+	}
+	entity, _, err := b.snapshotEntity(ctx, entitySetName, key)
+	if err != nil {
+		return nil, err
+	}
+	return b.readableEntity(entitySetName, entity), nil
+}
+
+// formatEntityTarget renders EntitySet(key) for display
+func formatEntityTarget(entitySetName string, key map[string]interface{}) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := key[name]
+		rendered := fmt.Sprint(value)
+		if s, ok := value.(string); ok {
+			rendered = "'" + s + "'"
+		}
+		if len(names) == 1 {
+			parts = append(parts, rendered)
+		} else {
+			parts = append(parts, name+"="+rendered)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", entitySetName, strings.Join(parts, ","))
+}
+
+// plural picks the singular or plural suffix for n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
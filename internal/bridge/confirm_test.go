@@ -0,0 +1,262 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+type confirmOutput struct {
+	ConfirmationRequired bool         `json:"confirmation_required"`
+	ConfirmToken         string       `json:"confirm_token"`
+	Confirmation         confirmation `json:"confirmation"`
+}
+
+// newConfirmTestBridge returns a bridge whose service holds Products(7) and records every write
+func newConfirmTestBridge(t *testing.T, confirm string, writes *[]string) *ODataMCPBridge {
+	t.Helper()
+	var mu sync.Mutex
+	return newServiceTestBridge(t, &config.Config{Confirm: confirm}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.Header().Set("X-CSRF-Token", "token")
+			w.Write([]byte(`{"d":{"__metadata":{"uri":"Products(7)"},"ProductID":7,"ProductName":"Chai","Price":"18.00","Category":{"__deferred":{"uri":"Products(7)/Category"}}}}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		*writes = append(*writes, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func parseConfirm(t *testing.T, result interface{}, err error) confirmOutput {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out confirmOutput
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+	if !out.ConfirmationRequired || out.ConfirmToken == "" {
+		t.Fatalf("expected a confirmation request, got %s", result)
+	}
+	return out
+}
+
+func TestConfirmTokenFlow(t *testing.T) {
+	var writes []string
+	bridge := newConfirmTestBridge(t, "update,delete", &writes)
+	product := bridge.metadata.EntityTypes["Product"]
+	ctx := context.Background()
+
+	updateArgs := func() map[string]interface{} {
+		return map[string]interface{}{"ProductID": float64(7), "ProductName": "Chai", "Price": 20.0, "_method": "MERGE"}
+	}
+
+	// The first call only describes the change
+	result, err := bridge.handleEntityUpdate(ctx, "Products", product, updateArgs())
+	out := parseConfirm(t, result, err)
+	if len(writes) != 0 {
+		t.Fatalf("nothing may be sent before confirmation, got %v", writes)
+	}
+	c := out.Confirmation
+	if c.Operation != "update" || c.Target != "Products(7)" || c.Method != "MERGE" {
+		t.Errorf("unexpected confirmation: %+v", c)
+	}
+	price, _ := c.Changes["Price"].(map[string]interface{})
+	if price["from"] != "18.00" || price["to"] != "20" || c.Changes["ProductName"] != nil {
+		t.Errorf("unexpected diff: %+v", c.Changes)
+	}
+
+	// A token does not approve different arguments, and is used up by the attempt
+	args := updateArgs()
+	args["Price"] = 99.0
+	args["confirm_token"] = out.ConfirmToken
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, args); err == nil || !strings.Contains(err.Error(), "different operation") {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+	args = updateArgs()
+	args["confirm_token"] = out.ConfirmToken
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, args); err == nil {
+		t.Error("expected a used token to be rejected")
+	}
+
+	// Echoing a fresh token with identical arguments performs the write
+	result, err = bridge.handleEntityUpdate(ctx, "Products", product, updateArgs())
+	out = parseConfirm(t, result, err)
+	args = updateArgs()
+	args["confirm_token"] = out.ConfirmToken
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, args); err != nil {
+		t.Fatalf("confirmed update failed: %v", err)
+	}
+	if len(writes) != 1 || !strings.HasPrefix(writes[0], "MERGE /Products(7)") || strings.Contains(writes[0], "confirm_token") {
+		t.Errorf("unexpected writes: %v", writes)
+	}
+
+	// Delete shows the current entity without metadata or deferred links
+	result, err = bridge.handleLazyDeleteEntity(ctx, map[string]interface{}{
+		"entity_set": "Products",
+		"key":        map[string]interface{}{"ProductID": float64(7)},
+	})
+	out = parseConfirm(t, result, err)
+	if out.Confirmation.Current["ProductName"] != "Chai" || out.Confirmation.Current["Category"] != nil || out.Confirmation.Current["__metadata"] != nil {
+		t.Errorf("unexpected current values: %+v", out.Confirmation.Current)
+	}
+
+	// Creates are outside the policy and dry runs never ask
+	if _, err := bridge.handleEntityCreate(ctx, "Products", map[string]interface{}{"ProductName": "Chang"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	result, err = bridge.handleEntityDelete(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7), "dry_run": true})
+	parseDryRun(t, result, err)
+	if len(writes) != 2 {
+		t.Errorf("unexpected writes: %v", writes)
+	}
+}
+
+func TestConfirmTokenBoundToCaller(t *testing.T) {
+	var writes []string
+	bridge := newConfirmTestBridge(t, "update", &writes)
+	product := bridge.metadata.EntityTypes["Product"]
+	alice := &auth.Principal{Subject: "alice", Method: auth.MethodOAuth}
+	owner := transport.WithSession(auth.WithPrincipal(context.Background(), alice), "s1")
+
+	updateArgs := func(token string) map[string]interface{} {
+		return map[string]interface{}{"ProductID": float64(7), "Price": 20.0, "_method": "MERGE", "confirm_token": token}
+	}
+	result, err := bridge.handleEntityUpdate(owner, "Products", product, updateArgs(""))
+	token := parseConfirm(t, result, err).ConfirmToken
+
+	// Nobody else can approve the write, whether by subject, method or session
+	for name, ctx := range map[string]context.Context{
+		"anonymous":     context.Background(),
+		"other session": transport.WithSession(auth.WithPrincipal(context.Background(), alice), "s2"),
+		"api key":       transport.WithSession(auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Method: auth.MethodAPIKey}), "s1"),
+	} {
+		if _, err := bridge.handleEntityUpdate(ctx, "Products", product, updateArgs(token)); err == nil || !strings.Contains(err.Error(), "invalid or expired confirm_token") {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+	if len(writes) != 0 {
+		t.Fatalf("another caller's token performed a write: %v", writes)
+	}
+
+	// Their attempts do not use up the token
+	if _, err := bridge.handleEntityUpdate(owner, "Products", product, updateArgs(token)); err != nil {
+		t.Fatalf("confirmed update failed: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Errorf("unexpected writes: %v", writes)
+	}
+}
+
+func TestConfirmUnderPolicy(t *testing.T) {
+	bridge := newServiceTestBridge(t, &config.Config{Confirm: "update,delete"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		filter := r.URL.Query().Get("$filter")
+		switch {
+		case filter == "":
+			w.Write([]byte(`{"d":{"ProductID":7,"ProductName":"Chai","Price":"18.00"}}`))
+		case strings.Contains(filter, "ProductID eq 7"):
+			w.Write([]byte(`{"d":{"results":[{"ProductID":7,"ProductName":"Chai","Price":"18.00"}]}}`))
+		default:
+			w.Write([]byte(`{"d":{"results":[]}}`))
+		}
+	}))
+	usePolicy(t, bridge, testPolicy)
+	ctx := context.Background()
+	key := map[string]interface{}{"ProductID": float64(7)}
+
+	// Properties hidden from reads are not shown as current values or as the old side of a change
+	if c := bridge.describeDelete(ctx, "Products", key); c.Current["ProductName"] != "Chai" || c.Current["Price"] != nil {
+		t.Errorf("unexpected current values: %+v", c.Current)
+	}
+	c := bridge.describeUpdate(ctx, "Products", key, map[string]interface{}{"ProductName": "Chang", "Price": 5.0}, "MERGE")
+	name, _ := c.Changes["ProductName"].(map[string]interface{})
+	price, _ := c.Changes["Price"].(map[string]interface{})
+	if name["from"] != "Chai" || price["to"] != 5.0 {
+		t.Errorf("unexpected diff: %+v", c.Changes)
+	}
+	if _, shown := price["from"]; shown {
+		t.Errorf("hidden current value shown: %+v", c.Changes)
+	}
+	if text := c.message(); strings.Contains(text, "18.00") || !strings.Contains(text, "(not readable) -> 5") {
+		t.Errorf("unexpected confirmation message:\n%s", text)
+	}
+
+	// Entities outside the mandatory filters are not read at all
+	if c := bridge.describeDelete(ctx, "Products", map[string]interface{}{"ProductID": float64(8)}); c.Current != nil || !strings.Contains(c.Note, "outside the access policy scope") {
+		t.Errorf("entity outside the scope was described: %+v", c)
+	}
+}
+
+// elicitingTransport answers elicitation/create requests like a client would
+type elicitingTransport struct {
+	bridge   *ODataMCPBridge
+	answer   map[string]interface{}
+	messages []string
+}
+
+func (e *elicitingTransport) Start(ctx context.Context) error          { return nil }
+func (e *elicitingTransport) ReadMessage() (*transport.Message, error) { return nil, io.EOF }
+func (e *elicitingTransport) Close() error                             { return nil }
+func (e *elicitingTransport) CanSendRequests() bool                    { return true }
+
+func (e *elicitingTransport) WriteMessage(msg *transport.Message) error {
+	var params struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(msg.Params, &params)
+	e.messages = append(e.messages, msg.Method+": "+params.Message)
+
+	result, _ := json.Marshal(e.answer)
+	go e.bridge.server.HandleMessage(context.Background(), &transport.Message{JSONRPC: "2.0", ID: msg.ID, Result: result})
+	return nil
+}
+
+func TestConfirmElicitation(t *testing.T) {
+	var writes []string
+	bridge := newConfirmTestBridge(t, "all", &writes)
+	product := bridge.metadata.EntityTypes["Product"]
+	ctx := context.Background()
+
+	fake := &elicitingTransport{bridge: bridge, answer: map[string]interface{}{"action": "decline"}}
+	bridge.server.SetTransport(fake)
+	if _, err := bridge.server.HandleMessage(ctx, &transport.Message{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage("1"),
+		Method:  "initialize",
+		Params:  json.RawMessage(`{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}}}`),
+	}); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	if _, err := bridge.handleEntityDelete(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7)}); err == nil || !strings.Contains(err.Error(), "not approved") {
+		t.Errorf("expected declined delete, got %v", err)
+	}
+	if len(writes) != 0 || len(fake.messages) != 1 || !strings.Contains(fake.messages[0], `elicitation/create: Approve delete of Products(7)?`) || !strings.Contains(fake.messages[0], `- ProductName: "Chai"`) {
+		t.Fatalf("unexpected exchange: writes %v, messages %v", writes, fake.messages)
+	}
+
+	fake.answer = map[string]interface{}{"action": "accept", "content": map[string]interface{}{"confirm": true}}
+	if _, err := bridge.handleEntityDelete(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7)}); err != nil {
+		t.Fatalf("accepted delete failed: %v", err)
+	}
+	if len(writes) != 1 || !strings.HasPrefix(writes[0], "DELETE /Products(7)") {
+		t.Errorf("unexpected writes: %v", writes)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zmcp/odata-mcp/internal/client"
+)
+
+// dryRunInputProperty is the per-call dry_run argument of modifying tools
+func dryRunInputProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "boolean",
+		"description": "Return the fully resolved HTTP request (method, URL, masked headers, body) without sending it",
+		"default":     false,
+	}
+}
+
+// withCallOptions moves the dry_run and confirm_token arguments into ctx so they never
+// reach the request body and survive delegation from lazy to eager handlers.
+// Dry-run mode is also enabled by the global --dry-run flag.
+func (b *ODataMCPBridge) withCallOptions(ctx context.Context, args map[string]interface{}) context.Context {
+	requested, _ := args["dry_run"].(bool)
+	delete(args, "dry_run")
+
+	if requested || b.config.DryRun {
+		ctx, _ = client.WithDryRun(ctx)
+	}
+
+	if token, ok := args["confirm_token"].(string); ok {
+		delete(args, "confirm_token")
+		ctx = context.WithValue(ctx, confirmTokenKey{}, token)
+	}
+	return ctx
+}
+
+// dryRunResult formats the request captured in ctx as the tool result
+func (b *ODataMCPBridge) dryRunResult(ctx context.Context, operation string) (interface{}, error) {
+	rec := client.DryRunFromContext(ctx)
+	if rec == nil {
+		return nil, fmt.Errorf("no dry-run request recorded")
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"dry_run":   true,
+		"operation": operation,
+		"request":   rec,
+		"message":   "Request was not sent",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(result), nil
+}
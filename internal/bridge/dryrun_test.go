@@ -0,0 +1,151 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+type dryRunOutput struct {
+	DryRun    bool   `json:"dry_run"`
+	Operation string `json:"operation"`
+	Request   struct {
+		Method  string            `json:"method"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+		Body    json.RawMessage   `json:"body"`
+	} `json:"request"`
+}
+
+// newDryRunTestBridge returns a bridge whose service fails the test on any request
+func newDryRunTestBridge(t *testing.T, cfg *config.Config) *ODataMCPBridge {
+	t.Helper()
+	bridge := newServiceTestBridge(t, cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run sent %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	bridge.client.SetBasicAuth("alice", "s3cret-password")
+	return bridge
+}
+
+func parseDryRun(t *testing.T, result interface{}, err error) dryRunOutput {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	var out dryRunOutput
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+	if !out.DryRun {
+		t.Fatalf("expected a dry-run result, got %s", result)
+	}
+	return out
+}
+
+func TestDryRunPerCall(t *testing.T) {
+	bridge := newDryRunTestBridge(t, &config.Config{})
+	ctx := context.Background()
+
+	result, err := bridge.handleEntityCreate(ctx, "Products", map[string]interface{}{
+		"ProductName": "Chai",
+		"Price":       18.5,
+		"dry_run":     true,
+	})
+	out := parseDryRun(t, result, err)
+	if out.Request.Method != "POST" || !strings.HasSuffix(out.Request.URL, "/Products") {
+		t.Errorf("unexpected request: %+v", out.Request)
+	}
+	// The body is the converted payload and never includes dry_run
+	if string(out.Request.Body) != `{"Price":"18.5","ProductName":"Chai"}` {
+		t.Errorf("body = %s", out.Request.Body)
+	}
+	if auth := out.Request.Headers["Authorization"]; !strings.HasPrefix(auth, "Basic ") || strings.Contains(auth, "YWxpY2U6czNjcmV0") {
+		t.Errorf("Authorization header not masked: %q", auth)
+	}
+
+	result, err = bridge.handleEntityUpdate(ctx, "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{
+		"ProductID": float64(7),
+		"Price":     2.5,
+		"_method":   "MERGE",
+		"dry_run":   true,
+	})
+	out = parseDryRun(t, result, err)
+	if out.Operation != "update" || out.Request.Method != "MERGE" || !strings.HasSuffix(out.Request.URL, "/Products(7)") {
+		t.Errorf("unexpected update request: %+v", out.Request)
+	}
+
+	// Validation still runs before anything is resolved
+	if _, err := bridge.handleEntityDelete(ctx, "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{"dry_run": true}); err == nil {
+		t.Error("expected missing key error in dry run")
+	}
+}
+
+func TestDryRunGlobalLazy(t *testing.T) {
+	bridge := newDryRunTestBridge(t, &config.Config{DryRun: true})
+	bridge.metadata.FunctionImports = map[string]*models.FunctionImport{
+		"Reset": {Name: "Reset", HTTPMethod: "POST", Parameters: []*models.FunctionParameter{{Name: "Force", Type: "Edm.Boolean", Mode: "In"}}},
+	}
+	ctx := context.Background()
+
+	result, err := bridge.handleLazyDeleteEntity(ctx, map[string]interface{}{
+		"entity_set": "OrderDetails",
+		"key":        map[string]interface{}{"OrderID": float64(1), "ProductID": float64(2)},
+	})
+	out := parseDryRun(t, result, err)
+	if out.Request.Method != "DELETE" || !strings.Contains(out.Request.URL, "OrderDetails(") || out.Request.Headers["X-CSRF-Token"] == "" {
+		t.Errorf("unexpected delete request: %+v", out.Request)
+	}
+
+	result, err = bridge.handleLazyCallFunction(ctx, map[string]interface{}{
+		"function_name": "Reset",
+		"params":        map[string]interface{}{"Force": true},
+	})
+	out = parseDryRun(t, result, err)
+	if out.Operation != "Reset" || out.Request.Method != "POST" || string(out.Request.Body) != `{"Force":true}` {
+		t.Errorf("unexpected function request: %+v", out.Request)
+	}
+}
+
+func TestDryRunChecksPolicyScope(t *testing.T) {
+	var reads []string
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("dry run sent %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		filter := r.URL.Query().Get("$filter")
+		reads = append(reads, filter)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(filter, "ProductID eq 7") {
+			w.Write([]byte(`{"d":{"results":[{"ProductID":7,"ProductName":"Chai"}]}}`))
+			return
+		}
+		w.Write([]byte(`{"d":{"results":[]}}`))
+	}))
+	usePolicy(t, bridge, testPolicy)
+	product := bridge.metadata.EntityTypes["Product"]
+	ctx := context.Background()
+
+	// A dry run is refused where the real call would be
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, map[string]interface{}{"ProductID": float64(8), "ProductName": "Chang", "dry_run": true}); err == nil || !strings.Contains(err.Error(), "outside the access policy scope") {
+		t.Errorf("expected a scope error for a dry run, got %v", err)
+	}
+
+	result, err := bridge.handleEntityUpdate(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7), "ProductName": "Chang", "_method": "MERGE", "dry_run": true})
+	if out := parseDryRun(t, result, err); out.Request.Method != "MERGE" {
+		t.Errorf("unexpected request: %+v", out.Request)
+	}
+	if len(reads) != 2 || reads[1] != "(ProductID eq 7) and (Price lt 100)" {
+		t.Errorf("scope checks = %q", reads)
+	}
+}
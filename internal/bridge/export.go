@@ -0,0 +1,682 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/utils"
+)
+
+// Export file formats
+const (
+	exportFormatCSV     = "csv"
+	exportFormatJSONL   = "jsonl"
+	exportFormatParquet = "parquet"
+)
+
+// exportPreviewRows is the number of rows echoed back to the model after an export
+const exportPreviewRows = 5
+
+// exportColumn is one output column with the Edm type used for normalization
+type exportColumn struct {
+	Name    string
+	EdmType string
+}
+
+// exportWriter writes normalized rows to an export file
+type exportWriter interface {
+	WriteRow(row map[string]interface{}) error
+	Close() error
+}
+
+// exportInputProperties returns the schema properties shared by the eager and lazy export tools
+func (b *ODataMCPBridge) exportInputProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "File format",
+			"enum":        []string{exportFormatCSV, exportFormatJSONL, exportFormatParquet},
+			"default":     exportFormatCSV,
+		},
+		"file_name": map[string]interface{}{
+			"type":        "string",
+			"description": "File name relative to the export directory (default: <EntitySet>_<timestamp>.<format>)",
+		},
+		"overwrite": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Replace the file if it already exists",
+			"default":     false,
+		},
+		b.getParameterName("$filter"): map[string]interface{}{
+			"type":        "string",
+			"description": "OData filter expression",
+		},
+		b.getParameterName("$select"): map[string]interface{}{
+			"type":        "string",
+			"description": "Comma-separated list of properties to export (default: all properties)",
+		},
+		b.getParameterName("$expand"): map[string]interface{}{
+			"type":        "string",
+			"description": "Navigation properties to expand (written as JSON)",
+		},
+		b.getParameterName("$orderby"): map[string]interface{}{
+			"type":        "string",
+			"description": "Properties to order by",
+		},
+		b.getParameterName("$top"): map[string]interface{}{
+			"type":        "integer",
+			"description": "Maximum number of entities to export (default: all)",
+		},
+	}
+}
+
+// generateExportTool creates an export tool for an entity set
+func (b *ODataMCPBridge) generateExportTool(entitySetName string, entitySet *models.EntitySet, entityType *models.EntityType) {
+	opName := constants.GetToolOperationName(constants.OpExport, b.config.ToolShrink)
+	toolName := b.formatToolName(opName, entitySetName)
+
+	description := fmt.Sprintf("Export all matching %s entities to a CSV, JSONL or Parquet file; returns the file path, row count and a preview", entitySetName) + b.policyToolNote(entitySetName)
+
+	properties := b.exportInputProperties()
+	b.applyPolicyTopLimit(entitySetName, properties)
+
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		},
+	}
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleEntityExport(ctx, entitySetName, entityType, args)
+	}
+
+	b.server.AddTool(tool, handler)
+
+	// Track tool info
+	b.tools[toolName] = &models.ToolInfo{
+		Name:        toolName,
+		Description: description,
+		EntitySet:   entitySetName,
+		Operation:   constants.OpExport,
+	}
+}
+
+// generateLazyExportEntitiesTool creates the export_entities generic tool
+func (b *ODataMCPBridge) generateLazyExportEntitiesTool() error {
+	toolName := b.formatToolName("export_entities", "")
+
+	properties := b.exportInputProperties()
+	properties["entity_set"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Name of the entity set to export (e.g., 'PurchaseOrders')",
+	}
+
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: "Export all matching entities of any entity set to a CSV, JSONL or Parquet file; returns the file path, row count and a preview",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"entity_set"},
+		},
+	}
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleLazyExportEntities(ctx, args)
+	}
+
+	b.server.AddTool(tool, handler)
+
+	// Track tool info
+	b.tools[toolName] = &models.ToolInfo{
+		Name:        toolName,
+		Description: tool.Description,
+		Operation:   constants.OpExport,
+	}
+
+	return nil
+}
+
+// handleLazyExportEntities handles lazy mode export operations
+func (b *ODataMCPBridge) handleLazyExportEntities(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Extract entity_set parameter
+	entitySet, ok := args["entity_set"].(string)
+	if !ok || entitySet == "" {
+		return nil, fmt.Errorf("missing required parameter: entity_set")
+	}
+
+	// Validate entity set and get entity type
+	_, entityType, err := b.validateEntitySet(entitySet)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove entity_set from args before delegating
+	delete(args, "entity_set")
+
+	// Delegate to existing handler
+	return b.handleEntityExport(ctx, entitySet, entityType, args)
+}
+
+// handleEntityExport streams every page of a query into a file in the export directory
+func (b *ODataMCPBridge) handleEntityExport(ctx context.Context, entitySetName string, entityType *models.EntityType, args map[string]interface{}) (interface{}, error) {
+	if b.config.ExportDir == "" {
+		return nil, fmt.Errorf("export is disabled: no export directory configured (--export-dir)")
+	}
+
+	format := exportFormatCSV
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = strings.ToLower(f)
+	}
+	switch format {
+	case exportFormatCSV, exportFormatJSONL, exportFormatParquet:
+	default:
+		return nil, fmt.Errorf("unsupported export format '%s' (supported: csv, jsonl, parquet)", format)
+	}
+
+	fileName, _ := args["file_name"].(string)
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s_%s", entitySetName, time.Now().UTC().Format("20060102T150405Z"))
+	}
+	if filepath.Ext(fileName) == "" {
+		fileName += "." + format
+	}
+	overwrite, _ := args["overwrite"].(bool)
+
+	path, err := b.resolveExportPath(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if !overwrite {
+		if _, err := os.Lstat(path); err == nil {
+			return nil, fmt.Errorf("export file already exists: %s (set overwrite to replace it)", fileName)
+		}
+	}
+
+	// Map arguments to handle both Claude-friendly and standard parameter names
+	options := make(map[string]string)
+	maxRows := 0
+	var selected []string
+	for key, value := range args {
+		switch b.mapParameterToOData(key) {
+		case "$filter":
+			if filter, ok := value.(string); ok && filter != "" {
+				options[constants.QueryFilter] = b.transformFilterForSAP(filter, entitySetName)
+			}
+		case "$select":
+			if sel, ok := value.(string); ok && sel != "" {
+				options[constants.QuerySelect] = sel
+				for _, name := range strings.Split(sel, ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						selected = append(selected, name)
+					}
+				}
+			}
+		case "$expand":
+			if expand, ok := value.(string); ok && expand != "" {
+				options[constants.QueryExpand] = expand
+			}
+		case "$orderby":
+			if orderby, ok := value.(string); ok && orderby != "" {
+				options[constants.QueryOrderBy] = orderby
+			}
+		case "$top":
+			if top, ok := value.(float64); ok && top > 0 {
+				maxRows = int(top)
+			}
+		}
+	}
+
+	// The access policy may narrow the selected columns and cap the row count
+	if b.policy != nil {
+		if maxRows > 0 {
+			options[constants.QueryTop] = strconv.Itoa(maxRows)
+		}
+		if err := b.applyReadPolicy(entitySetName, constants.OpExport, options); err != nil {
+			return nil, err
+		}
+		if top, err := strconv.Atoi(options[constants.QueryTop]); err == nil {
+			maxRows = top
+		}
+		delete(options, constants.QueryTop)
+		selected = splitPropertyList(options[constants.QuerySelect])
+	}
+
+	columns := exportColumns(entityType, selected, options[constants.QueryExpand])
+
+	// Redacted properties are left out: a mask would not fit typed (Parquet) columns
+	var redactedColumns []string
+	if !b.redactor.Empty() {
+		kept := columns[:0]
+		for _, col := range columns {
+			if b.redactor.IsRedacted(col.Name) {
+				redactedColumns = append(redactedColumns, col.Name)
+				continue
+			}
+			kept = append(kept, col)
+		}
+		columns = kept
+	}
+
+	// Write to a temporary file and rename, so a failed export never leaves a partial file behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	writer, err := newExportWriter(format, tmp, columns)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+
+	preview := make([]map[string]interface{}, 0, exportPreviewRows)
+	rowCount, truncated, err := b.forEachPage(ctx, entitySetName, options, maxRows, func(rows []interface{}) error {
+		for _, row := range rows {
+			entity, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			normalized := b.redactRow(normalizeExportRow(entity, columns, format == exportFormatJSONL))
+			if err := writer.WriteRow(normalized); err != nil {
+				return fmt.Errorf("failed to write export row: %w", err)
+			}
+			if len(preview) < exportPreviewRows {
+				preview = append(preview, normalized)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		writer.Close()
+		tmp.Close()
+		if b.config.VerboseErrors {
+			return nil, fmt.Errorf("failed to export entities from %s with options %v: %w", entitySetName, options, err)
+		}
+		return nil, fmt.Errorf("failed to export entities: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to finish export file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish export file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return nil, fmt.Errorf("failed to finish export file: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Exported rows", "rows", rowCount, "entity_set", entitySetName, "file", path)
+
+	columnNames := make([]string, len(columns))
+	for i, col := range columns {
+		columnNames[i] = col.Name
+	}
+
+	result := map[string]interface{}{
+		"entity_set": entitySetName,
+		"file_path":  path,
+		"format":     format,
+		"row_count":  rowCount,
+		"columns":    columnNames,
+		"preview":    preview,
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	if len(redactedColumns) > 0 {
+		result["redacted_columns"] = redactedColumns
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(response), nil
+}
+
+// resolveExportPath maps a requested file name to a writable path inside the export directory
+func (b *ODataMCPBridge) resolveExportPath(fileName string) (string, error) {
+	return resolvePathInDir(b.config.ExportDir, fileName, true)
+}
+
+// exportColumns decides the output columns: the selected properties, or every
+// property of the entity type, followed by expanded navigation properties
+func exportColumns(entityType *models.EntityType, selected []string, expand string) []exportColumn {
+	edmTypes := make(map[string]string, len(entityType.Properties))
+	for _, prop := range entityType.Properties {
+		edmTypes[prop.Name] = prop.Type
+	}
+
+	var columns []exportColumn
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, exportColumn{Name: name, EdmType: edmTypes[name]})
+		}
+	}
+
+	if len(selected) > 0 {
+		for _, name := range selected {
+			// Navigation paths in $select (e.g. "Supplier/Name") arrive nested under the first segment
+			add(strings.SplitN(name, "/", 2)[0])
+		}
+	} else {
+		for _, prop := range entityType.Properties {
+			add(prop.Name)
+		}
+	}
+
+	for _, nav := range strings.Split(expand, ",") {
+		if nav = strings.TrimSpace(nav); nav != "" {
+			add(strings.SplitN(nav, "/", 2)[0])
+		}
+	}
+
+	return columns
+}
+
+// normalizeExportRow applies the same date normalization as responses (legacy
+// /Date(...)/ to ISO 8601) and keeps decimals as exact strings. Tabular formats
+// get exactly the export columns; JSONL keeps every property except metadata.
+func normalizeExportRow(entity map[string]interface{}, columns []exportColumn, keepAll bool) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+
+	if keepAll {
+		for key, value := range entity {
+			if key == "__metadata" || strings.HasPrefix(key, "@") {
+				continue
+			}
+			row[key] = utils.ConvertDateValue(value, true, key)
+		}
+	} else {
+		for _, col := range columns {
+			row[col.Name] = utils.ConvertDateValue(entity[col.Name], true, col.Name)
+		}
+	}
+
+	for _, col := range columns {
+		if value, ok := row[col.Name]; ok {
+			row[col.Name] = normalizeExportNumber(value, col.EdmType)
+		}
+	}
+
+	// Expanded entities carry their own metadata blocks
+	for key, value := range row {
+		row[key] = stripExportMetadata(value)
+	}
+
+	return row
+}
+
+// normalizeExportNumber renders decimals as exact strings so no precision is lost
+// on the way to a spreadsheet, whether the service sent a number or a string
+func normalizeExportNumber(value interface{}, edmType string) interface{} {
+	if edmType != "Edm.Decimal" || value == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return utils.FormatDecimalString(strings.TrimSpace(v))
+	case float64:
+		return utils.ConvertNumericToString(v)
+	}
+	return value
+}
+
+// stripExportMetadata removes __metadata and @odata annotations from nested values
+func stripExportMetadata(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		cleaned := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if key == "__metadata" || strings.HasPrefix(key, "@") {
+				continue
+			}
+			cleaned[key] = stripExportMetadata(item)
+		}
+		// v2 wraps expanded collections in {"results": [...]}
+		if results, ok := cleaned["results"]; ok && len(cleaned) == 1 {
+			return results
+		}
+		return cleaned
+	case []interface{}:
+		cleaned := make([]interface{}, len(v))
+		for i, item := range v {
+			cleaned[i] = stripExportMetadata(item)
+		}
+		return cleaned
+	}
+	return value
+}
+
+// newExportWriter creates the writer for a format
+func newExportWriter(format string, file *os.File, columns []exportColumn) (exportWriter, error) {
+	switch format {
+	case exportFormatCSV:
+		return newCSVExportWriter(file, columns)
+	case exportFormatJSONL:
+		return newJSONLExportWriter(file), nil
+	case exportFormatParquet:
+		return newParquetExportWriter(file, columns)
+	}
+	return nil, fmt.Errorf("unsupported export format '%s'", format)
+}
+
+// csvExportWriter writes a header row followed by one line per entity
+type csvExportWriter struct {
+	writer  *csv.Writer
+	columns []exportColumn
+	record  []string
+}
+
+func newCSVExportWriter(file *os.File, columns []exportColumn) (*csvExportWriter, error) {
+	w := &csvExportWriter{
+		writer:  csv.NewWriter(file),
+		columns: columns,
+		record:  make([]string, len(columns)),
+	}
+	for i, col := range columns {
+		w.record[i] = col.Name
+	}
+	if err := w.writer.Write(w.record); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return w, nil
+}
+
+func (w *csvExportWriter) WriteRow(row map[string]interface{}) error {
+	for i, col := range w.columns {
+		w.record[i] = formatExportCell(row[col.Name])
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// formatExportCell renders a value as a CSV cell
+func formatExportCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		// Avoid scientific notation for large amounts
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// jsonlExportWriter writes one JSON object per line
+type jsonlExportWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLExportWriter(file *os.File) *jsonlExportWriter {
+	buffer := bufio.NewWriter(file)
+	return &jsonlExportWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (w *jsonlExportWriter) WriteRow(row map[string]interface{}) error {
+	return w.encoder.Encode(row)
+}
+
+func (w *jsonlExportWriter) Close() error {
+	return w.buffer.Flush()
+}
+
+// parquetExportWriter writes a flat, optional-column Parquet file. Integers map
+// to INT64, floating point and decimals to DOUBLE, booleans to BOOLEAN and
+// everything else (including ISO dates and expanded entities as JSON) to UTF-8 strings.
+type parquetExportWriter struct {
+	writer  *parquet.Writer
+	columns []exportColumn
+	index   []int // schema column index of each export column
+	kinds   []string
+	row     parquet.Row
+}
+
+func newParquetExportWriter(file *os.File, columns []exportColumn) (*parquetExportWriter, error) {
+	group := make(parquet.Group, len(columns))
+	kinds := make([]string, len(columns))
+	for i, col := range columns {
+		kinds[i] = parquetKind(col.EdmType)
+		switch kinds[i] {
+		case "int":
+			group[col.Name] = parquet.Optional(parquet.Int(64))
+		case "double":
+			group[col.Name] = parquet.Optional(parquet.Leaf(parquet.DoubleType))
+		case "bool":
+			group[col.Name] = parquet.Optional(parquet.Leaf(parquet.BooleanType))
+		default:
+			group[col.Name] = parquet.Optional(parquet.String())
+		}
+	}
+	schema := parquet.NewSchema("entity", group)
+
+	// Group columns are ordered by name in the schema
+	positions := make(map[string]int)
+	for i, path := range schema.Columns() {
+		positions[path[0]] = i
+	}
+	index := make([]int, len(columns))
+	for i, col := range columns {
+		index[i] = positions[col.Name]
+	}
+
+	config, err := parquet.NewWriterConfig(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Parquet writer: %w", err)
+	}
+
+	return &parquetExportWriter{
+		writer:  parquet.NewWriter(file, config),
+		columns: columns,
+		index:   index,
+		kinds:   kinds,
+		row:     make(parquet.Row, len(columns)),
+	}, nil
+}
+
+func (w *parquetExportWriter) WriteRow(row map[string]interface{}) error {
+	for i, col := range w.columns {
+		value, err := parquetValue(row[col.Name], w.kinds[i])
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		w.row[w.index[i]] = value
+	}
+	for i, value := range w.row {
+		if value.IsNull() {
+			w.row[i] = value.Level(0, 0, i)
+		} else {
+			w.row[i] = value.Level(0, 1, i)
+		}
+	}
+	_, err := w.writer.WriteRows([]parquet.Row{w.row})
+	return err
+}
+
+func (w *parquetExportWriter) Close() error {
+	return w.writer.Close()
+}
+
+// parquetKind maps an Edm type to the physical Parquet column kind
+func parquetKind(edmType string) string {
+	switch edmType {
+	case "Edm.Int16", "Edm.Int32", "Edm.Int64", "Edm.Byte", "Edm.SByte":
+		return "int"
+	case "Edm.Single", "Edm.Double", "Edm.Decimal":
+		return "double"
+	case "Edm.Boolean":
+		return "bool"
+	}
+	return "string"
+}
+
+// parquetValue converts a normalized value to a Parquet value of the given kind
+func parquetValue(value interface{}, kind string) (parquet.Value, error) {
+	if value == nil {
+		return parquet.NullValue(), nil
+	}
+
+	switch kind {
+	case "int":
+		switch v := value.(type) {
+		case float64:
+			return parquet.Int64Value(int64(v)), nil
+		case string:
+			// v2 sends Edm.Int64 as a string
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return parquet.Value{}, fmt.Errorf("cannot convert %q to an integer", v)
+			}
+			return parquet.Int64Value(n), nil
+		}
+	case "double":
+		if f, ok := toFloat(value); ok {
+			return parquet.DoubleValue(f), nil
+		}
+		return parquet.Value{}, fmt.Errorf("cannot convert %v to a number", value)
+	case "bool":
+		if v, ok := value.(bool); ok {
+			return parquet.BooleanValue(v), nil
+		}
+	}
+
+	return parquet.ByteArrayValue([]byte(formatExportCell(value))), nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+func exportTestRows() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"__metadata": map[string]interface{}{"type": "Product"}, "ProductID": 1, "ProductName": "Chai, tea", "Price": "18.000", "Released": "/Date(1704067200000)/"},
+		{"__metadata": map[string]interface{}{"type": "Product"}, "ProductID": 2, "ProductName": "Chang", "Price": nil, "Released": nil},
+		{"__metadata": map[string]interface{}{"type": "Product"}, "ProductID": 3, "ProductName": "Tofu", "Price": "2.5", "Released": "/Date(1701388800000)/"},
+	}
+}
+
+func newExportTestBridge(t *testing.T) (*ODataMCPBridge, string) {
+	t.Helper()
+	var queries []string
+	dir := t.TempDir()
+	bridge := newServiceTestBridge(t, &config.Config{ExportDir: dir}, aggregateTestHandler(exportTestRows(), &queries))
+	bridge.metadata.EntityTypes["Product"].Properties = append(bridge.metadata.EntityTypes["Product"].Properties,
+		&models.EntityProperty{Name: "Released", Type: "Edm.DateTime", Nullable: true})
+	return bridge, dir
+}
+
+type exportResult struct {
+	FilePath string                   `json:"file_path"`
+	RowCount int                      `json:"row_count"`
+	Columns  []string                 `json:"columns"`
+	Preview  []map[string]interface{} `json:"preview"`
+}
+
+func runExport(t *testing.T, bridge *ODataMCPBridge, args map[string]interface{}) exportResult {
+	t.Helper()
+	result, err := bridge.handleEntityExport(context.Background(), "Products", bridge.metadata.EntityTypes["Product"], args)
+	if err != nil {
+		t.Fatalf("handleEntityExport() error = %v", err)
+	}
+	var out exportResult
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+	return out
+}
+
+func TestHandleEntityExportCSV(t *testing.T) {
+	bridge, dir := newExportTestBridge(t)
+
+	out := runExport(t, bridge, map[string]interface{}{"file_name": "products"})
+
+	if out.FilePath != filepath.Join(dir, "products.csv") || out.RowCount != 3 || len(out.Preview) != 3 {
+		t.Errorf("unexpected export result: %+v", out)
+	}
+
+	data, err := os.ReadFile(out.FilePath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	want := "ProductID,ProductName,Price,Released\n" +
+		"1,\"Chai, tea\",18.000,2024-01-01T00:00:00Z\n" +
+		"2,Chang,,\n" +
+		"3,Tofu,2.5,2023-12-01T00:00:00Z\n"
+	if string(data) != want {
+		t.Errorf("CSV content =\n%s\nwant\n%s", data, want)
+	}
+
+	// Existing files are only replaced on request
+	if _, err := bridge.handleEntityExport(context.Background(), "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{"file_name": "products.csv"}); err == nil {
+		t.Error("expected an error when the export file already exists")
+	}
+	runExport(t, bridge, map[string]interface{}{"file_name": "products.csv", "overwrite": true})
+}
+
+func TestHandleEntityExportJSONL(t *testing.T) {
+	bridge, _ := newExportTestBridge(t)
+
+	out := runExport(t, bridge, map[string]interface{}{"format": "jsonl", "$top": float64(2)})
+
+	data, err := os.ReadFile(out.FilePath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || out.RowCount != 2 {
+		t.Fatalf("got %d lines (row_count %d), want 2", len(lines), out.RowCount)
+	}
+	if strings.Contains(lines[0], "__metadata") || !strings.Contains(lines[0], `"Released":"2024-01-01T00:00:00Z"`) || !strings.Contains(lines[0], `"Price":"18.000"`) {
+		t.Errorf("unexpected JSONL line: %s", lines[0])
+	}
+}
+
+func TestHandleEntityExportParquet(t *testing.T) {
+	bridge, _ := newExportTestBridge(t)
+
+	out := runExport(t, bridge, map[string]interface{}{"format": "parquet", "$select": "ProductName,Price"})
+
+	type productRow struct {
+		ProductName *string  `parquet:"ProductName,optional"`
+		Price       *float64 `parquet:"Price,optional"`
+	}
+
+	file, err := os.Open(out.FilePath)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	defer file.Close()
+	info, _ := file.Stat()
+
+	rows, err := parquet.Read[productRow](file, info.Size())
+	if err != nil {
+		t.Fatalf("failed to read Parquet export: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if *rows[0].ProductName != "Chai, tea" || *rows[0].Price != 18.0 || rows[1].Price != nil {
+		t.Errorf("unexpected Parquet rows: %+v %+v", rows[0], rows[1])
+	}
+}
+
+func TestResolveExportPath(t *testing.T) {
+	dir := t.TempDir()
+	bridge := createTestBridge(&config.Config{ExportDir: dir})
+
+	realDir, _ := filepath.EvalSymlinks(dir)
+	if path, err := bridge.resolveExportPath("reports/q1.csv"); err != nil || path != filepath.Join(realDir, "reports", "q1.csv") {
+		t.Errorf("resolveExportPath(subdir) = %q, %v", path, err)
+	}
+
+	for _, name := range []string{"../escape.csv", "reports/../../escape.csv", "/etc/passwd", "."} {
+		if _, err := bridge.resolveExportPath(name); err == nil {
+			t.Errorf("resolveExportPath(%q) should be rejected", name)
+		}
+	}
+
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err == nil {
+		if _, err := bridge.resolveExportPath("link/escape.csv"); err == nil {
+			t.Error("resolveExportPath() should reject symlinks leading outside the export directory")
+		}
+		// Rejected names must not create directories behind the symlink either
+		if _, err := bridge.resolveExportPath("link/a/b/escape.csv"); err == nil {
+			t.Error("resolveExportPath() should reject subdirectories of a symlink leading outside")
+		}
+		if _, err := os.Stat(filepath.Join(outside, "a")); !os.IsNotExist(err) {
+			t.Errorf("a directory was created outside the export directory: %v", err)
+		}
+	}
+}
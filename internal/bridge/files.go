@@ -0,0 +1,92 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolvePathInDir maps a file name to an absolute path inside baseDir, rejecting
+// absolute paths, parent traversal and symlinks that lead outside it. For writes
+// the directory (and any subdirectory in the name) is created and the file itself
+// must not be a symlink; for reads the file must exist.
+func resolvePathInDir(baseDir, fileName string, forWrite bool) (string, error) {
+	dir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid directory %s: %w", baseDir, err)
+	}
+	if forWrite {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+
+	if filepath.IsAbs(fileName) || filepath.VolumeName(fileName) != "" {
+		return "", fmt.Errorf("invalid file name %q: must be relative to %s", fileName, baseDir)
+	}
+
+	path := filepath.Join(dir, fileName)
+	if !isWithinDir(dir, path) || path == dir {
+		return "", fmt.Errorf("invalid file name %q: must stay inside %s", fileName, baseDir)
+	}
+
+	if !forWrite {
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return "", fmt.Errorf("file not found: %s", fileName)
+		}
+		if !isWithinDir(dir, real) {
+			return "", fmt.Errorf("invalid file name %q: must stay inside %s", fileName, baseDir)
+		}
+		return real, nil
+	}
+
+	// Check the part of the parent that exists before creating the rest, so a symlinked
+	// subdirectory cannot get directories created outside dir
+	parent := filepath.Dir(path)
+	existing := parent
+	for existing != dir {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	realExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil || !isWithinDir(dir, realExisting) {
+		return "", fmt.Errorf("invalid file name %q: must stay inside %s", fileName, baseDir)
+	}
+	rest, err := filepath.Rel(existing, parent)
+	if err != nil {
+		return "", fmt.Errorf("invalid file name %q: must stay inside %s", fileName, baseDir)
+	}
+	realParent := filepath.Join(realExisting, rest)
+	if err := os.MkdirAll(realParent, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if real, err := filepath.EvalSymlinks(realParent); err != nil || !isWithinDir(dir, real) {
+		return "", fmt.Errorf("invalid file name %q: must stay inside %s", fileName, baseDir)
+	}
+	path = filepath.Join(realParent, filepath.Base(path))
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("invalid file name %q: refusing to write through a symlink", fileName)
+	}
+
+	return path, nil
+}
+
+// isWithinDir reports whether path is dir or lies below it
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
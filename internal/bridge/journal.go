@@ -0,0 +1,534 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/client"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/journal"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+// defaultListChangesLimit is how many changes list_changes returns by default
+const defaultListChangesLimit = 20
+
+// journaling checks whether writes in this call are recorded in the change journal.
+// Dry runs change nothing and are not journaled.
+func (b *ODataMCPBridge) journaling(ctx context.Context) bool {
+	return b.journal != nil && client.DryRunFromContext(ctx) == nil
+}
+
+// snapshotEntity reads an entity as the journal stores it: without OData metadata or
+// deferred navigation properties, together with its ETag
+func (b *ODataMCPBridge) snapshotEntity(ctx context.Context, entitySetName string, key map[string]interface{}) (map[string]interface{}, string, error) {
+	response, err := b.client.GetEntity(ctx, entitySetName, key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	entity, ok := cleanEntity(response.Value)
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected response for %s", formatEntityTarget(entitySetName, key))
+	}
+	return entity, entityETag(response), nil
+}
+
+// cleanEntity copies an entity without metadata and deferred navigation properties
+func cleanEntity(value interface{}) (map[string]interface{}, bool) {
+	entity, ok := stripExportMetadata(value).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for name, value := range entity {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if _, deferred := nested["__deferred"]; deferred {
+				delete(entity, name)
+			}
+		}
+	}
+	return entity, true
+}
+
+// entityETag returns the ETag of a single-entity response: the ETag header, or the
+// etag in v2 __metadata or the v4 @odata.etag annotation
+func entityETag(response *models.ODataResponse) string {
+	if response.ETag != "" {
+		return response.ETag
+	}
+	entity, _ := response.Value.(map[string]interface{})
+	if etag, ok := entity["@odata.etag"].(string); ok {
+		return etag
+	}
+	if meta, ok := entity["__metadata"].(map[string]interface{}); ok {
+		if etag, ok := meta["etag"].(string); ok {
+			return etag
+		}
+	}
+	return ""
+}
+
+// entityKeyFrom extracts the key of an entity set's entity from its properties
+func (b *ODataMCPBridge) entityKeyFrom(entitySetName string, entity map[string]interface{}) map[string]interface{} {
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil || len(entityType.KeyProperties) == 0 {
+		return nil
+	}
+	key := make(map[string]interface{})
+	for _, name := range entityType.KeyProperties {
+		value, ok := entity[name]
+		if !ok || value == nil {
+			return nil
+		}
+		key[name] = value
+	}
+	return key
+}
+
+// callerIdentity identifies the authenticated caller as method:subject, since an API key
+// may carry the same name as an OAuth subject; it is "" without authentication
+func callerIdentity(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return principal.Method + ":" + principal.Subject
+	}
+	return ""
+}
+
+// recordChange appends a change made by the caller to the journal. The write already
+// happened, so a journal failure is reported but does not fail the call.
+func (b *ODataMCPBridge) recordChange(ctx context.Context, c *journal.Change) {
+	c.Caller = callerIdentity(ctx)
+	if err := b.journal.Record(c); err != nil {
+		logger.WarnContext(ctx, "Failed to record change", "error", err)
+	}
+}
+
+// journalCreate records a created entity from the create response
+func (b *ODataMCPBridge) journalCreate(ctx context.Context, entitySetName string, response *models.ODataResponse) {
+	change := &journal.Change{Operation: constants.OpCreate, EntitySet: entitySetName}
+	if after, ok := cleanEntity(response.Value); ok {
+		change.After = after
+		change.AfterETag = entityETag(response)
+		change.Key = b.entityKeyFrom(entitySetName, after)
+	}
+	b.recordChange(ctx, change)
+}
+
+// journalUpdate records an update; the state after it is read back, since most services
+// answer updates without a body
+func (b *ODataMCPBridge) journalUpdate(ctx context.Context, entitySetName string, key, before map[string]interface{}, beforeETag string, data map[string]interface{}) {
+	change := &journal.Change{
+		Operation:  constants.OpUpdate,
+		EntitySet:  entitySetName,
+		Key:        key,
+		Before:     before,
+		BeforeETag: beforeETag,
+	}
+
+	changed := make(map[string]bool)
+	for name := range data {
+		changed[name] = true
+	}
+	after, afterETag, err := b.snapshotEntity(ctx, entitySetName, key)
+	if err != nil {
+		// Fall back to what was sent; without an ETag, undo compares values instead
+		after = make(map[string]interface{}, len(before))
+		for name, value := range before {
+			after[name] = value
+		}
+		for name, value := range data {
+			after[name] = value
+		}
+	} else {
+		// PUT resets unlisted properties, so anything that differs counts as changed
+		for name, value := range after {
+			if formatConfirmValue(before[name]) != formatConfirmValue(value) {
+				changed[name] = true
+			}
+		}
+		change.AfterETag = afterETag
+	}
+	change.After = after
+	for name := range changed {
+		change.Changed = append(change.Changed, name)
+	}
+	sort.Strings(change.Changed)
+	b.recordChange(ctx, change)
+}
+
+// generateJournalTools creates the list_changes and undo_change tools used in both eager and lazy mode
+func (b *ODataMCPBridge) generateJournalTools() {
+	listName := b.formatToolName("list_changes", "")
+	listTool := &mcp.Tool{
+		Name:        listName,
+		Description: "List recent creates, updates and deletes made through this server, newest first, with the values before and after each change",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"entity_set": map[string]interface{}{
+					"type":        "string",
+					"description": "Only list changes to this entity set",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of changes to return (default: %d)", defaultListChangesLimit),
+				},
+				"include_undone": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list changes that were already undone",
+					"default":     false,
+				},
+			},
+		},
+	}
+	b.server.AddTool(listTool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleListChanges(ctx, args)
+	})
+	b.tools[listName] = &models.ToolInfo{
+		Name:        listName,
+		Description: listTool.Description,
+		Operation:   constants.OpInfo,
+	}
+
+	undoName := b.formatToolName("undo_change", "")
+	properties := map[string]interface{}{
+		"change_id": map[string]interface{}{
+			"type":        "integer",
+			"description": "ID of the change from list_changes",
+		},
+	}
+	description := "Undo a change from list_changes: restore the previous values of an update, re-create a deleted entity or delete a created one. Refused if the entity was changed again since"
+	for _, op := range []string{constants.ConfirmUpdate, constants.ConfirmDelete} {
+		if b.config.RequiresConfirmation(op) {
+			description = b.applyConfirmPolicy(op, description, properties)
+			break
+		}
+	}
+	undoTool := &mcp.Tool{
+		Name:        undoName,
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"change_id"},
+		},
+	}
+	b.server.AddTool(undoTool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return b.handleUndoChange(ctx, args)
+	})
+	b.tools[undoName] = &models.ToolInfo{
+		Name:        undoName,
+		Description: description,
+		Operation:   constants.OpUndo,
+	}
+}
+
+func (b *ODataMCPBridge) handleListChanges(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	entitySetName, _ := args["entity_set"].(string)
+	includeUndone, _ := args["include_undone"].(bool)
+	limit := defaultListChangesLimit
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	changes := make([]map[string]interface{}, 0)
+	for _, c := range b.journal.List(callerIdentity(ctx), entitySetName, includeUndone, 0) {
+		if len(changes) >= limit {
+			break
+		}
+		if !b.policy.AllowsEntitySet(c.EntitySet) {
+			continue
+		}
+		changes = append(changes, b.describeChange(c))
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"changes": changes,
+		"count":   len(changes),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+	return string(result), nil
+}
+
+// describeChange summarizes a journaled change, showing only properties the policy lets the caller read
+func (b *ODataMCPBridge) describeChange(c *journal.Change) map[string]interface{} {
+	rule := b.policy.EntitySet(c.EntitySet)
+	summary := map[string]interface{}{
+		"id":        c.ID,
+		"time":      c.Time,
+		"operation": c.Operation,
+		"can_undo":  c.UndoneAt == "" && len(c.Key) > 0,
+	}
+	if len(c.Key) > 0 {
+		summary["target"] = formatEntityTarget(c.EntitySet, c.Key)
+	} else {
+		summary["target"] = c.EntitySet
+		summary["note"] = "The created entity's key is unknown; this change cannot be undone"
+	}
+	if c.UndoneAt != "" {
+		summary["undone_at"] = c.UndoneAt
+	}
+
+	switch c.Operation {
+	case constants.OpCreate:
+		summary["entity"] = b.readableEntity(c.EntitySet, c.After)
+	case constants.OpDelete:
+		summary["entity"] = b.readableEntity(c.EntitySet, c.Before)
+	case constants.OpUpdate:
+		changes := make(map[string]interface{})
+		for _, name := range c.Changed {
+			if rule.AllowsProperty(policy.UsageSelect, name) {
+				changes[name] = map[string]interface{}{"from": c.Before[name], "to": c.After[name]}
+			}
+		}
+		summary["changes"] = changes
+	}
+	return summary
+}
+
+func (b *ODataMCPBridge) handleUndoChange(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	ctx = b.withCallOptions(ctx, args)
+	if client.DryRunFromContext(ctx) != nil {
+		return nil, fmt.Errorf("undo_change is not available in dry-run mode")
+	}
+
+	id, err := changeIDArg(args["change_id"])
+	if err != nil {
+		return nil, err
+	}
+	// Changes of other callers are not theirs to undo, nor to know about
+	c := b.journal.Get(id)
+	if c == nil || c.Caller != callerIdentity(ctx) {
+		return nil, fmt.Errorf("change %d not found", id)
+	}
+	if c.UndoneAt != "" {
+		return nil, fmt.Errorf("change %d was already undone at %s", id, c.UndoneAt)
+	}
+	if len(c.Key) == 0 {
+		return nil, fmt.Errorf("change %d cannot be undone: the key of the created entity is unknown", id)
+	}
+	target := formatEntityTarget(c.EntitySet, c.Key)
+
+	var action string
+	switch c.Operation {
+	case constants.OpCreate:
+		pending, err := b.undoCreate(ctx, c, target)
+		if pending != nil || err != nil {
+			return pending, err
+		}
+		action = "deleted"
+	case constants.OpUpdate:
+		pending, err := b.undoUpdate(ctx, c, target)
+		if pending != nil || err != nil {
+			return pending, err
+		}
+		action = "restored"
+	case constants.OpDelete:
+		if err := b.undoDelete(ctx, c, target); err != nil {
+			return nil, err
+		}
+		action = "re-created"
+	default:
+		return nil, fmt.Errorf("change %d has unknown operation %s", id, c.Operation)
+	}
+
+	if err := b.journal.MarkUndone(id); err != nil {
+		logger.WarnContext(ctx, "Failed to mark change undone", "change", id, "error", err)
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"status":  "success",
+		"message": fmt.Sprintf("Change %d undone: %s %s", id, target, action),
+		"undone":  id,
+		"target":  target,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+	return string(result), nil
+}
+
+// undoCreate deletes a created entity, if it is still as it was created
+func (b *ODataMCPBridge) undoCreate(ctx context.Context, c *journal.Change, target string) (interface{}, error) {
+	if !b.config.IsOperationEnabled('D') {
+		return nil, fmt.Errorf("cannot undo change %d: delete operations are disabled", c.ID)
+	}
+	if err := b.checkPolicyOperation(c.EntitySet, constants.OpDelete); err != nil {
+		return nil, err
+	}
+
+	current, etag, err := b.snapshotEntity(ctx, c.EntitySet, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot undo change %d: %s could not be read: %w", c.ID, target, err)
+	}
+	if err := b.changedSince(c, c.After, c.AfterETag, current, etag, nil); err != nil {
+		return nil, err
+	}
+
+	if b.needsConfirmation(ctx, constants.ConfirmDelete) {
+		if pending, err := b.confirmWrite(ctx, b.describeDelete(ctx, c.EntitySet, c.Key)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
+	if _, err := b.client.DeleteEntity(client.WithIfMatch(ctx, etag), c.EntitySet, c.Key); err != nil {
+		return nil, undoWriteError(c, target, err)
+	}
+	return nil, nil
+}
+
+// undoUpdate writes back the previous values of the updated properties, if none of them changed since
+func (b *ODataMCPBridge) undoUpdate(ctx context.Context, c *journal.Change, target string) (interface{}, error) {
+	if !b.config.IsOperationEnabled('U') {
+		return nil, fmt.Errorf("cannot undo change %d: update operations are disabled", c.ID)
+	}
+
+	restore := make(map[string]interface{}, len(c.Changed))
+	for _, name := range c.Changed {
+		if value, ok := c.Before[name]; ok {
+			restore[name] = value
+		}
+	}
+	if len(restore) == 0 {
+		return nil, fmt.Errorf("cannot undo change %d: no previous values were recorded", c.ID)
+	}
+	if err := b.checkWritePolicy(c.EntitySet, constants.OpUpdate, restore); err != nil {
+		return nil, err
+	}
+
+	current, etag, err := b.snapshotEntity(ctx, c.EntitySet, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot undo change %d: %s could not be read: %w", c.ID, target, err)
+	}
+	if err := b.changedSince(c, c.After, c.AfterETag, current, etag, c.Changed); err != nil {
+		return nil, err
+	}
+
+	// A partial update leaves properties outside the change alone
+	method := constants.MERGE
+	if strings.HasPrefix(b.metadata.Version, "4") {
+		method = constants.PATCH
+	}
+
+	if b.needsConfirmation(ctx, constants.ConfirmUpdate) {
+		if pending, err := b.confirmWrite(ctx, b.describeUpdate(ctx, c.EntitySet, c.Key, restore, method)); pending != nil || err != nil {
+			return pending, err
+		}
+	}
+
+	if _, err := b.client.UpdateEntity(client.WithIfMatch(ctx, etag), c.EntitySet, c.Key, restore, method); err != nil {
+		return nil, undoWriteError(c, target, err)
+	}
+	return nil, nil
+}
+
+// undoDelete re-creates a deleted entity from its snapshot, if nothing took its key since
+func (b *ODataMCPBridge) undoDelete(ctx context.Context, c *journal.Change, target string) error {
+	if !b.config.IsOperationEnabled('C') {
+		return fmt.Errorf("cannot undo change %d: create operations are disabled", c.ID)
+	}
+	// Only a 404 shows the entity is gone; other failures say nothing about it
+	_, _, err := b.snapshotEntity(ctx, c.EntitySet, c.Key)
+	var svcErr *client.ServiceError
+	switch {
+	case err == nil:
+		return fmt.Errorf("cannot undo change %d: %s exists again", c.ID, target)
+	case !errors.As(err, &svcErr) || svcErr.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("cannot undo change %d: %s could not be read: %w", c.ID, target, err)
+	}
+
+	// Only send declared properties; navigation and computed extras would be rejected
+	data := make(map[string]interface{})
+	if entityType := b.entityTypeOf(c.EntitySet); entityType != nil {
+		for _, prop := range entityType.Properties {
+			if value, ok := c.Before[prop.Name]; ok && value != nil {
+				data[prop.Name] = value
+			}
+		}
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("cannot undo change %d: the deleted entity was not recorded", c.ID)
+	}
+	if err := b.checkWritePolicy(c.EntitySet, constants.OpCreate, data); err != nil {
+		return err
+	}
+
+	if _, err := b.client.CreateEntity(ctx, c.EntitySet, data); err != nil {
+		return undoWriteError(c, target, err)
+	}
+	return nil
+}
+
+// changedSince refuses an undo when the entity was modified after the journaled change:
+// by ETag when both are known, otherwise by comparing the given properties (all if nil).
+// Properties hidden from reads by the access policy are counted, not named.
+func (b *ODataMCPBridge) changedSince(c *journal.Change, expected map[string]interface{}, expectedETag string, current map[string]interface{}, currentETag string, properties []string) error {
+	target := formatEntityTarget(c.EntitySet, c.Key)
+	if expectedETag != "" && currentETag != "" {
+		if expectedETag != currentETag {
+			return fmt.Errorf("cannot undo change %d: %s was modified since (ETag %s, expected %s)", c.ID, target, currentETag, expectedETag)
+		}
+		return nil
+	}
+
+	if properties == nil {
+		for name := range expected {
+			properties = append(properties, name)
+		}
+	}
+	rule := b.policy.EntitySet(c.EntitySet)
+	var modified []string
+	hidden := 0
+	for _, name := range properties {
+		if formatConfirmValue(current[name]) != formatConfirmValue(expected[name]) {
+			if rule.AllowsProperty(policy.UsageSelect, name) {
+				modified = append(modified, name)
+			} else {
+				hidden++
+			}
+		}
+	}
+	sort.Strings(modified)
+	if hidden > 0 {
+		modified = append(modified, fmt.Sprintf("%d hidden propert%s", hidden, plural(hidden, "y", "ies")))
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("cannot undo change %d: %s was modified since (%s)", c.ID, target, strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// undoWriteError explains a failed undo write, calling out concurrent modifications
+func undoWriteError(c *journal.Change, target string, err error) error {
+	if errors.Is(err, client.ErrPreconditionFailed) {
+		return fmt.Errorf("cannot undo change %d: %s was modified concurrently: %w", c.ID, target, err)
+	}
+	return fmt.Errorf("failed to undo change %d: %w", c.ID, err)
+}
+
+// changeIDArg reads the change_id argument, which clients may send as a number or a string
+func changeIDArg(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err == nil {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("change_id must be the numeric ID of a change from list_changes")
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/journal"
+)
+
+// productStore is a minimal OData v2 Products service with ETags
+type productStore struct {
+	mu       sync.Mutex
+	products map[string]map[string]interface{} // By key predicate
+	etags    map[string]int
+	ifMatch  []string // If-Match headers of writes
+	denyGets bool     // Answer reads with 403
+}
+
+func (s *productStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-CSRF-Token", "token")
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" {
+		w.Write([]byte(`{"d":{"EntitySets":["Products"]}}`))
+		return
+	}
+	if r.Method == http.MethodPost && path == "Products" {
+		var data map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&data)
+		id := fmt.Sprint(data["ProductID"])
+		if _, exists := s.products[id]; exists {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.products[id] = data
+		s.etags[id]++
+		w.WriteHeader(http.StatusCreated)
+		s.writeEntity(w, id)
+		return
+	}
+
+	if r.Method == http.MethodGet && s.denyGets {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(path, "Products("), ")")
+	entity, exists := s.products[id]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"NF","message":{"value":"Resource not found"}}}`))
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.writeEntity(w, id)
+		return
+	}
+
+	if m := r.Header.Get("If-Match"); m != "" {
+		s.ifMatch = append(s.ifMatch, m)
+		if m != s.etag(id) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+	}
+	switch r.Method {
+	case http.MethodDelete:
+		delete(s.products, id)
+	default:
+		body, _ := io.ReadAll(r.Body)
+		var data map[string]interface{}
+		json.Unmarshal(body, &data)
+		for name, value := range data {
+			entity[name] = value
+		}
+		s.etags[id]++
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *productStore) etag(id string) string {
+	return fmt.Sprintf(`W/"%d"`, s.etags[id])
+}
+
+func (s *productStore) writeEntity(w http.ResponseWriter, id string) {
+	entity := map[string]interface{}{"__metadata": map[string]interface{}{"etag": s.etag(id)}}
+	for name, value := range s.products[id] {
+		entity[name] = value
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"d": entity})
+}
+
+// modify changes a product behind the bridge's back
+func (s *productStore) modify(id, name string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.products[id][name] = value
+	s.etags[id]++
+}
+
+func (s *productStore) get(id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.products[id]
+}
+
+func TestChangeJournalUndo(t *testing.T) {
+	store := &productStore{
+		products: map[string]map[string]interface{}{
+			"7": {"ProductID": float64(7), "ProductName": "Chai", "Price": "18.00"},
+		},
+		etags: map[string]int{"7": 1},
+	}
+
+	j, err := journal.Open(filepath.Join(t.TempDir(), "changes.jsonl"))
+	if err != nil {
+		t.Fatalf("journal.Open() error = %v", err)
+	}
+	defer j.Close()
+	bridge := newServiceTestBridge(t, &config.Config{}, store)
+	bridge.journal = j
+	entityType := bridge.metadata.EntityTypes["Product"]
+	ctx := context.Background()
+
+	undo := func(id int) (interface{}, error) {
+		return bridge.handleUndoChange(ctx, map[string]interface{}{"change_id": float64(id)})
+	}
+
+	t.Run("update", func(t *testing.T) {
+		if _, err := bridge.handleEntityUpdate(ctx, "Products", entityType, map[string]interface{}{"ProductID": float64(7), "Price": 20, "_method": "MERGE"}); err != nil {
+			t.Fatalf("update error = %v", err)
+		}
+		listed, err := bridge.handleListChanges(ctx, map[string]interface{}{})
+		if err != nil || !strings.Contains(listed.(string), `"changes":{"Price":{"from":"18.00","to":"20"}}`) {
+			t.Fatalf("list_changes = %v, %v", listed, err)
+		}
+
+		if _, err := undo(1); err != nil {
+			t.Fatalf("undo error = %v", err)
+		}
+		if price := store.get("7")["Price"]; price != "18.00" {
+			t.Errorf("Price = %v, want restored 18.00", price)
+		}
+		if last := store.ifMatch[len(store.ifMatch)-1]; last != `W/"2"` {
+			t.Errorf("undo sent If-Match %s, want the current ETag", last)
+		}
+		if _, err := undo(1); err == nil || !strings.Contains(err.Error(), "already undone") {
+			t.Errorf("second undo error = %v", err)
+		}
+	})
+
+	t.Run("refused after a newer change", func(t *testing.T) {
+		bridge.handleEntityUpdate(ctx, "Products", entityType, map[string]interface{}{"ProductID": float64(7), "ProductName": "Chai Tea", "_method": "MERGE"})
+		store.modify("7", "ProductName", "Someone else's name")
+
+		if _, err := undo(2); err == nil || !strings.Contains(err.Error(), "was modified since") {
+			t.Fatalf("undo error = %v, want a conflict", err)
+		}
+		if name := store.get("7")["ProductName"]; name != "Someone else's name" {
+			t.Errorf("newer change was overwritten: %v", name)
+		}
+	})
+
+	t.Run("delete and create", func(t *testing.T) {
+		if _, err := bridge.handleEntityDelete(ctx, "Products", entityType, map[string]interface{}{"ProductID": float64(7)}); err != nil {
+			t.Fatalf("delete error = %v", err)
+		}
+		if _, err := undo(3); err != nil {
+			t.Fatalf("undo delete error = %v", err)
+		}
+		if p := store.get("7"); p == nil || p["ProductName"] != "Someone else's name" {
+			t.Errorf("entity not re-created: %v", p)
+		}
+
+		if _, err := bridge.handleEntityCreate(ctx, "Products", map[string]interface{}{"ProductID": 9, "ProductName": "Chang"}); err != nil {
+			t.Fatalf("create error = %v", err)
+		}
+		if _, err := undo(4); err != nil {
+			t.Fatalf("undo create error = %v", err)
+		}
+		if p := store.get("9"); p != nil {
+			t.Errorf("created entity not deleted: %v", p)
+		}
+	})
+
+	t.Run("changes belong to their caller", func(t *testing.T) {
+		alice := auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodOAuth})
+		if _, err := bridge.handleEntityUpdate(alice, "Products", entityType, map[string]interface{}{"ProductID": float64(7), "Price": 25, "_method": "MERGE"}); err != nil {
+			t.Fatalf("update error = %v", err)
+		}
+
+		listed, _ := bridge.handleListChanges(ctx, map[string]interface{}{"include_undone": true})
+		if strings.Contains(listed.(string), `"id":5`) {
+			t.Errorf("another caller's change was listed: %s", listed)
+		}
+		listed, _ = bridge.handleListChanges(alice, map[string]interface{}{"include_undone": true})
+		if !strings.Contains(listed.(string), `"id":5`) || !strings.Contains(listed.(string), `"count":1`) {
+			t.Errorf("list_changes for alice = %s", listed)
+		}
+		if _, err := undo(5); err == nil || !strings.Contains(err.Error(), "change 5 not found") {
+			t.Errorf("undo of another caller's change: error = %v", err)
+		}
+
+		// An API key named like the OAuth subject is a different caller
+		aliceKey := auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodAPIKey})
+		listed, _ = bridge.handleListChanges(aliceKey, map[string]interface{}{"include_undone": true})
+		if !strings.Contains(listed.(string), `"count":0`) {
+			t.Errorf("list_changes for the alice API key = %s", listed)
+		}
+		if _, err := bridge.handleUndoChange(aliceKey, map[string]interface{}{"change_id": float64(5)}); err == nil || !strings.Contains(err.Error(), "change 5 not found") {
+			t.Errorf("undo by the alice API key: error = %v", err)
+		}
+		if price := store.get("7")["Price"]; price != float64(25) && price != "25" {
+			t.Errorf("Price = %v, want alice's 25", price)
+		}
+	})
+
+	t.Run("delete undo needs a 404", func(t *testing.T) {
+		alice := auth.WithPrincipal(ctx, &auth.Principal{Subject: "alice", Method: auth.MethodOAuth})
+		if _, err := bridge.handleEntityDelete(alice, "Products", entityType, map[string]interface{}{"ProductID": float64(7)}); err != nil {
+			t.Fatalf("delete error = %v", err)
+		}
+
+		// A failed read does not show the entity is gone
+		store.mu.Lock()
+		store.denyGets = true
+		store.mu.Unlock()
+		_, err := bridge.handleUndoChange(alice, map[string]interface{}{"change_id": float64(6)})
+		if err == nil || !strings.Contains(err.Error(), "could not be read") {
+			t.Errorf("undo error = %v, want a read failure", err)
+		}
+		if p := store.get("7"); p != nil {
+			t.Errorf("entity re-created without a 404: %v", p)
+		}
+
+		store.mu.Lock()
+		store.denyGets = false
+		store.mu.Unlock()
+		if _, err := bridge.handleUndoChange(alice, map[string]interface{}{"change_id": float64(6)}); err != nil {
+			t.Fatalf("undo delete error = %v", err)
+		}
+	})
+}
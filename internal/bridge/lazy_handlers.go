@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
 )
 
 // validateEntitySet validates that an entity set exists and is allowed by filters
@@ -23,6 +24,9 @@ func (b *ODataMCPBridge) validateEntitySet(entitySet string) (*models.EntitySet,
 	if !b.shouldIncludeEntity(entitySet) {
 		return nil, nil, fmt.Errorf("entity set not allowed: %s (restricted by --entities filter)", entitySet)
 	}
+	if !b.policy.AllowsEntitySet(entitySet) {
+		return nil, nil, fmt.Errorf("entity set not allowed: %s (restricted by access policy)", entitySet)
+	}
 
 	// Get the entity type
 	et, exists := b.metadata.EntityTypes[es.EntityType]
@@ -157,14 +161,27 @@ func (b *ODataMCPBridge) handleLazyGetEntitySchema(ctx context.Context, args map
 	}
 
 	// Add property details
+	rule := b.policy.EntitySet(entitySet)
 	properties := make([]map[string]interface{}, 0, len(et.Properties))
 	for _, prop := range et.Properties {
+		readable := rule.AllowsProperty(policy.UsageSelect, prop.Name)
+		filterable := rule.AllowsProperty(policy.UsageFilter, prop.Name)
+		writable := rule.AllowsProperty(policy.UsageWrite, prop.Name)
+		if !readable && !filterable && !writable && !prop.IsKey {
+			// Hidden entirely by the access policy
+			continue
+		}
 		propSchema := map[string]interface{}{
 			"name":     prop.Name,
 			"type":     prop.Type,
 			"nullable": prop.Nullable,
 			"is_key":   prop.IsKey,
 		}
+		if rule != nil {
+			propSchema["readable"] = readable
+			propSchema["filterable"] = filterable
+			propSchema["writable"] = writable
+		}
 		if prop.Description != nil {
 			propSchema["description"] = *prop.Description
 		}
@@ -200,6 +217,20 @@ func (b *ODataMCPBridge) handleLazyGetEntitySchema(ctx context.Context, args map
 		schema["navigation_properties"] = navProps
 	}
 
+	if rule != nil {
+		access := map[string]interface{}{}
+		if len(rule.Operations) > 0 {
+			access["operations"] = rule.Operations
+		}
+		if mandatory := rule.MandatoryFilter(); mandatory != "" {
+			access["mandatory_filter"] = mandatory
+		}
+		if rule.MaxTop > 0 {
+			access["max_top"] = rule.MaxTop
+		}
+		schema["access_policy"] = access
+	}
+
 	// Format response as JSON string
 	result, err := json.Marshal(schema)
 	if err != nil {
@@ -211,6 +242,9 @@ func (b *ODataMCPBridge) handleLazyGetEntitySchema(ctx context.Context, args map
 
 // handleLazyCreateEntity handles lazy mode create operations
 func (b *ODataMCPBridge) handleLazyCreateEntity(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Resolve dry_run and confirm_token here; the delegated handler only sees the payload
+	ctx = b.withCallOptions(ctx, args)
+
 	// Extract entity_set parameter
 	entitySet, ok := args["entity_set"].(string)
 	if !ok || entitySet == "" {
@@ -245,6 +279,9 @@ func (b *ODataMCPBridge) handleLazyCreateEntity(ctx context.Context, args map[st
 
 // handleLazyUpdateEntity handles lazy mode update operations
 func (b *ODataMCPBridge) handleLazyUpdateEntity(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Resolve dry_run and confirm_token here; the delegated handler only sees the payload
+	ctx = b.withCallOptions(ctx, args)
+
 	// Extract entity_set parameter
 	entitySet, ok := args["entity_set"].(string)
 	if !ok || entitySet == "" {
@@ -315,6 +352,9 @@ func (b *ODataMCPBridge) handleLazyUpdateEntity(ctx context.Context, args map[st
 
 // handleLazyDeleteEntity handles lazy mode delete operations
 func (b *ODataMCPBridge) handleLazyDeleteEntity(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Resolve dry_run and confirm_token here; the delegated handler only sees the payload
+	ctx = b.withCallOptions(ctx, args)
+
 	// Extract entity_set parameter
 	entitySet, ok := args["entity_set"].(string)
 	if !ok || entitySet == "" {
@@ -369,7 +409,7 @@ func (b *ODataMCPBridge) handleLazyListFunctions(ctx context.Context, args map[s
 
 	for name, fn := range b.metadata.FunctionImports {
 		// Skip functions that are filtered out
-		if !b.shouldIncludeFunction(name) {
+		if !b.shouldIncludeFunction(name) || !b.policy.AllowsFunction(name) {
 			continue
 		}
 
@@ -422,6 +462,9 @@ func (b *ODataMCPBridge) handleLazyListFunctions(ctx context.Context, args map[s
 
 // handleLazyCallFunction handles lazy mode function call operations
 func (b *ODataMCPBridge) handleLazyCallFunction(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	// Resolve dry_run and confirm_token here; the delegated handler only sees the payload
+	ctx = b.withCallOptions(ctx, args)
+
 	// Extract function_name parameter
 	functionName, ok := args["function_name"].(string)
 	if !ok || functionName == "" {
@@ -83,6 +83,32 @@ func (b *ODataMCPBridge) generateLazyTools() error {
 		}
 	}
 
+	// 11. Aggregate entities tool (filter operation - 'F')
+	if b.config.IsOperationEnabled('F') {
+		if err := b.generateLazyAggregateEntitiesTool(); err != nil {
+			return fmt.Errorf("failed to generate lazy aggregate entities tool: %w", err)
+		}
+	}
+
+	// 12. Export entities tool (filter operation - 'F', needs an export directory)
+	if b.config.ExportDir != "" && b.config.IsOperationEnabled('F') {
+		if err := b.generateLazyExportEntitiesTool(); err != nil {
+			return fmt.Errorf("failed to generate lazy export entities tool: %w", err)
+		}
+	}
+
+	// 13. Bulk import tool (create/update/delete operations, needs an import directory)
+	if len(b.bulkOperations()) > 0 {
+		if err := b.generateBulkTool(); err != nil {
+			return fmt.Errorf("failed to generate bulk import tool: %w", err)
+		}
+	}
+
+	// 14. Change journal tools (list_changes, undo_change)
+	if b.journal != nil && !b.config.IsReadOnly() {
+		b.generateJournalTools()
+	}
+
 	return nil
 }
 
@@ -127,7 +153,7 @@ func (b *ODataMCPBridge) generateLazyListEntitiesTool() error {
 
 	tool := &mcp.Tool{
 		Name:        toolName,
-		Description: "List/filter entities from any entity set with OData query options",
+		Description: "List/filter entities from any entity set with OData query options, or summarize them column by column",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -167,6 +193,10 @@ func (b *ODataMCPBridge) generateLazyListEntitiesTool() error {
 			"required": []string{"entity_set"},
 		},
 	}
+	properties := tool.InputSchema["properties"].(map[string]interface{})
+	for name, schema := range summaryInputProperties() {
+		properties[name] = schema
+	}
 
 	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return b.handleLazyListEntities(ctx, args)
@@ -323,6 +353,7 @@ func (b *ODataMCPBridge) generateLazyCreateEntityTool() error {
 					"type":        "object",
 					"description": "Entity data as a JSON object with property names and values",
 				},
+				"dry_run": dryRunInputProperty(),
 			},
 			"required": []string{"entity_set", "data"},
 		},
@@ -372,11 +403,14 @@ func (b *ODataMCPBridge) generateLazyUpdateEntityTool() error {
 					"enum":        []string{"PUT", "PATCH", "MERGE"},
 					"default":     "PUT",
 				},
+				"dry_run": dryRunInputProperty(),
 			},
 			"required": []string{"entity_set", "key", "data"},
 		},
 	}
 
+	tool.Description = b.applyConfirmPolicy(constants.ConfirmUpdate, tool.Description, tool.InputSchema["properties"].(map[string]interface{}))
+
 	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return b.handleLazyUpdateEntity(ctx, args)
 	}
@@ -411,11 +445,14 @@ func (b *ODataMCPBridge) generateLazyDeleteEntityTool() error {
 					"type":        "object",
 					"description": "Key properties and values as a JSON object (e.g., {\"ProductID\": 1})",
 				},
+				"dry_run": dryRunInputProperty(),
 			},
 			"required": []string{"entity_set", "key"},
 		},
 	}
 
+	tool.Description = b.applyConfirmPolicy(constants.ConfirmDelete, tool.Description, tool.InputSchema["properties"].(map[string]interface{}))
+
 	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return b.handleLazyDeleteEntity(ctx, args)
 	}
@@ -480,11 +517,14 @@ func (b *ODataMCPBridge) generateLazyCallFunctionTool() error {
 					"description": "Function parameters as a JSON object with parameter names and values",
 					"default":     map[string]interface{}{},
 				},
+				"dry_run": dryRunInputProperty(),
 			},
 			"required": []string{"function_name"},
 		},
 	}
 
+	tool.Description = b.applyConfirmPolicy(constants.ConfirmFunction, tool.Description, tool.InputSchema["properties"].(map[string]interface{}))
+
 	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return b.handleLazyCallFunction(ctx, args)
 	}
@@ -5,9 +5,12 @@ package bridge
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/zmcp/odata-mcp/internal/client"
 	"github.com/zmcp/odata-mcp/internal/config"
 	"github.com/zmcp/odata-mcp/internal/mcp"
 	"github.com/zmcp/odata-mcp/internal/models"
@@ -103,6 +106,22 @@ func createTestBridge(cfg *config.Config) *ODataMCPBridge {
 	return bridge
 }
 
+// newServiceTestBridge creates a test bridge whose client talks to an httptest server
+// running handler, which is also the service URL unless cfg names one. The server is
+// closed when the test ends.
+func newServiceTestBridge(t *testing.T, cfg *config.Config, handler http.Handler) *ODataMCPBridge {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if cfg.ServiceURL == "" {
+		cfg.ServiceURL = server.URL
+	}
+	bridge := createTestBridge(cfg)
+	bridge.client = client.NewODataClient(server.URL, false)
+	return bridge
+}
+
 func TestShouldUseLazyMode(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -184,7 +203,7 @@ func TestGenerateLazyTools(t *testing.T) {
 		t.Fatalf("generateLazyTools() error = %v", err)
 	}
 
-	// Check that exactly 10 tools were generated
+	// Check that exactly 11 tools were generated
 	expectedToolPrefixes := []string{
 		"odata_service_info",
 		"list_entities",
@@ -196,6 +215,7 @@ func TestGenerateLazyTools(t *testing.T) {
 		"delete_entity",
 		"list_functions",
 		"call_function",
+		"aggregate_entities",
 	}
 
 	if len(bridge.tools) != len(expectedToolPrefixes) {
@@ -238,6 +258,7 @@ func TestGenerateLazyToolsReadOnlyMode(t *testing.T) {
 		"get_entity_schema",
 		"list_functions",
 		"call_function",
+		"aggregate_entities",
 	}
 
 	mutatingPrefixes := []string{
@@ -668,6 +689,7 @@ func TestToolNamingWithPrefix(t *testing.T) {
 		"myservice_delete_entity",
 		"myservice_list_functions",
 		"myservice_call_function",
+		"myservice_aggregate_entities",
 	}
 
 	for _, expectedName := range expectedPrefixes {
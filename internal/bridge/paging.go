@@ -0,0 +1,84 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/progress"
+)
+
+// forEachPage reads an entity set page by page using $skip/$top and calls fn with
+// the rows of every page. It stops when the set is exhausted, when maxRows rows
+// have been delivered (truncated is then true if more rows follow), or when fn
+// returns an error.
+// The caller's options are copied; $top, $skip and the inline count are managed here,
+// with a caller-supplied $skip taken as the starting offset. Every page is reported as
+// progress of the request.
+func (b *ODataMCPBridge) forEachPage(ctx context.Context, entitySetName string, options map[string]string, maxRows int, fn func(rows []interface{}) error) (fetched int, truncated bool, err error) {
+	pageOptions := make(map[string]string, len(options)+3)
+	for k, v := range options {
+		pageOptions[k] = v
+	}
+	// Counting every page is wasted work on large sets
+	pageOptions[constants.QueryInlineCount] = "none"
+
+	offset := 0
+	if skip, ok := options[constants.QuerySkip]; ok {
+		if _, err := fmt.Sscanf(skip, "%d", &offset); err != nil || offset < 0 {
+			return 0, false, fmt.Errorf("invalid %s value: %s", constants.QuerySkip, skip)
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fetched, false, err
+		}
+
+		pageSize := constants.DefaultPageSize
+		if maxRows > 0 && maxRows-fetched < pageSize {
+			pageSize = maxRows - fetched
+		}
+		// The page that reaches maxRows asks for one row more, so a leftover row tells
+		// whether the cap cut anything off
+		top := pageSize
+		if maxRows > 0 && fetched+pageSize >= maxRows {
+			top++
+		}
+		pageOptions[constants.QueryTop] = fmt.Sprintf("%d", top)
+		pageOptions[constants.QuerySkip] = fmt.Sprintf("%d", offset+fetched)
+
+		response, err := b.client.GetEntitySet(ctx, entitySetName, pageOptions)
+		if err != nil {
+			return fetched, false, fmt.Errorf("failed to read %s at offset %d: %w", entitySetName, offset+fetched, err)
+		}
+
+		rows, _ := response.Value.([]interface{})
+		if len(rows) == 0 {
+			return fetched, false, nil
+		}
+		leftover := len(rows) > pageSize
+		if leftover {
+			// Some services ignore $top; never deliver more than asked for
+			rows = rows[:pageSize]
+		}
+
+		if err := fn(rows); err != nil {
+			return fetched, false, err
+		}
+		fetched += len(rows)
+		progress.Report(ctx, float64(fetched), 0, fmt.Sprintf("Read %d rows from %s", fetched, entitySetName))
+
+		// A short page without a next link means the set is exhausted; with a
+		// next link the server applied its own page size and more rows follow
+		if len(rows) < pageSize && response.NextLink == "" {
+			return fetched, false, nil
+		}
+		if maxRows > 0 && fetched >= maxRows {
+			return fetched, leftover || response.NextLink != "", nil
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/client"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+)
+
+// passthroughToolCall is the tool middleware that makes every OData request of a tool call
+// with the caller's own credentials. Calls without an authenticated caller are refused
+// rather than falling back to the configured technical user.
+func (b *ODataMCPBridge) passthroughToolCall(name string, next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		creds, err := b.passthrough.Credentials(ctx, auth.PrincipalFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return next(client.WithCredentials(ctx, creds), args)
+	}
+}
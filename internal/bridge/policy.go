@@ -0,0 +1,536 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+// entityTypeOf returns the entity type of an entity set, or nil if it is unknown
+func (b *ODataMCPBridge) entityTypeOf(entitySetName string) *models.EntityType {
+	if b.metadata == nil {
+		return nil
+	}
+	entitySet, exists := b.metadata.EntitySets[entitySetName]
+	if !exists {
+		return nil
+	}
+	return b.metadata.EntityTypes[entitySet.EntityType]
+}
+
+// checkPolicyOperation rejects operations the access policy does not allow on an entity set
+func (b *ODataMCPBridge) checkPolicyOperation(entitySetName, operation string) error {
+	if !b.policy.AllowsEntitySet(entitySetName) {
+		return fmt.Errorf("entity set not allowed: %s (restricted by access policy)", entitySetName)
+	}
+	if !b.policy.EntitySet(entitySetName).AllowsOperation(operation) {
+		return fmt.Errorf("operation %s on %s is not allowed by the access policy", operation, entitySetName)
+	}
+	return nil
+}
+
+// applyReadPolicy enforces the access policy on the query options of a read:
+// $select, $expand, $filter and $orderby may only use permitted properties, a
+// $select of the readable properties is added when some are hidden, mandatory
+// filters are ANDed to $filter and $top is capped by max_top.
+func (b *ODataMCPBridge) applyReadPolicy(entitySetName, operation string, options map[string]string) error {
+	if err := b.checkPolicyOperation(entitySetName, operation); err != nil {
+		return err
+	}
+	rule := b.policy.EntitySet(entitySetName)
+	if rule == nil {
+		return nil
+	}
+	entityType := b.entityTypeOf(entitySetName)
+
+	if rule.RestrictsProperties(policy.UsageSelect) {
+		if err := checkPolicyProperties(rule, policy.UsageSelect, entitySetName, splitPropertyList(options[constants.QuerySelect])); err != nil {
+			return err
+		}
+		if err := checkPolicyProperties(rule, policy.UsageSelect, entitySetName, splitPropertyList(options[constants.QueryExpand])); err != nil {
+			return err
+		}
+		if sel := strings.TrimSpace(options[constants.QuerySelect]); (sel == "" || sel == "*") && entityType != nil {
+			// Expanded navigation properties must stay selected or the service drops them
+			selected := append(readableProperties(rule, entityType), splitPropertyList(options[constants.QueryExpand])...)
+			options[constants.QuerySelect] = strings.Join(selected, ",")
+		}
+	}
+
+	if rule.RestrictsProperties(policy.UsageFilter) && entityType != nil {
+		for _, option := range []string{constants.QueryFilter, constants.QueryOrderBy} {
+			if err := checkPolicyProperties(rule, policy.UsageFilter, entitySetName, referencedProperties(options[option], entityType)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mandatory := rule.MandatoryFilter(); mandatory != "" {
+		if filter := strings.TrimSpace(options[constants.QueryFilter]); filter != "" {
+			if err := checkBalancedExpression(filter); err != nil {
+				return fmt.Errorf("invalid $filter: %w", err)
+			}
+			options[constants.QueryFilter] = fmt.Sprintf("(%s) and (%s)", filter, mandatory)
+		} else {
+			options[constants.QueryFilter] = mandatory
+		}
+	}
+
+	if rule.MaxTop > 0 && operation != constants.OpCount && operation != constants.OpGet {
+		top, err := strconv.Atoi(options[constants.QueryTop])
+		if err != nil || top <= 0 || top > rule.MaxTop {
+			options[constants.QueryTop] = strconv.Itoa(rule.MaxTop)
+		}
+	}
+
+	return nil
+}
+
+// applyAggregatePolicy enforces the access policy on an aggregation: grouped
+// properties are returned and filtered on, aggregated properties are returned,
+// and mandatory filters are ANDed to the filter
+func (b *ODataMCPBridge) applyAggregatePolicy(entitySetName string, spec *aggregateSpec) error {
+	if err := b.checkPolicyOperation(entitySetName, constants.OpAggregate); err != nil {
+		return err
+	}
+	rule := b.policy.EntitySet(entitySetName)
+	if rule == nil {
+		return nil
+	}
+
+	var aggregated []string
+	for _, expr := range spec.Aggregates {
+		if expr.Property != "" {
+			aggregated = append(aggregated, expr.Property)
+		}
+	}
+	if err := checkPolicyProperties(rule, policy.UsageSelect, entitySetName, append(append([]string{}, spec.GroupBy...), aggregated...)); err != nil {
+		return err
+	}
+	if err := checkPolicyProperties(rule, policy.UsageFilter, entitySetName, spec.GroupBy); err != nil {
+		return err
+	}
+	if entityType := b.entityTypeOf(entitySetName); entityType != nil {
+		if err := checkPolicyProperties(rule, policy.UsageFilter, entitySetName, referencedProperties(spec.Filter, entityType)); err != nil {
+			return err
+		}
+	}
+
+	if mandatory := rule.MandatoryFilter(); mandatory != "" {
+		if spec.Filter != "" {
+			if err := checkBalancedExpression(spec.Filter); err != nil {
+				return fmt.Errorf("invalid filter: %w", err)
+			}
+			spec.Filter = fmt.Sprintf("(%s) and (%s)", spec.Filter, mandatory)
+		} else {
+			spec.Filter = mandatory
+		}
+	}
+	return nil
+}
+
+// checkWritePolicy enforces the access policy on a create or update payload
+func (b *ODataMCPBridge) checkWritePolicy(entitySetName, operation string, data map[string]interface{}) error {
+	if err := b.checkPolicyOperation(entitySetName, operation); err != nil {
+		return err
+	}
+	rule := b.policy.EntitySet(entitySetName)
+	if !rule.RestrictsProperties(policy.UsageWrite) {
+		return nil
+	}
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	return checkPolicyProperties(rule, policy.UsageWrite, entitySetName, names)
+}
+
+// readableEntity returns the properties of an entity the access policy lets the caller
+// read. Entity metadata (__metadata, @odata annotations) is kept; property annotations
+// follow their property.
+func (b *ODataMCPBridge) readableEntity(entitySetName string, entity map[string]interface{}) map[string]interface{} {
+	rule := b.policy.EntitySet(entitySetName)
+	if !rule.RestrictsProperties(policy.UsageSelect) {
+		return entity
+	}
+	visible := make(map[string]interface{}, len(entity))
+	for name, value := range entity {
+		property, _, _ := strings.Cut(name, "@")
+		if property == "" || strings.HasPrefix(property, "__") || rule.AllowsProperty(policy.UsageSelect, property) {
+			visible[name] = value
+		}
+	}
+	return visible
+}
+
+// readableWriteResponse removes the properties the access policy hides from reads from
+// the entity a create or update returns
+func (b *ODataMCPBridge) readableWriteResponse(entitySetName string, response *models.ODataResponse) {
+	if entity, ok := response.Value.(map[string]interface{}); ok {
+		response.Value = b.readableEntity(entitySetName, entity)
+	}
+}
+
+// checkPolicyScope verifies an entity matches the mandatory filters before it is
+// updated or deleted, so writes cannot reach rows that reads are not allowed to see
+func (b *ODataMCPBridge) checkPolicyScope(ctx context.Context, entitySetName string, key map[string]interface{}) error {
+	mandatory := b.policy.EntitySet(entitySetName).MandatoryFilter()
+	if mandatory == "" {
+		return nil
+	}
+
+	options := map[string]string{
+		constants.QueryFilter:      fmt.Sprintf("(%s) and (%s)", keyFilter(key), mandatory),
+		constants.QueryTop:         "1",
+		constants.QueryInlineCount: "none",
+	}
+	response, err := b.client.GetEntitySet(ctx, entitySetName, options)
+	if err != nil {
+		return fmt.Errorf("failed to check access policy scope: %w", err)
+	}
+	if rows, _ := response.Value.([]interface{}); len(rows) == 0 {
+		return fmt.Errorf("%s not found or outside the access policy scope", formatEntityTarget(entitySetName, key))
+	}
+	return nil
+}
+
+// getEntityInScope reads a single entity through a filter query, so the mandatory filters apply
+func (b *ODataMCPBridge) getEntityInScope(ctx context.Context, entitySetName string, key map[string]interface{}, options map[string]string) (*models.ODataResponse, error) {
+	query := make(map[string]string, len(options)+3)
+	for k, v := range options {
+		query[k] = v
+	}
+	if err := b.applyReadPolicy(entitySetName, constants.OpGet, query); err != nil {
+		return nil, err
+	}
+	// Key properties identify the entity; they are not subject to the filter rules
+	if filter := query[constants.QueryFilter]; filter != "" {
+		query[constants.QueryFilter] = fmt.Sprintf("(%s) and (%s)", keyFilter(key), filter)
+	} else {
+		query[constants.QueryFilter] = keyFilter(key)
+	}
+	query[constants.QueryInlineCount] = "none"
+	query[constants.QueryTop] = "1"
+
+	response, err := b.client.GetEntitySet(ctx, entitySetName, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := response.Value.([]interface{})
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s not found or outside the access policy scope", formatEntityTarget(entitySetName, key))
+	}
+	response.Value = rows[0]
+	return response, nil
+}
+
+// policyToolNote describes the restrictions of an entity set for tool descriptions
+func (b *ODataMCPBridge) policyToolNote(entitySetName string) string {
+	rule := b.policy.EntitySet(entitySetName)
+	if rule == nil {
+		return ""
+	}
+
+	var notes []string
+	if entityType := b.entityTypeOf(entitySetName); entityType != nil && rule.RestrictsProperties(policy.UsageSelect) {
+		notes = append(notes, "readable properties: "+strings.Join(readableProperties(rule, entityType), ", "))
+	}
+	if mandatory := rule.MandatoryFilter(); mandatory != "" {
+		notes = append(notes, "always filtered by "+mandatory)
+	}
+	if rule.MaxTop > 0 {
+		notes = append(notes, fmt.Sprintf("at most %d rows per call", rule.MaxTop))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (access policy: " + strings.Join(notes, "; ") + ")"
+}
+
+// applyPolicyTopLimit sets the max_top of an entity set as the maximum of a tool's $top argument
+func (b *ODataMCPBridge) applyPolicyTopLimit(entitySetName string, properties map[string]interface{}) {
+	rule := b.policy.EntitySet(entitySetName)
+	if rule == nil || rule.MaxTop <= 0 {
+		return
+	}
+	if top, ok := properties[b.getParameterName("$top")].(map[string]interface{}); ok {
+		top["maximum"] = rule.MaxTop
+	}
+}
+
+// policyTraceInfo shows the effective rule of every entity set and function for --trace
+func (b *ODataMCPBridge) policyTraceInfo() *models.PolicyTraceInfo {
+	if b.policy == nil {
+		return nil
+	}
+
+	info := &models.PolicyTraceInfo{
+		File:         b.policy.Path(),
+		DenyUnlisted: b.policy.DenyUnlisted,
+		EntitySets:   make(map[string]interface{}),
+		Functions:    make(map[string]interface{}),
+	}
+
+	for name := range b.metadata.EntitySets {
+		rule := b.policy.EntitySet(name)
+		switch {
+		case !b.policy.AllowsEntitySet(name):
+			info.EntitySets[name] = "denied"
+		case rule == nil:
+			info.EntitySets[name] = "unrestricted"
+		default:
+			effective := map[string]interface{}{"rule": rule.Pattern}
+			if len(rule.Operations) > 0 {
+				effective["operations"] = rule.Operations
+			}
+			if entityType := b.entityTypeOf(name); entityType != nil {
+				for _, usage := range []string{policy.UsageSelect, policy.UsageFilter, policy.UsageWrite} {
+					if rule.RestrictsProperties(usage) {
+						effective[usage] = permittedProperties(rule, usage, entityType)
+					}
+				}
+			}
+			if mandatory := rule.MandatoryFilter(); mandatory != "" {
+				effective["mandatory_filter"] = mandatory
+			}
+			if rule.MaxTop > 0 {
+				effective["max_top"] = rule.MaxTop
+			}
+			info.EntitySets[name] = effective
+		}
+	}
+
+	for name := range b.metadata.FunctionImports {
+		if b.policy.AllowsFunction(name) {
+			info.Functions[name] = "allowed"
+		} else {
+			info.Functions[name] = "denied"
+		}
+	}
+
+	return info
+}
+
+// validatePolicy rejects policies naming properties an entity set does not have.
+// Wildcard rules are skipped since they apply to sets with different properties.
+func (b *ODataMCPBridge) validatePolicy() error {
+	if b.policy == nil {
+		return nil
+	}
+	for name, rule := range b.policy.EntitySets {
+		entityType := b.entityTypeOf(name)
+		if entityType == nil {
+			continue
+		}
+		for _, prop := range rule.NamedProperties() {
+			if !hasProperty(entityType, prop) {
+				return fmt.Errorf("access policy for %s names unknown property %s", name, prop)
+			}
+		}
+	}
+	return nil
+}
+
+// readableProperties lists the structural properties a rule lets reads return
+func readableProperties(rule *policy.EntitySetRule, entityType *models.EntityType) []string {
+	var names []string
+	for _, prop := range entityType.Properties {
+		if rule.AllowsProperty(policy.UsageSelect, prop.Name) {
+			names = append(names, prop.Name)
+		}
+	}
+	return names
+}
+
+// permittedProperties lists structural and navigation properties allowed for a usage
+func permittedProperties(rule *policy.EntitySetRule, usage string, entityType *models.EntityType) []string {
+	names := make([]string, 0, len(entityType.Properties))
+	for _, prop := range entityType.Properties {
+		if rule.AllowsProperty(usage, prop.Name) {
+			names = append(names, prop.Name)
+		}
+	}
+	if usage == policy.UsageSelect {
+		for _, nav := range entityType.NavigationProps {
+			if rule.AllowsProperty(usage, nav.Name) {
+				names = append(names, nav.Name)
+			}
+		}
+	}
+	return names
+}
+
+// checkPolicyProperties returns an error naming the first property the rule forbids for a usage
+func checkPolicyProperties(rule *policy.EntitySetRule, usage, entitySetName string, names []string) error {
+	for _, name := range names {
+		if !rule.AllowsProperty(usage, name) {
+			return fmt.Errorf("property %s of %s cannot be used in %s by the access policy", name, entitySetName, usage)
+		}
+	}
+	return nil
+}
+
+// splitPropertyList splits $select or $expand into the first segment of each path.
+// Commas inside nested options such as Orders($select=ID,Name) do not split.
+func splitPropertyList(list string) []string {
+	var names []string
+	depth, start := 0, 0
+	for i := 0; i <= len(list); i++ {
+		if i < len(list) {
+			switch list[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		item := strings.TrimSpace(list[start:i])
+		start = i + 1
+		// Nested options such as Orders($select=ID) and paths such as Orders/Items
+		if j := strings.IndexAny(item, "(/"); j >= 0 {
+			item = item[:j]
+		}
+		if item != "" && item != "*" {
+			names = append(names, item)
+		}
+	}
+	return names
+}
+
+// checkBalancedExpression rejects an expression whose parentheses do not pair up or whose
+// string literal is left open. A caller's filter is wrapped in parentheses before the
+// mandatory filter is ANDed to it, so "A) or (B" would otherwise escape the wrapping.
+func checkBalancedExpression(expr string) error {
+	depth := 0
+	inLiteral := false
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case inLiteral:
+			if r == '\'' {
+				if i+1 < len(runes) && runes[i+1] == '\'' {
+					i++ // '' escape
+					continue
+				}
+				inLiteral = false
+			}
+		case r == '\'':
+			inLiteral = true
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses in '%s'", expr)
+			}
+		}
+	}
+	if inLiteral {
+		return fmt.Errorf("unterminated string literal in '%s'", expr)
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses in '%s'", expr)
+	}
+	return nil
+}
+
+// referencedProperties returns the properties of an entity type that appear as
+// identifiers in a $filter or $orderby expression, ignoring string literals
+func referencedProperties(expr string, entityType *models.EntityType) []string {
+	if expr == "" {
+		return nil
+	}
+
+	var names []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			// Skip the literal, including '' escapes
+			i++
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			// Only the first segment of a path like Category/Name is a property of this type
+			if start == 0 || runes[start-1] != '/' {
+				if name := string(runes[start:i]); hasProperty(entityType, name) {
+					names = append(names, name)
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// hasProperty checks structural and navigation properties of an entity type
+func hasProperty(entityType *models.EntityType, name string) bool {
+	for _, prop := range entityType.Properties {
+		if prop.Name == name {
+			return true
+		}
+	}
+	for _, nav := range entityType.NavigationProps {
+		if nav.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFilter renders a key as a $filter expression
+func keyFilter(key map[string]interface{}) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		var literal string
+		switch v := key[name].(type) {
+		case string:
+			literal = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		case float64:
+			literal = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			literal = fmt.Sprint(v)
+		}
+		parts = append(parts, fmt.Sprintf("%s eq %s", name, literal))
+	}
+	return strings.Join(parts, " and ")
+}
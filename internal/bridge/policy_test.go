@@ -0,0 +1,274 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+const testPolicy = `
+entity_sets:
+  Products:
+    operations: [read, update]
+    select:
+      deny: [Price]
+    filter:
+      deny: [Price]
+    write:
+      allow: [ProductName]
+    filters: ["Price lt 100"]
+    max_top: 20
+  Categories:
+    deny: true
+functions:
+  GetProductsByCategory:
+    deny: true
+`
+
+// usePolicy puts a bridge under a policy given as YAML
+func usePolicy(t *testing.T, bridge *ODataMCPBridge, yaml string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	p, err := policy.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+	bridge.policy = p
+	if err := bridge.validatePolicy(); err != nil {
+		t.Fatalf("validatePolicy() error = %v", err)
+	}
+}
+
+// newPolicyTestBridge returns a bridge under testPolicy whose service records every request
+func newPolicyTestBridge(t *testing.T, requests *[]*http.Request) *ODataMCPBridge {
+	t.Helper()
+	var mu sync.Mutex
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*requests = append(*requests, r)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("X-CSRF-Token", "token")
+		w.Write([]byte(`{"d":{"results":[{"ProductID":7,"ProductName":"Chai"}]}}`))
+	}))
+	bridge.hintManager = hint.NewManager()
+	usePolicy(t, bridge, testPolicy)
+	return bridge
+}
+
+func lastQuery(t *testing.T, requests []*http.Request) url.Values {
+	t.Helper()
+	if len(requests) == 0 {
+		t.Fatal("no request was sent")
+	}
+	return requests[len(requests)-1].URL.Query()
+}
+
+func TestPolicyReads(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	ctx := context.Background()
+
+	// Hidden columns are deselected, mandatory filters ANDed and $top capped
+	if _, err := bridge.handleEntityFilter(ctx, "Products", map[string]interface{}{
+		"$filter": "ProductName eq 'Price gt 5'",
+		"$top":    float64(500),
+	}); err != nil {
+		t.Fatalf("filter failed: %v", err)
+	}
+	query := lastQuery(t, requests)
+	if query.Get("$select") != "ProductID,ProductName" {
+		t.Errorf("$select = %q", query.Get("$select"))
+	}
+	if query.Get("$filter") != "(ProductName eq 'Price gt 5') and (Price lt 100)" {
+		t.Errorf("$filter = %q", query.Get("$filter"))
+	}
+	if query.Get("$top") != "20" {
+		t.Errorf("$top = %q", query.Get("$top"))
+	}
+
+	// Restricted properties cannot be asked for explicitly
+	for _, args := range []map[string]interface{}{
+		{"$select": "ProductName,Price"},
+		{"$filter": "Price gt 5"},
+		{"$orderby": "Price desc"},
+	} {
+		sent := len(requests)
+		if _, err := bridge.handleEntityFilter(ctx, "Products", args); err == nil || !strings.Contains(err.Error(), "property Price of Products cannot be used") {
+			t.Errorf("%v: expected policy error, got %v", args, err)
+		}
+		if len(requests) != sent {
+			t.Errorf("%v: nothing may be sent for a rejected query", args)
+		}
+	}
+
+	// A filter cannot close the parentheses it is wrapped in to escape the mandatory filter
+	const escape = "ProductID gt 0) or (ProductID gt 0"
+	for _, args := range []map[string]interface{}{
+		{"$filter": escape},
+		{"$filter": "ProductName eq 'Chai"},
+	} {
+		sent := len(requests)
+		if _, err := bridge.handleEntityFilter(ctx, "Products", args); err == nil || !strings.Contains(err.Error(), "invalid $filter") {
+			t.Errorf("%v: expected an invalid filter error, got %v", args, err)
+		}
+		if len(requests) != sent {
+			t.Errorf("%v: nothing may be sent for a rejected filter", args)
+		}
+	}
+	sent := len(requests)
+	if _, err := bridge.handleEntityAggregate(ctx, "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{
+		"filter":    escape,
+		"aggregate": []interface{}{map[string]interface{}{"with": "count"}},
+	}); err == nil || !strings.Contains(err.Error(), "invalid filter") {
+		t.Errorf("aggregate: expected an invalid filter error, got %v", err)
+	}
+	if len(requests) != sent {
+		t.Error("aggregate: nothing may be sent for a rejected filter")
+	}
+
+	// Single reads go through the mandatory filter
+	if _, err := bridge.handleEntityGet(ctx, "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{"ProductID": float64(7)}); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	query = lastQuery(t, requests)
+	if query.Get("$filter") != "(ProductID eq 7) and (Price lt 100)" || query.Get("$top") != "1" {
+		t.Errorf("unexpected get query: %v", query)
+	}
+
+	// Denied entity sets are rejected in lazy mode too
+	if _, err := bridge.handleLazyCountEntities(ctx, map[string]interface{}{"entity_set": "Categories"}); err == nil || !strings.Contains(err.Error(), "restricted by access policy") {
+		t.Errorf("expected denied entity set, got %v", err)
+	}
+}
+
+func TestPolicyWrites(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	product := bridge.metadata.EntityTypes["Product"]
+	ctx := context.Background()
+
+	if _, err := bridge.handleEntityCreate(ctx, "Products", map[string]interface{}{"ProductName": "Chang"}); err == nil || !strings.Contains(err.Error(), "operation create on Products is not allowed") {
+		t.Errorf("expected create to be denied, got %v", err)
+	}
+	if _, err := bridge.handleEntityDelete(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7)}); err == nil {
+		t.Error("expected delete to be denied")
+	}
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7), "Price": 5.0}); err == nil || !strings.Contains(err.Error(), "cannot be used in write") {
+		t.Errorf("expected write policy error, got %v", err)
+	}
+	if _, err := bridge.handleFunctionCall(ctx, "GetProductsByCategory", bridge.metadata.FunctionImports["GetProductsByCategory"], map[string]interface{}{"categoryId": float64(1)}); err == nil {
+		t.Error("expected denied function")
+	}
+	if len(requests) != 0 {
+		t.Fatalf("denied writes sent %d requests", len(requests))
+	}
+
+	// Updates first check that the entity is inside the mandatory filter
+	if _, err := bridge.handleEntityUpdate(ctx, "Products", product, map[string]interface{}{"ProductID": float64(7), "ProductName": "Chai Tea", "_method": "MERGE"}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	last := requests[len(requests)-1]
+	if requests[0].Method != http.MethodGet || last.Method != constants.MERGE || last.URL.Path != "/Products(7)" {
+		t.Errorf("unexpected requests: first %s, last %s %s", requests[0].Method, last.Method, last.URL.Path)
+	}
+	if filter := requests[0].URL.Query().Get("$filter"); filter != "(ProductID eq 7) and (Price lt 100)" {
+		t.Errorf("scope check $filter = %q", filter)
+	}
+}
+
+func TestPolicyWriteResponses(t *testing.T) {
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-CSRF-Token", "token")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"d":{"__metadata":{"uri":"Products(8)"},"ProductID":8,"ProductName":"Chang","Price":"19.00","Price@odata.type":"Edm.Decimal"}}`))
+	}))
+	usePolicy(t, bridge, `
+entity_sets:
+  Products:
+    select:
+      deny: [Price]
+`)
+	ctx := context.Background()
+
+	// Writes return the entity as a read would show it
+	created, err := bridge.handleEntityCreate(ctx, "Products", map[string]interface{}{"ProductName": "Chang"})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	updated, err := bridge.handleEntityUpdate(ctx, "Products", bridge.metadata.EntityTypes["Product"], map[string]interface{}{"ProductID": float64(8), "ProductName": "Chang", "_method": "PUT"})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	for _, result := range []interface{}{created, updated} {
+		text := result.(string)
+		if strings.Contains(text, "Price") || strings.Contains(text, "19.00") || !strings.Contains(text, "Chang") {
+			t.Errorf("unexpected write response: %s", text)
+		}
+	}
+}
+
+func TestPolicyToolGeneration(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+
+	operations := make(map[string]bool)
+	for name, info := range bridge.tools {
+		if info.EntitySet == "Categories" || strings.Contains(name, "GetProductsByCategory") {
+			t.Errorf("tool %s should be hidden by the policy", name)
+		}
+		if info.EntitySet == "Products" {
+			operations[info.Operation] = true
+		}
+	}
+	if !operations[constants.OpFilter] || !operations[constants.OpUpdate] || operations[constants.OpCreate] || operations[constants.OpDelete] {
+		t.Errorf("unexpected Products operations: %v", operations)
+	}
+
+	for _, tool := range bridge.server.GetTools() {
+		info := bridge.tools[tool.Name]
+		if info.EntitySet != "Products" {
+			continue
+		}
+		properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+		switch info.Operation {
+		case constants.OpFilter:
+			if !strings.Contains(tool.Description, "always filtered by Price lt 100") {
+				t.Errorf("filter description lacks the policy note: %s", tool.Description)
+			}
+			if top, _ := properties["$top"].(map[string]interface{}); top["maximum"] != 20 {
+				t.Errorf("$top schema = %v", properties["$top"])
+			}
+		case constants.OpUpdate:
+			if _, ok := properties["Price"]; ok {
+				t.Error("update schema must not offer non-writable properties")
+			}
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+// maxPromptNameLength keeps prompt names short enough for slash commands
+const maxPromptNameLength = 48
+
+var promptNameUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// generatePrompts registers prompts for exploring, finding and creating records of every
+// exposed entity set, and a recipe prompt for every example of the service hints
+func (b *ODataMCPBridge) generatePrompts() {
+	for _, name := range b.resourceEntitySets() {
+		entitySet := b.metadata.EntitySets[name]
+		entityType := b.entityTypeOf(name)
+		if entityType == nil {
+			continue
+		}
+		b.generateExplorePrompt(name, entitySet, entityType)
+		if b.promptTool(name, constants.OpGet) != "" || b.promptTool(name, constants.OpFilter) != "" {
+			b.generateFindPrompt(name, entityType)
+		}
+		if entitySet.Creatable && b.promptTool(name, constants.OpCreate) != "" {
+			b.generateCreatePrompt(name, entityType)
+		}
+	}
+
+	used := make(map[string]bool)
+	for i, example := range b.hintManager.Examples(b.config.ServiceURL) {
+		name := "recipe_" + strings.Trim(promptNameUnsafe.ReplaceAllString(strings.ToLower(example.Description), "_"), "_")
+		if len(name) > maxPromptNameLength {
+			name = strings.TrimRight(name[:maxPromptNameLength], "_")
+		}
+		if used[name] || name == "recipe_" {
+			name = fmt.Sprintf("recipe_%d", i+1)
+		}
+		used[name] = true
+		b.generateRecipePrompt(name, example)
+	}
+}
+
+// promptTool names the tool a prompt should use for an operation on an entity set: the
+// entity set's own tool, or in lazy mode the generic tool with an entity_set argument.
+// It returns "" if no tool performs the operation.
+func (b *ODataMCPBridge) promptTool(entitySetName, operation string) string {
+	var generic string
+	for name, info := range b.tools {
+		if info.Operation != operation {
+			continue
+		}
+		switch info.EntitySet {
+		case entitySetName:
+			return "`" + name + "`"
+		case "":
+			generic = name
+		}
+	}
+	if generic == "" || !b.policy.EntitySet(entitySetName).AllowsOperation(operation) {
+		return ""
+	}
+	return fmt.Sprintf("`%s` with entity_set \"%s\"", generic, entitySetName)
+}
+
+// generateExplorePrompt registers explore_<EntitySet>: schema, key fields and sample rows
+func (b *ODataMCPBridge) generateExplorePrompt(entitySetName string, entitySet *models.EntitySet, entityType *models.EntityType) {
+	prompt := &mcp.Prompt{
+		Name:        "explore_" + entitySetName,
+		Title:       "Explore " + entitySetName,
+		Description: fmt.Sprintf("Explore the %s entity set: schema, key fields and sample rows", entitySetName),
+	}
+
+	b.server.AddPrompt(prompt, func(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+		var text strings.Builder
+		fmt.Fprintf(&text, "Explore the OData entity set %s", entitySetName)
+		if entitySet.Description != nil && *entitySet.Description != "" {
+			fmt.Fprintf(&text, " (%s)", *entitySet.Description)
+		}
+		text.WriteString(".\n\n")
+		b.writeSchema(&text, entitySetName, entityType)
+
+		if filter := b.promptTool(entitySetName, constants.OpFilter); filter != "" {
+			fmt.Fprintf(&text, "\nRead 5 sample rows with %s ($top 5).", filter)
+		}
+		if count := b.promptTool(entitySetName, constants.OpCount); count != "" {
+			fmt.Fprintf(&text, " Get the total number of rows with %s.", count)
+		}
+		text.WriteString("\nThen summarize what the entity set holds, which fields identify a record, and how it relates to other entity sets through its navigation properties.")
+
+		return &mcp.PromptResult{Messages: []*mcp.PromptMessage{mcp.UserMessage(text.String())}}, nil
+	})
+}
+
+// generateFindPrompt registers find_<EntitySet>: look up a record by a business key
+func (b *ODataMCPBridge) generateFindPrompt(entitySetName string, entityType *models.EntityType) {
+	rule := b.policy.EntitySet(entitySetName)
+	prompt := &mcp.Prompt{
+		Name:        "find_" + entitySetName,
+		Title:       "Find " + entitySetName + " record",
+		Description: fmt.Sprintf("Find a %s record by its key or another business key", entitySetName),
+		Arguments: []*mcp.PromptArgument{
+			{Name: "value", Description: "The value identifying the record, e.g. a number, code or name", Required: true},
+			{Name: "field", Description: "Property holding the value; by default the key and text properties are tried"},
+		},
+	}
+
+	b.server.AddPrompt(prompt, func(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+		value, field := args["value"], args["field"]
+		get := b.promptTool(entitySetName, constants.OpGet)
+		filter := b.promptTool(entitySetName, constants.OpFilter)
+
+		var text strings.Builder
+		fmt.Fprintf(&text, "Find the %s record identified by \"%s\".\n\n", entitySetName, value)
+
+		switch {
+		case field != "":
+			prop := propertyOf(entityType, field)
+			if prop == nil || !rule.AllowsProperty(policy.UsageFilter, field) {
+				return nil, fmt.Errorf("%w: %s is not a filterable property of %s", mcp.ErrInvalidPromptArguments, field, entitySetName)
+			}
+			if filter == "" {
+				return nil, fmt.Errorf("%w: %s cannot be filtered", mcp.ErrInvalidPromptArguments, entitySetName)
+			}
+			fmt.Fprintf(&text, "Query %s with $filter %s eq %s.", filter, field, filterLiteral(prop, value))
+		default:
+			if get != "" {
+				fmt.Fprintf(&text, "If \"%s\" is a value of the key (%s), read the record directly with %s.\n",
+					value, strings.Join(entityType.KeyProperties, ", "), get)
+			}
+			if filter != "" {
+				var candidates []string
+				for _, prop := range entityType.Properties {
+					if prop.Type == "Edm.String" && !prop.IsKey && rule.AllowsProperty(policy.UsageFilter, prop.Name) {
+						candidates = append(candidates, prop.Name)
+					}
+				}
+				if len(candidates) > 0 {
+					fmt.Fprintf(&text, "Otherwise search the text properties (%s) with %s, e.g. $filter %s eq %s, or substringof/contains for partial matches.\n",
+						strings.Join(candidates, ", "), filter, candidates[0], filterLiteral(&models.EntityProperty{Type: "Edm.String"}, value))
+				}
+			}
+		}
+		text.WriteString("\nIf several records match, list them with their key fields and ask which one is meant. Show the record found with its most important fields.")
+
+		return &mcp.PromptResult{Messages: []*mcp.PromptMessage{mcp.UserMessage(text.String())}}, nil
+	})
+}
+
+// generateCreatePrompt registers create_<EntitySet>, with an argument for every required
+// property the access policy lets clients write
+func (b *ODataMCPBridge) generateCreatePrompt(entitySetName string, entityType *models.EntityType) {
+	rule := b.policy.EntitySet(entitySetName)
+	fieldHints := b.hintManager.FieldHints(b.config.ServiceURL)
+
+	var required, optional []*models.EntityProperty
+	for _, prop := range entityType.Properties {
+		if !rule.AllowsProperty(policy.UsageWrite, prop.Name) {
+			continue
+		}
+		if prop.Nullable {
+			optional = append(optional, prop)
+		} else {
+			required = append(required, prop)
+		}
+	}
+
+	prompt := &mcp.Prompt{
+		Name:        "create_" + entitySetName,
+		Title:       "Create " + entitySetName + " record",
+		Description: fmt.Sprintf("Create a %s record, guided through its required fields", entitySetName),
+	}
+	for _, prop := range required {
+		description := prop.Type
+		if prop.IsKey {
+			// Keys are often assigned by the service, so they are never required here
+			description += ", key (leave empty if the service assigns it)"
+		}
+		if fieldHint, ok := fieldHints[prop.Name]; ok && fieldHint.Format != "" {
+			description += ", " + fieldHint.Format
+		}
+		prompt.Arguments = append(prompt.Arguments, &mcp.PromptArgument{
+			Name:        prop.Name,
+			Description: description,
+			Required:    !prop.IsKey,
+		})
+	}
+
+	b.server.AddPrompt(prompt, func(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+		var text strings.Builder
+		fmt.Fprintf(&text, "Create a new %s record.\n\nRequired fields:\n", entitySetName)
+		for _, prop := range required {
+			value := "(ask the user)"
+			if v := args[prop.Name]; v != "" {
+				value = fmt.Sprintf("%q", v)
+			} else if prop.IsKey {
+				value = "(leave out if the service assigns it)"
+			}
+			fmt.Fprintf(&text, "- %s (%s): %s%s\n", prop.Name, prop.Type, value, fieldHintNote(fieldHints, prop.Name))
+		}
+		if len(optional) > 0 {
+			text.WriteString("\nOptional fields:\n")
+			for _, prop := range optional {
+				fmt.Fprintf(&text, "- %s (%s)%s\n", prop.Name, prop.Type, fieldHintNote(fieldHints, prop.Name))
+			}
+		}
+		fmt.Fprintf(&text, "\nAsk the user for missing required values and for any optional fields they want to set. "+
+			"Then call %s with dry_run true, show the request that would be sent, and create the record once the user confirms.",
+			b.promptTool(entitySetName, constants.OpCreate))
+
+		return &mcp.PromptResult{Messages: []*mcp.PromptMessage{mcp.UserMessage(text.String())}}, nil
+	})
+}
+
+// generateRecipePrompt registers a workflow recipe taken from a hint example
+func (b *ODataMCPBridge) generateRecipePrompt(name string, example hint.Example) {
+	prompt := &mcp.Prompt{
+		Name:        name,
+		Title:       example.Description,
+		Description: "Service hint recipe: " + example.Description,
+	}
+
+	b.server.AddPrompt(prompt, func(ctx context.Context, args map[string]string) (*mcp.PromptResult, error) {
+		var text strings.Builder
+		fmt.Fprintf(&text, "%s.\n\nRun this query with the matching tool:\n%s\n", strings.TrimSuffix(example.Description, "."), example.Query)
+		if example.Note != "" {
+			fmt.Fprintf(&text, "\nNote: %s\n", example.Note)
+		}
+		text.WriteString("\nReplace the sample values with the ones the user is interested in, and explain the result.")
+
+		return &mcp.PromptResult{Messages: []*mcp.PromptMessage{mcp.UserMessage(text.String())}}, nil
+	})
+}
+
+// writeSchema describes the key fields and the properties a read returns
+func (b *ODataMCPBridge) writeSchema(text *strings.Builder, entitySetName string, entityType *models.EntityType) {
+	rule := b.policy.EntitySet(entitySetName)
+	fieldHints := b.hintManager.FieldHints(b.config.ServiceURL)
+
+	fmt.Fprintf(text, "Key fields: %s\n\nProperties:\n", strings.Join(entityType.KeyProperties, ", "))
+	for _, prop := range entityType.Properties {
+		if !rule.AllowsProperty(policy.UsageSelect, prop.Name) {
+			continue
+		}
+		attrs := []string{prop.Type}
+		if prop.IsKey {
+			attrs = append(attrs, "key")
+		} else if !prop.Nullable {
+			attrs = append(attrs, "required")
+		}
+		fmt.Fprintf(text, "- %s (%s)", prop.Name, strings.Join(attrs, ", "))
+		if prop.Description != nil && *prop.Description != "" {
+			fmt.Fprintf(text, ": %s", *prop.Description)
+		}
+		text.WriteString(fieldHintNote(fieldHints, prop.Name) + "\n")
+	}
+
+	var navigation []string
+	for _, nav := range entityType.NavigationProps {
+		if rule.AllowsProperty(policy.UsageSelect, nav.Name) {
+			navigation = append(navigation, nav.Name)
+		}
+	}
+	if len(navigation) > 0 {
+		sort.Strings(navigation)
+		fmt.Fprintf(text, "\nNavigation properties: %s\n", strings.Join(navigation, ", "))
+	}
+
+	if entityHint, ok := b.hintManager.EntityHints(b.config.ServiceURL)[entitySetName]; ok {
+		if entityHint.Description != "" {
+			fmt.Fprintf(text, "\nHint: %s\n", entityHint.Description)
+		}
+		for _, note := range entityHint.Notes {
+			fmt.Fprintf(text, "- %s\n", note)
+		}
+	}
+	if note := b.policyToolNote(entitySetName); note != "" {
+		fmt.Fprintf(text, "\nAccess policy: %s\n", strings.TrimSuffix(strings.TrimPrefix(note, " (access policy: "), ")"))
+	}
+}
+
+// fieldHintNote formats the format and example of a field hint for a property line
+func fieldHintNote(fieldHints map[string]hint.FieldHint, name string) string {
+	fieldHint, ok := fieldHints[name]
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if fieldHint.Format != "" {
+		parts = append(parts, "format: "+fieldHint.Format)
+	}
+	if fieldHint.Example != "" {
+		parts = append(parts, "example: "+fieldHint.Example)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// propertyOf returns the structural property of an entity type with a name, or nil
+func propertyOf(entityType *models.EntityType, name string) *models.EntityProperty {
+	for _, prop := range entityType.Properties {
+		if prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// filterLiteral renders a value as a $filter literal for a property: quoted for strings
+// and other non-numeric types, as is for numbers and booleans
+func filterLiteral(prop *models.EntityProperty, value string) string {
+	switch prop.Type {
+	case "Edm.Int16", "Edm.Int32", "Edm.Int64", "Edm.Byte", "Edm.SByte",
+		"Edm.Decimal", "Edm.Double", "Edm.Single", "Edm.Boolean":
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPromptHint = `{
+  "field_hints": {"ProductName": {"format": "at most 40 characters", "example": "Chai"}},
+  "entity_hints": {"Products": {"description": "Goods sold by the shop", "notes": ["Prices are net"]}},
+  "examples": [{"description": "Products of a category", "query": "filter_Products($filter=CategoryID eq 1)", "note": "CategoryID is numeric"}]
+}`
+
+// getPrompt renders a prompt and returns the text of its only message
+func getPrompt(t *testing.T, bridge *ODataMCPBridge, name string, args map[string]interface{}) string {
+	t.Helper()
+	result, rpcErr := resourceRequest(t, bridge, "prompts/get", map[string]interface{}{"name": name, "arguments": args})
+	if rpcErr != nil {
+		t.Fatalf("prompts/get %s failed: %+v", name, rpcErr)
+	}
+	messages, _ := result["messages"].([]interface{})
+	if len(messages) != 1 {
+		t.Fatalf("expected one message from %s, got %v", name, result)
+	}
+	message := messages[0].(map[string]interface{})
+	content := message["content"].(map[string]interface{})
+	if message["role"] != "user" || content["type"] != "text" {
+		t.Errorf("unexpected message: %v", message)
+	}
+	return content["text"].(string)
+}
+
+func TestPrompts(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	bridge.hintManager.SetCLIHint(testPromptHint)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+	bridge.generatePrompts()
+	initializeClient(t, bridge, "2024-11-05")
+
+	result, _ := resourceRequest(t, bridge, "prompts/list", nil)
+	var names []string
+	for _, prompt := range result["prompts"].([]interface{}) {
+		names = append(names, prompt.(map[string]interface{})["name"].(string))
+	}
+	// Categories is denied and Products may not be created under the policy
+	want := []string{
+		"explore_OrderDetails", "find_OrderDetails", "create_OrderDetails",
+		"explore_Products", "find_Products",
+		"recipe_products_of_a_category",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("prompts = %v, want %v", names, want)
+	}
+
+	explore := getPrompt(t, bridge, "explore_Products", nil)
+	for _, part := range []string{"Key fields: ProductID", "- ProductName (Edm.String, required)", "[format: at most 40 characters, example: Chai]", "Goods sold by the shop", "always filtered by Price lt 100", "Read 5 sample rows with `filter_Products"} {
+		if !strings.Contains(explore, part) {
+			t.Errorf("explore prompt lacks %q:\n%s", part, explore)
+		}
+	}
+	if strings.Contains(explore, "- Price (") {
+		t.Errorf("explore prompt describes a hidden property:\n%s", explore)
+	}
+
+	find := getPrompt(t, bridge, "find_Products", map[string]interface{}{"value": "O'Hara", "field": "ProductName"})
+	if !strings.Contains(find, "Query `filter_Products") || !strings.Contains(find, "with $filter ProductName eq 'O''Hara'.") {
+		t.Errorf("unexpected find prompt:\n%s", find)
+	}
+	find = getPrompt(t, bridge, "find_Products", map[string]interface{}{"value": "Chai"})
+	if !strings.Contains(find, "read the record directly with `get_Products") || !strings.Contains(find, "text properties (ProductName)") {
+		t.Errorf("unexpected find prompt:\n%s", find)
+	}
+	if _, rpcErr := resourceRequest(t, bridge, "prompts/get", map[string]interface{}{"name": "find_Products", "arguments": map[string]interface{}{"value": "5", "field": "Price"}}); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("expected invalid params for a hidden field, got %+v", rpcErr)
+	}
+	if _, rpcErr := resourceRequest(t, bridge, "prompts/get", map[string]interface{}{"name": "find_Products"}); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("expected invalid params for a missing argument, got %+v", rpcErr)
+	}
+	if _, rpcErr := resourceRequest(t, bridge, "prompts/get", map[string]interface{}{"name": "explore_Categories"}); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("expected invalid params for an unknown prompt, got %+v", rpcErr)
+	}
+
+	create := getPrompt(t, bridge, "create_OrderDetails", map[string]interface{}{"Quantity": "12"})
+	for _, part := range []string{"- Quantity (Edm.Int32): \"12\"", "- OrderID (Edm.Int32): (leave out if the service assigns it)", "Then call `create_OrderDetails"} {
+		if !strings.Contains(create, part) {
+			t.Errorf("create prompt lacks %q:\n%s", part, create)
+		}
+	}
+
+	recipe := getPrompt(t, bridge, "recipe_products_of_a_category", nil)
+	if !strings.Contains(recipe, "filter_Products($filter=CategoryID eq 1)") || !strings.Contains(recipe, "Note: CategoryID is numeric") {
+		t.Errorf("unexpected recipe prompt:\n%s", recipe)
+	}
+
+	// In lazy mode prompts refer to the generic tools
+	lazy := newPolicyTestBridge(t, &requests)
+	lazy.config.LazyMetadata = true
+	if err := lazy.generateTools(); err != nil {
+		t.Fatalf("generateTools() error = %v", err)
+	}
+	lazy.generatePrompts()
+	initializeClient(t, lazy, "2024-11-05")
+	if explore := getPrompt(t, lazy, "explore_Products", nil); !strings.Contains(explore, "Read 5 sample rows with `list_entities") || !strings.Contains(explore, "with entity_set \"Products\" ($top 5)") {
+		t.Errorf("unexpected lazy explore prompt:\n%s", explore)
+	}
+}
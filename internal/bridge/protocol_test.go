@@ -0,0 +1,71 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+func TestProtocolValidation(t *testing.T) {
+	bridge := createTestBridge(&config.Config{ServiceURL: "http://localhost"})
+	send := func(msg string) *transport.Message {
+		t.Helper()
+		var m transport.Message
+		if err := json.Unmarshal([]byte(msg), &m); err != nil {
+			t.Fatalf("bad test message %s: %v", msg, err)
+		}
+		resp, err := bridge.server.HandleMessage(context.Background(), &m)
+		if err != nil {
+			t.Fatalf("HandleMessage(%s) error = %v", msg, err)
+		}
+		return resp
+	}
+
+	if err := bridge.server.SetProtocolVersion("1999-01-01"); err == nil {
+		t.Error("SetProtocolVersion accepted an unsupported version")
+	}
+
+	// Only ping may come before initialize
+	if resp := send(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`); resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("tools/list before initialize = %+v, want Invalid Request", resp)
+	}
+	if resp := send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.Error != nil {
+		t.Errorf("ping before initialize failed: %+v", resp.Error)
+	}
+
+	// Notifications are never answered, whether the server knows them or not
+	for _, method := range []string{"notifications/cancelled", "notifications/progress", "notifications/unknown"} {
+		if resp := send(`{"jsonrpc":"2.0","method":"` + method + `","params":{"requestId":1}}`); resp != nil {
+			t.Errorf("%s was answered: %+v", method, resp)
+		}
+	}
+	if resp := send(`{"jsonrpc":"2.0","id":3}`); resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("request without method = %+v, want Invalid Request", resp)
+	}
+
+	// The server agrees to a version it supports and offers its own otherwise
+	for requested, want := range map[string]string{"2025-03-26": "2025-03-26", "2099-01-01": "2025-06-18"} {
+		resp := send(`{"jsonrpc":"2.0","id":4,"method":"initialize","params":{"protocolVersion":"` + requested + `","capabilities":{},"clientInfo":{"name":"test-client","version":"1.0"}}}`)
+		var result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if resp.Error != nil || json.Unmarshal(resp.Result, &result) != nil || result.ProtocolVersion != want {
+			t.Errorf("initialize with %s = %+v, want version %s", requested, resp, want)
+		}
+	}
+
+	// A null ID is answered with null, or with 0 in compatibility mode
+	if resp := send(`{"jsonrpc":"2.0","id":null,"method":"ping"}`); string(resp.ID) != "null" {
+		t.Errorf("null ID answered with %s", resp.ID)
+	}
+	bridge.server.SetNullIDCompat(true)
+	if resp := send(`{"jsonrpc":"2.0","id":null,"method":"ping"}`); string(resp.ID) != "0" {
+		t.Errorf("null ID answered with %s in compatibility mode", resp.ID)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/zmcp/odata-mcp/internal/mcp"
+)
+
+// redactedError carries a scrubbed message and details but keeps the original error for errors.Is/As
+type redactedError struct {
+	msg     string
+	details map[string]interface{}
+	err     error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// ErrorDetails returns the scrubbed details, so they are found before the original ones
+func (e *redactedError) ErrorDetails() map[string]interface{} { return e.details }
+
+// redactToolCall is the tool middleware that applies the redaction rules to every result
+// and removes redacted values from error messages
+func (b *ODataMCPBridge) redactToolCall(name string, next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		// Handlers consume their arguments, so collect the values to scrub first
+		secrets := b.redactor.Secrets(args)
+
+		result, err := next(ctx, args)
+		if err != nil {
+			msg := b.redactor.Message(err.Error(), secrets)
+			// Service errors often quote rejected values in their details too
+			var detailed mcp.DetailedError
+			if errors.As(err, &detailed) && !b.redactor.Empty() {
+				return nil, &redactedError{msg: msg, details: b.redactDetails(detailed.ErrorDetails(), secrets), err: err}
+			}
+			if msg != err.Error() {
+				return nil, &redactedError{msg: msg, err: err}
+			}
+			return nil, err
+		}
+		return b.redactResult(result), nil
+	}
+}
+
+// redactResult redacts a JSON tool result and lists the redacted fields under
+// "@odata.metadata". Results that are not JSON only get the value patterns applied.
+func (b *ODataMCPBridge) redactResult(result interface{}) interface{} {
+	text, ok := result.(string)
+	if !ok {
+		return result
+	}
+
+	// UseNumber keeps large integer keys and decimals exactly as the service sent them
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return b.redactor.Text(text)
+	}
+
+	data, fields := b.redactor.Apply(data)
+	if len(fields) == 0 {
+		return text
+	}
+	if obj, ok := data.(map[string]interface{}); ok {
+		meta, _ := obj["@odata.metadata"].(map[string]interface{})
+		if meta == nil {
+			meta = make(map[string]interface{})
+		}
+		// Keep fields a handler already redacted itself (e.g. aggregate aliases)
+		if earlier, ok := meta["redacted_fields"].([]interface{}); ok {
+			for _, field := range earlier {
+				if name, ok := field.(string); ok {
+					fields = append(fields, name)
+				}
+			}
+			sort.Strings(fields)
+		}
+		meta["redacted_fields"] = fields
+		meta["redaction"] = b.redactor.Mode()
+		obj["@odata.metadata"] = meta
+	}
+
+	redacted, err := json.Marshal(data)
+	if err != nil {
+		// Never fall back to the unredacted text
+		return `{"error": "failed to format redacted response"}`
+	}
+	return string(redacted)
+}
+
+// redactRow applies the redaction rules to one exported row
+func (b *ODataMCPBridge) redactRow(row map[string]interface{}) map[string]interface{} {
+	if b.redactor.Empty() {
+		return row
+	}
+	b.redactor.Apply(row)
+	return row
+}
+
+// redactDetails scrubs the messages in machine-readable error details
+func (b *ODataMCPBridge) redactDetails(details map[string]interface{}, secrets []string) map[string]interface{} {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+	var scrubbed interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&scrubbed); err != nil {
+		return nil
+	}
+	scrubbed = b.redactStrings(scrubbed, secrets)
+	result, _ := scrubbed.(map[string]interface{})
+	return result
+}
+
+// redactStrings applies Message to every string in a decoded JSON value
+func (b *ODataMCPBridge) redactStrings(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return b.redactor.Message(v, secrets)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = b.redactStrings(item, secrets)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = b.redactStrings(item, secrets)
+		}
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/redact"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// callTool runs a tools/call through the MCP server, so tool middleware applies
+func callTool(t *testing.T, bridge *ODataMCPBridge, operation string, args map[string]interface{}) *transport.Message {
+	t.Helper()
+	var name string
+	for toolName, info := range bridge.tools {
+		if info.EntitySet == "Products" && info.Operation == operation {
+			name = toolName
+		}
+	}
+	if name == "" {
+		t.Fatalf("no %s tool for Products", operation)
+	}
+	params, _ := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+	resp, err := bridge.server.HandleMessage(context.Background(), &transport.Message{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage("1"),
+		Method:  "tools/call",
+		Params:  params,
+	})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	return resp
+}
+
+func TestRedactToolResults(t *testing.T) {
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.Header().Set("X-CSRF-Token", "token")
+			w.Write([]byte(`{"d":{"results":[{"ProductID":7,"ProductName":"ask bob@example.com","Price":"18.00"}]}}`))
+			return
+		}
+		// Services often quote the rejected value back
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":"VAL","message":{"value":"Price 4711.25 exceeds the limit for bob@example.com"}}}`))
+	}))
+	bridge.hintManager = hint.NewManager()
+	r, err := redact.New([]string{"price"}, nil, []string{"email"}, redact.ModeMask)
+	if err != nil {
+		t.Fatalf("redact.New() error = %v", err)
+	}
+	bridge.redactor = r
+	bridge.server.Use(bridge.redactToolCall)
+	if err := bridge.generateEagerTools(); err != nil {
+		t.Fatalf("generateEagerTools() error = %v", err)
+	}
+	initializeClient(t, bridge, "2024-11-05")
+
+	resp := callTool(t, bridge, constants.OpFilter, map[string]interface{}{})
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil || len(result.Content) != 1 {
+		t.Fatalf("unexpected result: %s", resp.Result)
+	}
+	text := result.Content[0].Text
+	if strings.Contains(text, "18.00") || strings.Contains(text, "bob@") {
+		t.Errorf("result not redacted: %s", text)
+	}
+	if !strings.Contains(text, `"ProductID":7,`) {
+		t.Errorf("numbers must survive redaction unchanged: %s", text)
+	}
+	if !strings.Contains(text, `"redacted_fields":["Price","ProductName"]`) {
+		t.Errorf("redacted fields not marked: %s", text)
+	}
+
+	// Service errors come back as isError results, message and details scrubbed alike
+	resp = callTool(t, bridge, constants.OpCreate, map[string]interface{}{"ProductName": "Chang", "Price": 4711.25})
+	var failure struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if resp.Error != nil || json.Unmarshal(resp.Result, &failure) != nil || !failure.IsError || len(failure.Content) != 2 {
+		t.Fatalf("expected an isError result, got %s %+v", resp.Result, resp.Error)
+	}
+	for _, content := range failure.Content {
+		if msg := content.Text; strings.Contains(msg, "4711") || strings.Contains(msg, "bob@") || !strings.Contains(msg, "exceeds the limit") {
+			t.Errorf("unexpected error content: %s", msg)
+		}
+	}
+	if !strings.Contains(failure.Content[1].Text, `"category":"invalid_request"`) {
+		t.Errorf("error details lack the category: %s", failure.Content[1].Text)
+	}
+}
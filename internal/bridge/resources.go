@@ -0,0 +1,317 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/policy"
+)
+
+const jsonMimeType = "application/json"
+
+// serviceName names the service in resource URIs: the last path segment of the service
+// URL without a .svc suffix, e.g. "northwind" for .../V4/Northwind/Northwind.svc
+func (b *ODataMCPBridge) serviceName() string {
+	name := "service"
+	if u, err := url.Parse(b.config.ServiceURL); err == nil {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if last := segments[len(segments)-1]; last != "" {
+			name = last
+		}
+	}
+	return strings.ToLower(strings.TrimSuffix(name, ".svc"))
+}
+
+// resourceURI builds an odata:// resource URI for a path within the service
+func (b *ODataMCPBridge) resourceURI(path string) string {
+	return "odata://" + b.serviceName() + "/" + path
+}
+
+// generateResources registers the service metadata, a schema document per entity set and
+// the service hints as resources, and single entities as a resource template
+func (b *ODataMCPBridge) generateResources() {
+	b.server.AddResource(&mcp.Resource{
+		URI:         b.resourceURI("$metadata"),
+		Name:        "Service metadata",
+		Description: "Entity sets, entity types and function imports of the OData service",
+		MimeType:    jsonMimeType,
+	}, func(ctx context.Context, uri string) (*mcp.ResourceContents, error) {
+		return jsonContents(uri, b.metadataDocument())
+	})
+
+	if b.hintManager.GetHints(b.config.ServiceURL) != nil {
+		b.server.AddResource(&mcp.Resource{
+			URI:         b.resourceURI("$hints"),
+			Name:        "Service hints",
+			Description: "Known issues, workarounds and examples for this OData service",
+			MimeType:    jsonMimeType,
+		}, func(ctx context.Context, uri string) (*mcp.ResourceContents, error) {
+			return jsonContents(uri, b.hintManager.GetHints(b.config.ServiceURL))
+		})
+	}
+
+	for _, name := range b.resourceEntitySets() {
+		entitySetName := name
+		b.server.AddResource(&mcp.Resource{
+			URI:         b.resourceURI(entitySetName + "/$schema"),
+			Name:        entitySetName + " schema",
+			Description: "Properties, keys and capabilities of the " + entitySetName + " entity set" + b.policyToolNote(entitySetName),
+			MimeType:    jsonMimeType,
+		}, func(ctx context.Context, uri string) (*mcp.ResourceContents, error) {
+			return jsonContents(uri, b.schemaDocument(entitySetName))
+		})
+	}
+
+	if b.config.IsOperationEnabled('G') {
+		b.server.AddResourceTemplate(&mcp.ResourceTemplate{
+			URITemplate: b.resourceURI("{EntitySet}({key})"),
+			Name:        "Entity",
+			Description: "A single entity by its key predicate, e.g. " + b.resourceURI("Products(1)") +
+				" or " + b.resourceURI("OrderDetails(OrderID=10248,ProductID=11)"),
+			MimeType: jsonMimeType,
+		}, b.readEntityResource)
+	}
+}
+
+// resourceEntitySets lists the entity sets exposed as resources in alphabetical order
+func (b *ODataMCPBridge) resourceEntitySets() []string {
+	names := make([]string, 0, len(b.metadata.EntitySets))
+	for name := range b.metadata.EntitySets {
+		if b.shouldIncludeEntity(name) && b.policy.AllowsEntitySet(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// metadataDocument describes the entity sets, types and functions the bridge exposes.
+// Entity sets and functions hidden by filters or the access policy are left out, so the
+// raw $metadata XML is not passed on.
+func (b *ODataMCPBridge) metadataDocument() map[string]interface{} {
+	entitySets := make(map[string]*models.EntitySet)
+	entityTypes := make(map[string]*models.EntityType)
+	for _, name := range b.resourceEntitySets() {
+		entitySet := b.metadata.EntitySets[name]
+		entitySets[name] = entitySet
+		if entityType := b.entityTypeOf(name); entityType != nil {
+			entityTypes[entitySet.EntityType] = entityType
+		}
+	}
+
+	functions := make(map[string]*models.FunctionImport)
+	for name, function := range b.metadata.FunctionImports {
+		if b.shouldIncludeFunction(name) && b.policy.AllowsFunction(name) {
+			functions[name] = function
+		}
+	}
+
+	return map[string]interface{}{
+		"service_url":      b.config.ServiceURL,
+		"version":          b.metadata.Version,
+		"schema_namespace": b.metadata.SchemaNamespace,
+		"container_name":   b.metadata.ContainerName,
+		"entity_sets":      entitySets,
+		"entity_types":     entityTypes,
+		"function_imports": functions,
+	}
+}
+
+// schemaDocument describes one entity set with the properties the access policy lets reads return
+func (b *ODataMCPBridge) schemaDocument(entitySetName string) map[string]interface{} {
+	doc := map[string]interface{}{
+		"entity_set": b.metadata.EntitySets[entitySetName],
+	}
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil {
+		return doc
+	}
+
+	rule := b.policy.EntitySet(entitySetName)
+	properties := make([]*models.EntityProperty, 0, len(entityType.Properties))
+	for _, prop := range entityType.Properties {
+		if rule.AllowsProperty(policy.UsageSelect, prop.Name) {
+			properties = append(properties, prop)
+		}
+	}
+	navigation := make([]*models.NavigationProperty, 0, len(entityType.NavigationProps))
+	for _, nav := range entityType.NavigationProps {
+		if rule.AllowsProperty(policy.UsageSelect, nav.Name) {
+			navigation = append(navigation, nav)
+		}
+	}
+	doc["entity_type"] = &models.EntityType{
+		Name:            entityType.Name,
+		Properties:      properties,
+		KeyProperties:   entityType.KeyProperties,
+		Description:     entityType.Description,
+		NavigationProps: navigation,
+	}
+	if note := b.policyToolNote(entitySetName); note != "" {
+		doc["access_policy"] = strings.TrimSuffix(strings.TrimPrefix(note, " (access policy: "), ")")
+	}
+	return doc
+}
+
+// readEntityResource reads an entity named by odata://{service}/{EntitySet}({key})
+func (b *ODataMCPBridge) readEntityResource(ctx context.Context, uri string) (*mcp.ResourceContents, error) {
+	path := strings.TrimPrefix(uri, b.resourceURI(""))
+	open := strings.Index(path, "(")
+	if open <= 0 || !strings.HasSuffix(path, ")") {
+		return nil, fmt.Errorf("%w: %s", mcp.ErrResourceNotFound, uri)
+	}
+	entitySetName := path[:open]
+
+	entityType := b.entityTypeOf(entitySetName)
+	if entityType == nil || !b.shouldIncludeEntity(entitySetName) || !b.policy.AllowsEntitySet(entitySetName) {
+		return nil, fmt.Errorf("%w: %s", mcp.ErrResourceNotFound, uri)
+	}
+
+	predicate, err := url.PathUnescape(path[open+1 : len(path)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key predicate in %s: %w", uri, err)
+	}
+	key, err := parseKeyPredicate(predicate, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key predicate in %s: %w", uri, err)
+	}
+
+	response, err := b.getEntity(ctx, entitySetName, key, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+	return &mcp.ResourceContents{
+		URI:      uri,
+		MimeType: jsonMimeType,
+		Text:     string(text),
+		ETag:     entityETag(response),
+	}, nil
+}
+
+// parseKeyPredicate parses an OData key predicate, either a single value such as 1 or
+// 'ALFKI', or Name=value pairs for composite keys. Typed literals such as
+// guid'...' are passed on as their string value.
+func parseKeyPredicate(predicate string, entityType *models.EntityType) (map[string]interface{}, error) {
+	parts, err := splitKeyPredicate(predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make(map[string]interface{})
+	if len(parts) == 1 && !strings.Contains(unquotedPrefix(parts[0]), "=") {
+		if len(entityType.KeyProperties) != 1 {
+			return nil, fmt.Errorf("%s has a composite key; use Name=value pairs", entityType.Name)
+		}
+		value, err := parseKeyValue(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		key[entityType.KeyProperties[0]] = value
+		return key, nil
+	}
+
+	for _, part := range parts {
+		name, literal, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected Name=value, got %q", part)
+		}
+		value, err := parseKeyValue(literal)
+		if err != nil {
+			return nil, err
+		}
+		key[strings.TrimSpace(name)] = value
+	}
+	for _, keyProp := range entityType.KeyProperties {
+		if _, exists := key[keyProp]; !exists {
+			return nil, fmt.Errorf("missing required key property: %s", keyProp)
+		}
+	}
+	if len(key) != len(entityType.KeyProperties) {
+		return nil, fmt.Errorf("key properties of %s are %s", entityType.Name, strings.Join(entityType.KeyProperties, ", "))
+	}
+	return key, nil
+}
+
+// splitKeyPredicate splits a key predicate at commas outside quoted strings
+func splitKeyPredicate(predicate string) ([]string, error) {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(predicate); i++ {
+		switch predicate[i] {
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				parts = append(parts, predicate[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated string in %q", predicate)
+	}
+	parts = append(parts, predicate[start:])
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return nil, fmt.Errorf("empty key value in %q", predicate)
+		}
+	}
+	return parts, nil
+}
+
+// unquotedPrefix returns the part of a key literal before its first quote
+func unquotedPrefix(literal string) string {
+	if i := strings.Index(literal, "'"); i >= 0 {
+		return literal[:i]
+	}
+	return literal
+}
+
+// parseKeyValue converts a key literal to the value a tool call would pass: quoted and
+// typed literals become strings, numbers int64 or float64, and true/false bools
+func parseKeyValue(literal string) (interface{}, error) {
+	literal = strings.TrimSpace(literal)
+	if quote := strings.Index(literal, "'"); quote >= 0 {
+		if !strings.HasSuffix(literal, "'") || len(literal) < quote+2 {
+			return nil, fmt.Errorf("malformed string literal %s", literal)
+		}
+		return strings.ReplaceAll(literal[quote+1:len(literal)-1], "''", "'"), nil
+	}
+	switch literal {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported key literal %s", literal)
+}
+
+// jsonContents formats a value as JSON resource contents
+func jsonContents(uri string, value interface{}) (*mcp.ResourceContents, error) {
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format resource: %w", err)
+	}
+	return &mcp.ResourceContents{URI: uri, MimeType: jsonMimeType, Text: string(text)}, nil
+}
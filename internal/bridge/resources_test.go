@@ -0,0 +1,242 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/hint"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// resourceRequest sends a resources/* request and returns its result or error
+func resourceRequest(t *testing.T, bridge *ODataMCPBridge, method string, params map[string]interface{}) (map[string]interface{}, *transport.Error) {
+	t.Helper()
+	raw, _ := json.Marshal(params)
+	resp, err := bridge.server.HandleMessage(context.Background(), &transport.Message{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage("1"),
+		Method:  method,
+		Params:  raw,
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("invalid %s result: %v", method, err)
+	}
+	return result, nil
+}
+
+// initializeClient starts the session of the test client, as its first request would
+func initializeClient(t *testing.T, bridge *ODataMCPBridge, protocolVersion string) {
+	t.Helper()
+	if _, rpcErr := resourceRequest(t, bridge, "initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "test-client", "version": "1.0"},
+	}); rpcErr != nil {
+		t.Fatalf("initialize failed: %+v", rpcErr)
+	}
+}
+
+// readText reads a resource and returns the text of its only contents
+func readText(t *testing.T, bridge *ODataMCPBridge, uri string) string {
+	t.Helper()
+	result, rpcErr := resourceRequest(t, bridge, "resources/read", map[string]interface{}{"uri": uri})
+	if rpcErr != nil {
+		t.Fatalf("read %s failed: %+v", uri, rpcErr)
+	}
+	contents, _ := result["contents"].([]interface{})
+	if len(contents) != 1 {
+		t.Fatalf("expected one contents entry for %s, got %v", uri, result)
+	}
+	entry := contents[0].(map[string]interface{})
+	if entry["uri"] != uri || entry["mimeType"] != "application/json" {
+		t.Errorf("unexpected contents: %v", entry)
+	}
+	return entry["text"].(string)
+}
+
+func TestParseKeyPredicate(t *testing.T) {
+	metadata := createTestMetadata()
+	product := metadata.EntityTypes["Product"]
+	orderDetail := metadata.EntityTypes["OrderDetail"]
+
+	tests := []struct {
+		predicate string
+		want      map[string]interface{}
+		wantErr   bool
+	}{
+		{"7", map[string]interface{}{"ProductID": int64(7)}, false},
+		{"'O''Brien, Ltd'", map[string]interface{}{"ProductID": "O'Brien, Ltd"}, false},
+		{"guid'069f2c5e-2738-1eeb-b7bd-cd0f34d2052d'", map[string]interface{}{"ProductID": "069f2c5e-2738-1eeb-b7bd-cd0f34d2052d"}, false},
+		{"ProductID=7", map[string]interface{}{"ProductID": int64(7)}, false},
+		{"1.5", map[string]interface{}{"ProductID": 1.5}, false},
+		{"'unterminated", nil, true},
+		{"Name", nil, true},
+		{"ProductName='Chai'", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseKeyPredicate(tt.predicate, product)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseKeyPredicate(%q) error = %v, wantErr %v", tt.predicate, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeyPredicate(%q) = %v, want %v", tt.predicate, got, tt.want)
+		}
+	}
+
+	got, err := parseKeyPredicate("OrderID=10248, ProductID=11", orderDetail)
+	if err != nil || !reflect.DeepEqual(got, map[string]interface{}{"OrderID": int64(10248), "ProductID": int64(11)}) {
+		t.Errorf("composite key = %v, %v", got, err)
+	}
+	if _, err := parseKeyPredicate("10248", orderDetail); err == nil {
+		t.Error("expected an error for a single value on a composite key")
+	}
+	if _, err := parseKeyPredicate("OrderID=10248", orderDetail); err == nil || !strings.Contains(err.Error(), "missing required key property: ProductID") {
+		t.Errorf("expected missing key property, got %v", err)
+	}
+}
+
+func TestResources(t *testing.T) {
+	var requests []*http.Request
+	bridge := newPolicyTestBridge(t, &requests)
+	bridge.config.ServiceURL = "https://example.com/V2/Northwind/Northwind.svc/"
+	bridge.generateResources()
+	initializeClient(t, bridge, "2024-11-05")
+
+	result, _ := resourceRequest(t, bridge, "resources/list", nil)
+	var uris []string
+	for _, res := range result["resources"].([]interface{}) {
+		uris = append(uris, res.(map[string]interface{})["uri"].(string))
+	}
+	want := []string{"odata://northwind/$metadata", "odata://northwind/OrderDetails/$schema", "odata://northwind/Products/$schema"}
+	if !reflect.DeepEqual(uris, want) {
+		t.Errorf("resources = %v, want %v", uris, want)
+	}
+
+	result, _ = resourceRequest(t, bridge, "resources/templates/list", nil)
+	templates := result["resourceTemplates"].([]interface{})
+	if len(templates) != 1 || templates[0].(map[string]interface{})["uriTemplate"] != "odata://northwind/{EntitySet}({key})" {
+		t.Errorf("unexpected templates: %v", templates)
+	}
+
+	// Entity sets and functions hidden by the policy are not described
+	metadata := readText(t, bridge, "odata://northwind/$metadata")
+	if strings.Contains(metadata, "Categories") || strings.Contains(metadata, "GetProductsByCategory") || !strings.Contains(metadata, "OrderDetails") {
+		t.Errorf("unexpected metadata document: %s", metadata)
+	}
+
+	var schema struct {
+		EntityType struct {
+			Properties []struct {
+				Name string `json:"name"`
+			} `json:"properties"`
+		} `json:"entity_type"`
+		AccessPolicy string `json:"access_policy"`
+	}
+	json.Unmarshal([]byte(readText(t, bridge, "odata://northwind/Products/$schema")), &schema)
+	if len(schema.EntityType.Properties) != 2 || schema.EntityType.Properties[1].Name != "ProductName" || !strings.Contains(schema.AccessPolicy, "always filtered by Price lt 100") {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+
+	// Entities are read like the get tool, including the mandatory filter
+	entity := readText(t, bridge, "odata://northwind/Products(7)")
+	if !strings.Contains(entity, "Chai") {
+		t.Errorf("unexpected entity: %s", entity)
+	}
+	if query := lastQuery(t, requests); query.Get("$filter") != "(ProductID eq 7) and (Price lt 100)" {
+		t.Errorf("unexpected entity query: %v", query)
+	}
+
+	for _, uri := range []string{"odata://northwind/Categories(1)", "odata://northwind/Unknown(1)", "odata://other/$metadata"} {
+		sent := len(requests)
+		if _, rpcErr := resourceRequest(t, bridge, "resources/read", map[string]interface{}{"uri": uri}); rpcErr == nil || rpcErr.Code != -32002 {
+			t.Errorf("%s: expected resource not found, got %+v", uri, rpcErr)
+		}
+		if len(requests) != sent {
+			t.Errorf("%s: nothing may be sent for an unknown resource", uri)
+		}
+	}
+}
+
+// notifyingTransport collects the notifications a server sends
+type notifyingTransport struct {
+	notifications chan *transport.Message
+}
+
+func (n *notifyingTransport) Start(ctx context.Context) error          { return nil }
+func (n *notifyingTransport) ReadMessage() (*transport.Message, error) { return nil, io.EOF }
+func (n *notifyingTransport) Close() error                             { return nil }
+
+func (n *notifyingTransport) WriteMessage(msg *transport.Message) error {
+	n.notifications <- msg
+	return nil
+}
+
+func TestResourceSubscription(t *testing.T) {
+	var version atomic.Int32
+	version.Store(1)
+	bridge := newServiceTestBridge(t, &config.Config{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", fmt.Sprintf(`W/"%d"`, version.Load()))
+		w.Write([]byte(`{"d":{"ProductID":7,"ProductName":"Chai"}}`))
+	}))
+	bridge.hintManager = hint.NewManager()
+	bridge.generateResources()
+	defer bridge.server.Stop()
+
+	fake := &notifyingTransport{notifications: make(chan *transport.Message, 10)}
+	bridge.server.SetTransport(fake)
+	bridge.server.SetResourcePollInterval(10 * time.Millisecond)
+	initializeClient(t, bridge, "2024-11-05")
+
+	uri := bridge.resourceURI("Products(7)")
+	if _, rpcErr := resourceRequest(t, bridge, "resources/subscribe", map[string]interface{}{"uri": uri}); rpcErr != nil {
+		t.Fatalf("subscribe failed: %+v", rpcErr)
+	}
+
+	// An unchanged ETag sends nothing
+	select {
+	case msg := <-fake.notifications:
+		t.Fatalf("unexpected notification: %s", msg.Params)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	version.Store(2)
+	select {
+	case msg := <-fake.notifications:
+		if msg.Method != "notifications/resources/updated" || !strings.Contains(string(msg.Params), uri) {
+			t.Errorf("unexpected notification: %s %s", msg.Method, msg.Params)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no notification after the entity changed")
+	}
+
+	if _, rpcErr := resourceRequest(t, bridge, "resources/unsubscribe", map[string]interface{}{"uri": uri}); rpcErr != nil {
+		t.Fatalf("unsubscribe failed: %+v", rpcErr)
+	}
+	version.Store(3)
+	select {
+	case msg := <-fake.notifications:
+		t.Errorf("notification after unsubscribe: %s", msg.Params)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
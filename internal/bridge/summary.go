@@ -0,0 +1,301 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/utils"
+)
+
+// summaryInputProperties returns the schema properties that switch a filter/list tool to summarize mode
+func summaryInputProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"summarize": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Return per-column statistics (counts, distinct values, min/max, sum/avg, most frequent values, date ranges) over all matching rows instead of the rows themselves",
+			"default":     false,
+		},
+		"summary_top_values": map[string]interface{}{
+			"type":        "integer",
+			"description": fmt.Sprintf("Number of most frequent values reported per column in summarize mode (default: %d, max: %d)", constants.DefaultSummaryTopValues, constants.MaxSummaryTopValues),
+		},
+	}
+}
+
+// valueFrequency is one entry of a column's most frequent values
+type valueFrequency struct {
+	Value interface{} `json:"value"`
+	Count int         `json:"count"`
+}
+
+// columnSummary is the result reported for one column
+type columnSummary struct {
+	Type           string           `json:"type,omitempty"`
+	Count          int              `json:"count"`
+	Nulls          int              `json:"nulls"`
+	Distinct       int              `json:"distinct"`
+	DistinctCapped bool             `json:"distinct_capped,omitempty"`
+	Min            interface{}      `json:"min,omitempty"`
+	Max            interface{}      `json:"max,omitempty"`
+	Sum            *float64         `json:"sum,omitempty"`
+	Avg            *float64         `json:"avg,omitempty"`
+	Earliest       string           `json:"earliest,omitempty"`
+	Latest         string           `json:"latest,omitempty"`
+	TopValues      []valueFrequency `json:"top_values,omitempty"`
+}
+
+// columnAccumulator collects the running statistics of one column
+type columnAccumulator struct {
+	edmType  string
+	numeric  bool
+	date     bool
+	count    int
+	nulls    int
+	sum      float64
+	numbers  int
+	min      interface{}
+	max      interface{}
+	earliest string
+	latest   string
+	freq     map[string]*valueFrequency
+	capped   bool
+}
+
+func newColumnAccumulator(edmType string) *columnAccumulator {
+	return &columnAccumulator{
+		edmType: edmType,
+		numeric: isNumericEdmType(edmType),
+		date:    isDateEdmType(edmType),
+		freq:    make(map[string]*valueFrequency),
+	}
+}
+
+func (c *columnAccumulator) add(value interface{}) {
+	if value == nil {
+		c.nulls++
+		return
+	}
+	c.count++
+
+	// Expanded navigation properties and complex values are only counted
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return
+	}
+
+	if s, ok := value.(string); ok && (c.date || utils.IsODataLegacyDate(s)) {
+		iso := utils.ConvertODataLegacyToISO(s)
+		if c.earliest == "" || iso < c.earliest {
+			c.earliest = iso
+		}
+		if c.latest == "" || iso > c.latest {
+			c.latest = iso
+		}
+		value = iso
+	} else if n, ok := toFloat(value); ok && (c.numeric || !isString(value)) {
+		// Decimal strings count as numbers only when metadata says the column is numeric
+		c.sum += n
+		c.numbers++
+	}
+
+	if c.min == nil || compareValues(value, c.min) < 0 {
+		c.min = value
+	}
+	if c.max == nil || compareValues(value, c.max) > 0 {
+		c.max = value
+	}
+
+	key := fmt.Sprintf("%T:%v", value, value)
+	if f, exists := c.freq[key]; exists {
+		f.Count++
+	} else if len(c.freq) < constants.MaxSummaryDistinctValues {
+		c.freq[key] = &valueFrequency{Value: value, Count: 1}
+	} else {
+		c.capped = true
+	}
+}
+
+func (c *columnAccumulator) result(topN int) *columnSummary {
+	summary := &columnSummary{
+		Type:           c.edmType,
+		Count:          c.count,
+		Nulls:          c.nulls,
+		Distinct:       len(c.freq),
+		DistinctCapped: c.capped,
+	}
+
+	if c.earliest != "" {
+		summary.Earliest = c.earliest
+		summary.Latest = c.latest
+	} else {
+		summary.Min = c.min
+		summary.Max = c.max
+	}
+
+	if c.numbers > 0 {
+		sum := c.sum
+		avg := c.sum / float64(c.numbers)
+		summary.Sum = &sum
+		summary.Avg = &avg
+	}
+
+	if topN > 0 && len(c.freq) > 0 {
+		values := make([]valueFrequency, 0, len(c.freq))
+		for _, f := range c.freq {
+			values = append(values, *f)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return compareValues(values[i].Value, values[j].Value) < 0
+		})
+		if len(values) > topN {
+			values = values[:topN]
+		}
+		summary.TopValues = values
+	}
+
+	return summary
+}
+
+// summarizeEntities pages through an entity set within the summarize row budget and
+// returns per-column statistics instead of rows
+func (b *ODataMCPBridge) summarizeEntities(ctx context.Context, entitySetName string, options map[string]string, mappedArgs map[string]interface{}) (interface{}, error) {
+	topN := constants.DefaultSummaryTopValues
+	if n, ok := mappedArgs["summary_top_values"].(float64); ok {
+		topN = int(n)
+		if topN < 0 {
+			topN = 0
+		}
+		if topN > constants.MaxSummaryTopValues {
+			topN = constants.MaxSummaryTopValues
+		}
+	}
+
+	budget := b.config.SummarizeMaxRows
+	if budget <= 0 {
+		budget = constants.DefaultSummarizeMaxRows
+	}
+	maxRows := budget
+	if top, ok := options[constants.QueryTop]; ok {
+		// An explicit $top narrows the budget but never widens it
+		var n int
+		if _, err := fmt.Sscanf(top, "%d", &n); err == nil && n > 0 && n < maxRows {
+			maxRows = n
+		}
+	}
+
+	// Column types from metadata, in declaration order
+	var entityType *models.EntityType
+	if entitySet, exists := b.metadata.EntitySets[entitySetName]; exists {
+		entityType = b.metadata.EntityTypes[entitySet.EntityType]
+	}
+	edmTypes := make(map[string]string)
+	var order []string
+	if entityType != nil {
+		for _, prop := range entityType.Properties {
+			edmTypes[prop.Name] = prop.Type
+			order = append(order, prop.Name)
+		}
+	}
+
+	columns := make(map[string]*columnAccumulator)
+	rowsSeen := 0
+	rows, truncated, err := b.forEachPage(ctx, entitySetName, options, maxRows, func(page []interface{}) error {
+		for _, row := range page {
+			entity, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rowsSeen++
+			for name, value := range entity {
+				if name == "__metadata" || strings.HasPrefix(name, "@") {
+					continue
+				}
+				col, exists := columns[name]
+				if !exists {
+					col = newColumnAccumulator(edmTypes[name])
+					// Columns missing from earlier rows were absent, i.e. null
+					col.nulls = rowsSeen - 1
+					columns[name] = col
+				}
+				col.add(value)
+			}
+			// Columns absent from this row count as null
+			for name, col := range columns {
+				if _, present := entity[name]; !present {
+					col.nulls++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if b.config.VerboseErrors {
+			return nil, fmt.Errorf("failed to summarize entities from %s with options %v: %w", entitySetName, options, err)
+		}
+		return nil, fmt.Errorf("failed to summarize entities: %w", err)
+	}
+
+	// Metadata order first, then anything else (e.g. expanded navigation properties)
+	names := make([]string, 0, len(columns))
+	for _, name := range order {
+		if _, exists := columns[name]; exists {
+			names = append(names, name)
+		}
+	}
+	var extra []string
+	for name := range columns {
+		if _, known := edmTypes[name]; !known {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	names = append(names, extra...)
+
+	summaries := make(map[string]*columnSummary, len(columns))
+	for _, name := range names {
+		summaries[name] = columns[name].result(topN)
+	}
+
+	result := map[string]interface{}{
+		"entity_set":      entitySetName,
+		"rows_summarized": rows,
+		"columns":         summaries,
+		"column_order":    names,
+	}
+	if truncated && maxRows == budget {
+		result["truncated"] = true
+		result["warning"] = fmt.Sprintf("Summary covers the first %d matching rows (--summarize-max-rows); narrow the filter for complete statistics.", rows)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format response: %w", err)
+	}
+
+	return string(response), nil
+}
+
+// isDateEdmType reports whether an Edm type holds dates or timestamps
+func isDateEdmType(edmType string) bool {
+	switch edmType {
+	case "Edm.DateTime", "Edm.DateTimeOffset", "Edm.Date":
+		return true
+	}
+	return false
+}
+
+func isString(value interface{}) bool {
+	_, ok := value.(string)
+	return ok
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/config"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+type summaryResult struct {
+	RowsSummarized int                       `json:"rows_summarized"`
+	Truncated      bool                      `json:"truncated"`
+	Warning        string                    `json:"warning"`
+	Columns        map[string]*columnSummary `json:"columns"`
+	ColumnOrder    []string                  `json:"column_order"`
+}
+
+func TestHandleEntityFilterSummarize(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"ProductID": 1, "ProductName": "Chai", "Price": "18.00", "Released": "/Date(1704067200000)/"},
+		{"ProductID": 2, "ProductName": "Chang", "Price": "19.50", "Released": "/Date(1706745600000)/"},
+		{"ProductID": 3, "ProductName": "Chai", "Price": nil, "Released": "/Date(1701388800000)/"},
+		{"ProductID": 4, "ProductName": "Tofu", "Price": "2.50", "Released": nil},
+	}
+
+	var queries []string
+	bridge := newServiceTestBridge(t, &config.Config{}, aggregateTestHandler(rows, &queries))
+	bridge.metadata.EntityTypes["Product"].Properties = append(bridge.metadata.EntityTypes["Product"].Properties,
+		&models.EntityProperty{Name: "Released", Type: "Edm.DateTime", Nullable: true})
+
+	result, err := bridge.handleEntityFilter(context.Background(), "Products", map[string]interface{}{
+		"summarize":          true,
+		"summary_top_values": float64(1),
+	})
+	if err != nil {
+		t.Fatalf("handleEntityFilter() summarize error = %v", err)
+	}
+
+	var out summaryResult
+	if err := json.Unmarshal([]byte(result.(string)), &out); err != nil {
+		t.Fatalf("invalid JSON result: %v", err)
+	}
+
+	if out.RowsSummarized != 4 || out.Truncated {
+		t.Errorf("rows_summarized = %d, truncated = %v; want 4, false", out.RowsSummarized, out.Truncated)
+	}
+	if strings.Join(out.ColumnOrder, ",") != "ProductID,ProductName,Price,Released" {
+		t.Errorf("column_order = %v, want metadata order", out.ColumnOrder)
+	}
+
+	price := out.Columns["Price"]
+	if price.Count != 3 || price.Nulls != 1 || price.Sum == nil || *price.Sum != 40.0 || price.Min != "2.50" || price.Max != "19.50" {
+		t.Errorf("unexpected Price summary: %+v", price)
+	}
+
+	name := out.Columns["ProductName"]
+	if name.Distinct != 3 || name.Sum != nil || len(name.TopValues) != 1 || name.TopValues[0].Value != "Chai" || name.TopValues[0].Count != 2 {
+		t.Errorf("unexpected ProductName summary: %+v", name)
+	}
+
+	released := out.Columns["Released"]
+	if released.Earliest != "2023-12-01T00:00:00Z" || released.Latest != "2024-02-01T00:00:00Z" || released.Nulls != 1 {
+		t.Errorf("unexpected Released summary: %+v", released)
+	}
+}
+
+func TestHandleEntityFilterSummarizeBudget(t *testing.T) {
+	rows := make([]map[string]interface{}, 0, 30)
+	for i := 0; i < 30; i++ {
+		rows = append(rows, map[string]interface{}{"ProductID": i})
+	}
+
+	var queries []string
+	bridge := newServiceTestBridge(t, &config.Config{SummarizeMaxRows: 10}, aggregateTestHandler(rows, &queries))
+
+	// Budget exhausted: truncated with a warning
+	result, err := bridge.handleEntityFilter(context.Background(), "Products", map[string]interface{}{
+		"summarize": true,
+		"$skip":     float64(5),
+	})
+	if err != nil {
+		t.Fatalf("handleEntityFilter() summarize error = %v", err)
+	}
+	var out summaryResult
+	json.Unmarshal([]byte(result.(string)), &out)
+	if out.RowsSummarized != 10 || !out.Truncated || out.Warning == "" {
+		t.Errorf("expected truncation at budget, got %+v", out)
+	}
+	if out.Columns["ProductID"].Min != 5.0 {
+		t.Errorf("expected $skip to offset the scan, min = %v", out.Columns["ProductID"].Min)
+	}
+
+	// An explicit $top below the budget is honoured without a warning
+	result, err = bridge.handleEntityFilter(context.Background(), "Products", map[string]interface{}{
+		"summarize": true,
+		"$top":      float64(3),
+	})
+	if err != nil {
+		t.Fatalf("handleEntityFilter() summarize error = %v", err)
+	}
+	out = summaryResult{}
+	json.Unmarshal([]byte(result.(string)), &out)
+	if out.RowsSummarized != 3 || out.Truncated {
+		t.Errorf("expected 3 rows without truncation, got %+v", out)
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+)
+
+// ErrBatchNotSupported is returned when the service rejects $batch requests altogether
+var ErrBatchNotSupported = errors.New("$batch is not supported by the service")
+
+// BatchOperation is a single modifying request inside a changeset
+type BatchOperation struct {
+	Method    string                 // POST, PUT, PATCH, MERGE or DELETE
+	EntitySet string                 // Target entity set
+	Key       map[string]interface{} // Key values (nil for create)
+	Data      map[string]interface{} // Request body (nil for delete)
+}
+
+// BatchResult is the outcome of one operation in a changeset
+type BatchResult struct {
+	StatusCode int
+	Body       []byte
+	Err        error
+	RolledBack bool // The whole changeset failed; this operation was not applied
+}
+
+// ExecuteChangeset sends the operations as one atomic changeset in a multipart $batch request.
+// Changesets are all-or-nothing: when the service rejects one operation, every result
+// carries that error. The returned error is reserved for failures of the batch request itself.
+func (c *ODataClient) ExecuteChangeset(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	// Always fetch a fresh CSRF token for modifying operations (Python behavior)
+	if err := c.fetchCSRFToken(ctx); err != nil {
+		logger.InfoContext(ctx, "Failed to fetch CSRF token, proceeding without it", "error", err)
+		// Continue without token - some services might not require it
+	}
+
+	batchBoundary := "batch_" + randomBoundary()
+	changesetBoundary := "changeset_" + randomBoundary()
+
+	body, err := c.buildBatchBody(ops, batchBoundary, changesetBoundary)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.DebugContext(ctx, "Sending $batch", "operations", len(ops))
+
+	req, err := c.buildRequest(ctx, constants.POST, constants.BatchEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(constants.ContentType, "multipart/mixed; boundary="+batchBoundary)
+	req.ContentLength = int64(len(body))
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		return nil, fmt.Errorf("%w (HTTP %d)", ErrBatchNotSupported, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return nil, c.parseErrorFromBody(respBody, resp.StatusCode)
+	}
+
+	return c.parseBatchResponse(resp.Header.Get(constants.ContentType), respBody, len(ops))
+}
+
+// buildBatchBody renders the multipart/mixed $batch payload with a single changeset
+func (c *ODataClient) buildBatchBody(ops []BatchOperation, batchBoundary, changesetBoundary string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "--%s\r\n", batchBoundary)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", changesetBoundary)
+
+	accept := constants.ContentTypeJSON
+	if c.isV4 {
+		accept = constants.ContentTypeODataJSONV4
+	}
+
+	for i, op := range ops {
+		path := op.EntitySet
+		if len(op.Key) > 0 {
+			path = fmt.Sprintf("%s(%s)", op.EntitySet, c.buildKeyPredicate(op.Key))
+		}
+
+		fmt.Fprintf(&buf, "--%s\r\n", changesetBoundary)
+		buf.WriteString("Content-Type: application/http\r\n")
+		buf.WriteString("Content-Transfer-Encoding: binary\r\n")
+		fmt.Fprintf(&buf, "Content-ID: %d\r\n\r\n", i+1)
+
+		fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", op.Method, path)
+		fmt.Fprintf(&buf, "%s: %s\r\n", constants.Accept, accept)
+
+		if op.Data != nil {
+			jsonData, err := json.Marshal(op.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal entity data: %w", err)
+			}
+			fmt.Fprintf(&buf, "%s: %s\r\n", constants.ContentType, constants.ContentTypeJSON)
+			fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(jsonData))
+			buf.Write(jsonData)
+			buf.WriteString("\r\n")
+		} else {
+			buf.WriteString("\r\n")
+		}
+	}
+
+	fmt.Fprintf(&buf, "--%s--\r\n", changesetBoundary)
+	fmt.Fprintf(&buf, "\r\n--%s--\r\n", batchBoundary)
+
+	return buf.Bytes(), nil
+}
+
+// parseBatchResponse maps a multipart $batch response back to the changeset operations
+func (c *ODataClient) parseBatchResponse(contentType string, body []byte, count int) ([]BatchResult, error) {
+	boundary, err := multipartBoundary(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse $batch response: %w", err)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	part, err := reader.NextPart()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse $batch response: %w", err)
+	}
+
+	// A successful changeset comes back as a nested multipart with one response per operation
+	if nested, err := multipartBoundary(part.Header.Get(constants.ContentType)); err == nil {
+		results := make([]BatchResult, 0, count)
+		changeset := multipart.NewReader(part, nested)
+		for {
+			opPart, err := changeset.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse $batch changeset: %w", err)
+			}
+			results = append(results, c.readBatchPart(opPart))
+		}
+		if len(results) != count {
+			return nil, fmt.Errorf("$batch response has %d results for %d operations", len(results), count)
+		}
+		return results, nil
+	}
+
+	// A failed changeset is a single error response that applies to every operation
+	failure := c.readBatchPart(part)
+	if failure.Err == nil {
+		failure.Err = fmt.Errorf("changeset failed with HTTP %d", failure.StatusCode)
+	}
+	failure.RolledBack = true
+	results := make([]BatchResult, count)
+	for i := range results {
+		results[i] = failure
+	}
+	return results, nil
+}
+
+// readBatchPart parses an application/http part into a result
+func (c *ODataClient) readBatchPart(part *multipart.Part) BatchResult {
+	resp, err := http.ReadResponse(bufio.NewReader(part), nil)
+	if err != nil {
+		return BatchResult{Err: fmt.Errorf("failed to parse $batch part: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return BatchResult{StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to read $batch part: %w", err)}
+	}
+
+	result := BatchResult{StatusCode: resp.StatusCode, Body: body}
+	if resp.StatusCode >= 400 {
+		result.Err = c.parseErrorFromBody(body, resp.StatusCode)
+	}
+	return result
+}
+
+// multipartBoundary extracts the boundary of a multipart content type
+func multipartBoundary(contentType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return "", fmt.Errorf("not a multipart content type: %s", contentType)
+	}
+	return params["boundary"], nil
+}
+
+// randomBoundary returns a random multipart boundary suffix
+func randomBoundary() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "odata-mcp"
+	}
+	return hex.EncodeToString(b)
+}
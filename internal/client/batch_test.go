@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExecuteChangeset(t *testing.T) {
+	var batchBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/$batch") {
+			w.Header().Set("X-CSRF-Token", "token")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		batchBody = string(body)
+
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresp")
+		io.WriteString(w, "--batchresp\r\n"+
+			"Content-Type: multipart/mixed; boundary=csresp\r\n\r\n"+
+			"--csresp\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n"+
+			"HTTP/1.1 201 Created\r\nContent-Type: application/json\r\nContent-Length: 21\r\n\r\n{\"d\":{\"ProductID\":1}}\r\n"+
+			"--csresp\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n"+
+			"HTTP/1.1 204 No Content\r\n\r\n\r\n"+
+			"--csresp--\r\n"+
+			"--batchresp--\r\n")
+	}))
+	defer server.Close()
+
+	client := NewODataClient(server.URL, false)
+	results, err := client.ExecuteChangeset(context.Background(), []BatchOperation{
+		{Method: "POST", EntitySet: "Products", Data: map[string]interface{}{"ProductID": 1}},
+		{Method: "DELETE", EntitySet: "Products", Key: map[string]interface{}{"ProductID": 2}},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteChangeset() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].StatusCode != 201 || results[1].StatusCode != 204 || results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if !strings.Contains(batchBody, "POST Products HTTP/1.1") || !strings.Contains(batchBody, "DELETE Products(2) HTTP/1.1") {
+		t.Errorf("unexpected $batch body:\n%s", batchBody)
+	}
+}
+
+func TestExecuteChangesetFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/$batch") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		// A failed changeset is answered with a single error response
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresp")
+		io.WriteString(w, "--batchresp\r\n"+
+			"Content-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n"+
+			"HTTP/1.1 400 Bad Request\r\nContent-Type: application/json\r\n\r\n"+
+			"{\"error\":{\"code\":\"BAD\",\"message\":{\"value\":\"Invalid price\"}}}\r\n"+
+			"--batchresp--\r\n")
+	}))
+	defer server.Close()
+
+	client := NewODataClient(server.URL, false)
+	results, err := client.ExecuteChangeset(context.Background(), []BatchOperation{
+		{Method: "POST", EntitySet: "Products", Data: map[string]interface{}{"ProductID": 1}},
+		{Method: "POST", EntitySet: "Products", Data: map[string]interface{}{"ProductID": 2}},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteChangeset() error = %v", err)
+	}
+	for i, result := range results {
+		if !result.RolledBack || result.Err == nil || !strings.Contains(result.Err.Error(), "Invalid price") {
+			t.Errorf("result %d = %+v, want a rolled back failure", i, result)
+		}
+	}
+}
+
+func TestExecuteChangesetNotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/$batch") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewODataClient(server.URL, false)
+	_, err := client.ExecuteChangeset(context.Background(), []BatchOperation{{Method: "DELETE", EntitySet: "Products", Key: map[string]interface{}{"ProductID": 1}}})
+	if !errors.Is(err, ErrBatchNotSupported) {
+		t.Errorf("ExecuteChangeset() error = %v, want ErrBatchNotSupported", err)
+	}
+}
@@ -8,31 +8,36 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/zmcp/odata-mcp/internal/constants"
 	"github.com/zmcp/odata-mcp/internal/debug"
+	"github.com/zmcp/odata-mcp/internal/logging"
 	"github.com/zmcp/odata-mcp/internal/metadata"
 	"github.com/zmcp/odata-mcp/internal/models"
+	"github.com/zmcp/odata-mcp/internal/progress"
 )
 
+// logger is the logger of the client; request and response bodies are logged as payloads
+var logger = logging.Logger("client")
+
 // ODataClient handles HTTP communication with OData services
 type ODataClient struct {
-	baseURL        string
-	httpClient     *http.Client
-	cookies        map[string]string
-	username       string
-	password       string
-	csrfToken      string
-	verbose        bool
-	sessionCookies []*http.Cookie // Track session cookies from server
-	isV4           bool           // Whether the service is OData v4
-	retryConfig    *RetryConfig   // Retry configuration for failed requests
-	mu             sync.RWMutex   // Guards mutable fields: csrfToken, sessionCookies, cookies
+	baseURL      string
+	httpClient   *http.Client
+	cookies      map[string]string
+	username     string
+	password     string
+	verbose      bool
+	session      session             // CSRF token and session cookies of the configured credentials
+	userSessions map[string]*session // Sessions of per-request credentials, by Credentials.ID
+	isV4         bool                // Whether the service is OData v4
+	retryConfig  *RetryConfig        // Retry configuration for failed requests
+	mu           sync.RWMutex        // Guards mutable fields: sessions, cookies
 }
 
 // encodeQueryParams encodes URL query parameters with proper space encoding
@@ -124,41 +129,60 @@ func (c *ODataClient) buildRequest(ctx context.Context, method, endpoint string,
 		req.Header.Set(constants.Accept, constants.ContentTypeJSON)
 	}
 
-	// Set authentication
-	if c.username != "" && c.password != "" {
+	// Set authentication; per-request user credentials replace the configured ones
+	creds := credentialsFromContext(ctx)
+	switch {
+	case creds != nil && creds.Bearer != "":
+		req.Header.Set(constants.Authorization, "Bearer "+creds.Bearer)
+	case creds != nil:
+		req.SetBasicAuth(creds.Username, creds.Password)
+	case c.username != "" && c.password != "":
 		req.SetBasicAuth(c.username, c.password)
 	}
+	sess := c.sessionFor(ctx)
 
-	// Lock for reading mutable fields: cookies, sessionCookies, csrfToken
+	// Lock for reading mutable fields: cookies, session
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	// Set cookies
-	for name, value := range c.cookies {
-		req.AddCookie(&http.Cookie{
-			Name:  name,
-			Value: value,
-		})
+	// Set cookies (they authenticate the configured user)
+	if creds == nil {
+		for name, value := range c.cookies {
+			req.AddCookie(&http.Cookie{
+				Name:  name,
+				Value: value,
+			})
+		}
 	}
 
 	// Add session cookies received from server
-	for _, cookie := range c.sessionCookies {
+	for _, cookie := range sess.cookies {
 		req.AddCookie(cookie)
 	}
 
+	// Guard writes against concurrent changes when an ETag was supplied
+	if etag := ifMatchFromContext(ctx); etag != "" && method != constants.GET {
+		req.Header.Set(constants.IfMatch, etag)
+	}
+
 	// Set CSRF token if available
-	if c.csrfToken != "" {
-		req.Header.Set(constants.CSRFTokenHeader, c.csrfToken)
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Adding CSRF token to request: %s\n", debug.MaskToken(c.csrfToken))
-		}
+	if sess.csrfToken != "" {
+		req.Header.Set(constants.CSRFTokenHeader, sess.csrfToken)
+		logger.DebugContext(ctx, "Adding CSRF token to request", "csrf_token", sess.csrfToken)
 	}
 
 	return req, nil
 }
 
-// doRequest executes an HTTP request with retry and CSRF handling
+// doRequest executes an HTTP request with retry and CSRF handling. In dry-run mode a
+// modifying request is recorded instead of sent; reads are sent, so checks that precede
+// a write behave as they would for the real call.
 func (c *ODataClient) doRequest(req *http.Request) (*http.Response, error) {
+	return c.send(req, isModifyingMethod(req.Method))
+}
+
+// send executes an HTTP request; in dry-run mode it records an operation request instead
+func (c *ODataClient) send(req *http.Request, operation bool) (*http.Response, error) {
 	// For requests with body, we need to save it for potential retry
 	var bodyBytes []byte
 	if req.Body != nil && req.ContentLength > 0 {
@@ -170,7 +194,18 @@ func (c *ODataClient) doRequest(req *http.Request) (*http.Response, error) {
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 	}
 
-	return c.doRequestWithRetry(req, bodyBytes)
+	// In dry-run mode the fully built request is recorded instead of sent
+	if rec := DryRunFromContext(req.Context()); rec != nil && operation {
+		rec.capture(req, bodyBytes)
+		return nil, ErrDryRun
+	}
+
+	start := time.Now()
+	resp, err := c.doRequestWithRetry(req, bodyBytes)
+	if log := RequestLogFromContext(req.Context()); log != nil {
+		log.record(req, resp, err, time.Since(start))
+	}
+	return resp, err
 }
 
 // doRequestWithRetry executes an HTTP request with exponential backoff retry and CSRF handling
@@ -178,26 +213,19 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 	var lastErr error
 	var lastResp *http.Response
 	var lastBody []byte
+	retryReason := ""
 	csrfRetried := false
 
 	// Check if this is a modifying operation (for CSRF handling)
-	modifyingMethods := []string{"POST", "PUT", "MERGE", "PATCH", "DELETE"}
-	isModifying := false
-	for _, m := range modifyingMethods {
-		if req.Method == m {
-			isModifying = true
-			break
-		}
-	}
+	isModifying := isModifyingMethod(req.Method)
 
 	for attempt := 0; attempt <= c.retryConfig.MaxRetries; attempt++ {
 		// Wait before retry (skip first attempt)
 		if attempt > 0 {
 			backoff := c.retryConfig.CalculateBackoff(attempt - 1)
-			if c.verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Retry attempt %d/%d after %v\n",
-					attempt, c.retryConfig.MaxRetries, backoff)
-			}
+			logger.InfoContext(req.Context(), "Retrying request", "attempt", attempt, "max_retries", c.retryConfig.MaxRetries,
+				"backoff", backoff, "reason", retryReason)
+			progress.Report(req.Context(), 0, 0, fmt.Sprintf("retry %d/%d after %s", attempt, c.retryConfig.MaxRetries, retryReason))
 			select {
 			case <-req.Context().Done():
 				return nil, req.Context().Err()
@@ -211,16 +239,19 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 			req.ContentLength = int64(len(bodyBytes))
 		}
 
-		if c.verbose && attempt == 0 {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] %s %s\n", req.Method, debug.MaskURL(req.URL.String()))
+		if attempt == 0 {
+			logger.DebugContext(req.Context(), "Sending request", "method", req.Method, "url", req.URL.String())
 		}
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			lastErr = fmt.Errorf("HTTP request failed: %w", err)
-			if c.verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Request failed: %v\n", err)
+			// A cancelled request (e.g. notifications/cancelled) is not retried
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return nil, ctxErr
 			}
+			lastErr = fmt.Errorf("HTTP request failed: %w", err)
+			retryReason = "network error"
+			logger.InfoContext(req.Context(), "Request failed", "method", req.Method, "url", req.URL.String(), "error", err)
 			continue // Network error, retry
 		}
 
@@ -229,6 +260,7 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 		resp.Body.Close()
 		if readErr != nil {
 			lastErr = fmt.Errorf("failed to read response body: %w", readErr)
+			retryReason = "read error"
 			continue
 		}
 
@@ -238,13 +270,12 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 		// Check for CSRF failure (special handling, doesn't count toward retries)
 		if resp.StatusCode == http.StatusForbidden && isModifying && !csrfRetried {
 			if IsCSRFFailure(resp, respBody) {
-				if c.verbose {
-					fmt.Fprintf(os.Stderr, "[VERBOSE] CSRF token validation failed, attempting to refetch...\n")
-				}
+				logger.InfoContext(req.Context(), "CSRF token validation failed, fetching a new token")
 
 				csrfRetried = true
+				sess := c.sessionFor(req.Context())
 				c.mu.Lock()
-				c.csrfToken = ""
+				sess.csrfToken = ""
 				c.mu.Unlock()
 
 				// Try to fetch new CSRF token
@@ -255,11 +286,9 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 
 				// Update request with new CSRF token and retry (same attempt count)
 				c.mu.RLock()
-				req.Header.Set(constants.CSRFTokenHeader, c.csrfToken)
+				req.Header.Set(constants.CSRFTokenHeader, sess.csrfToken)
 				c.mu.RUnlock()
-				if c.verbose {
-					fmt.Fprintf(os.Stderr, "[VERBOSE] Retrying request with new CSRF token...\n")
-				}
+				logger.DebugContext(req.Context(), "Retrying request with new CSRF token")
 				attempt-- // Don't count CSRF retry toward max retries
 				continue
 			}
@@ -267,9 +296,8 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 
 		// Check if we should retry based on status code
 		if c.retryConfig.ShouldRetry(resp.StatusCode, attempt) {
-			if c.verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Received status %d, will retry\n", resp.StatusCode)
-			}
+			retryReason = strconv.Itoa(resp.StatusCode)
+			logger.InfoContext(req.Context(), "Service returned a retryable status", "status", resp.StatusCode)
 			continue
 		}
 
@@ -286,15 +314,28 @@ func (c *ODataClient) doRequestWithRetry(req *http.Request, bodyBytes []byte) (*
 	return nil, fmt.Errorf("all %d retries failed: %w", c.retryConfig.MaxRetries, lastErr)
 }
 
+// isModifyingMethod reports whether an HTTP method changes data
+func isModifyingMethod(method string) bool {
+	switch method {
+	case constants.POST, constants.PUT, constants.MERGE, constants.PATCH, constants.DELETE:
+		return true
+	}
+	return false
+}
+
 // fetchCSRFToken fetches a CSRF token from the service
 func (c *ODataClient) fetchCSRFToken(ctx context.Context) error {
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Fetching CSRF token...\n")
+	// Dry runs never contact the service
+	if DryRunFromContext(ctx) != nil {
+		return nil
 	}
 
+	logger.DebugContext(ctx, "Fetching CSRF token")
+
 	// Clear any existing CSRF token (Python behavior)
+	sess := c.sessionFor(ctx)
 	c.mu.Lock()
-	c.csrfToken = ""
+	sess.csrfToken = ""
 	c.mu.Unlock()
 
 	// Use service root for CSRF token fetching (more reliable than empty string)
@@ -305,9 +346,8 @@ func (c *ODataClient) fetchCSRFToken(ctx context.Context) error {
 
 	req.Header.Set(constants.CSRFTokenHeader, constants.CSRFTokenFetch)
 
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Token fetch request: %s %s\n", req.Method, debug.MaskURL(req.URL.String()))
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Token fetch headers: %s\n", maskHeaders(req.Header))
+	if logger.Enabled(ctx, logging.LevelDebug) {
+		logger.DebugContext(ctx, "Token fetch request", "method", req.Method, "url", req.URL.String(), "headers", maskHeaders(req.Header))
 	}
 
 	// Don't use doRequest here to avoid retry loops - fetch token requests shouldn't retry
@@ -320,19 +360,15 @@ func (c *ODataClient) fetchCSRFToken(ctx context.Context) error {
 	// Store any session cookies from the response
 	if cookies := resp.Cookies(); len(cookies) > 0 {
 		c.mu.Lock()
-		c.sessionCookies = append(c.sessionCookies, cookies...)
+		sess.cookies = append(sess.cookies, cookies...)
 		c.mu.Unlock()
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Received %d session cookies during token fetch\n", len(cookies))
-			for _, cookie := range cookies {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Cookie: %s=%s (Path=%s)\n", cookie.Name, debug.MaskToken(cookie.Value), cookie.Path)
-			}
+		for _, cookie := range cookies {
+			logger.DebugContext(ctx, "Received session cookie during token fetch", "name", cookie.Name, "value", debug.MaskToken(cookie.Value), "path", cookie.Path)
 		}
 	}
 
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Token fetch response status: %d\n", resp.StatusCode)
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Token fetch response headers: %s\n", maskHeaders(resp.Header))
+	if logger.Enabled(ctx, logging.LevelDebug) {
+		logger.DebugContext(ctx, "Token fetch response", "status", resp.StatusCode, "headers", maskHeaders(resp.Header))
 	}
 
 	// Check both possible header names (case variations)
@@ -354,11 +390,9 @@ func (c *ODataClient) fetchCSRFToken(ctx context.Context) error {
 	}
 
 	c.mu.Lock()
-	c.csrfToken = token
+	sess.csrfToken = token
 	c.mu.Unlock()
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] CSRF token fetched successfully: %s\n", debug.MaskToken(token))
-	}
+	logger.DebugContext(ctx, "CSRF token fetched", "csrf_token", token)
 
 	return nil
 }
@@ -410,9 +444,7 @@ func (c *ODataClient) GetMetadata(ctx context.Context) (*models.ODataMetadata, e
 	// Parse metadata XML
 	metadata, err := c.parseMetadataXML(body)
 	if err != nil {
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Metadata parsing failed: %v, attempting service document fallback...\n", err)
-		}
+		logger.WarnContext(ctx, "Metadata parsing failed, falling back to the service document", "error", err)
 		// Fallback to service document if metadata parsing fails
 		fallbackMeta, fallbackErr := c.getServiceDocument(ctx)
 		if fallbackErr != nil {
@@ -522,9 +554,7 @@ func (c *ODataClient) GetEntity(ctx context.Context, entitySet string, key map[s
 func (c *ODataClient) CreateEntity(ctx context.Context, entitySet string, data map[string]interface{}) (*models.ODataResponse, error) {
 	// Always fetch a fresh CSRF token for modifying operations (Python behavior)
 	if err := c.fetchCSRFToken(ctx); err != nil {
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to fetch CSRF token, proceeding without it: %v\n", err)
-		}
+		logger.InfoContext(ctx, "Failed to fetch CSRF token, proceeding without it", "error", err)
 		// Continue without token - some services might not require it
 	}
 
@@ -533,9 +563,7 @@ func (c *ODataClient) CreateEntity(ctx context.Context, entitySet string, data m
 		return nil, fmt.Errorf("failed to marshal entity data: %w", err)
 	}
 
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Creating entity with data: %s\n", string(jsonData))
-	}
+	logger.DebugContext(ctx, "Creating entity", "entity_set", entitySet, logging.KeyPayload, string(jsonData))
 
 	req, err := c.buildRequest(ctx, constants.POST, entitySet, bytes.NewReader(jsonData))
 	if err != nil {
@@ -559,9 +587,7 @@ func (c *ODataClient) CreateEntity(ctx context.Context, entitySet string, data m
 func (c *ODataClient) UpdateEntity(ctx context.Context, entitySet string, key map[string]interface{}, data map[string]interface{}, method string) (*models.ODataResponse, error) {
 	// Always fetch a fresh CSRF token for modifying operations (Python behavior)
 	if err := c.fetchCSRFToken(ctx); err != nil {
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to fetch CSRF token, proceeding without it: %v\n", err)
-		}
+		logger.InfoContext(ctx, "Failed to fetch CSRF token, proceeding without it", "error", err)
 		// Continue without token - some services might not require it
 	}
 
@@ -577,9 +603,7 @@ func (c *ODataClient) UpdateEntity(ctx context.Context, entitySet string, key ma
 		method = constants.PUT
 	}
 
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Updating entity with data: %s\n", string(jsonData))
-	}
+	logger.DebugContext(ctx, "Updating entity", "entity_set", entitySet, "method", method, logging.KeyPayload, string(jsonData))
 
 	req, err := c.buildRequest(ctx, method, endpoint, bytes.NewReader(jsonData))
 	if err != nil {
@@ -603,9 +627,7 @@ func (c *ODataClient) UpdateEntity(ctx context.Context, entitySet string, key ma
 func (c *ODataClient) DeleteEntity(ctx context.Context, entitySet string, key map[string]interface{}) (*models.ODataResponse, error) {
 	// Always fetch a fresh CSRF token for modifying operations (Python behavior)
 	if err := c.fetchCSRFToken(ctx); err != nil {
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to fetch CSRF token, proceeding without it: %v\n", err)
-		}
+		logger.InfoContext(ctx, "Failed to fetch CSRF token, proceeding without it", "error", err)
 		// Continue without token - some services might not require it
 	}
 
@@ -646,9 +668,7 @@ func (c *ODataClient) CallFunction(ctx context.Context, functionName string, par
 	} else {
 		// Always fetch a fresh CSRF token for modifying operations (Python behavior)
 		if err := c.fetchCSRFToken(ctx); err != nil {
-			if c.verbose {
-				fmt.Fprintf(os.Stderr, "[VERBOSE] Failed to fetch CSRF token, proceeding without it: %v\n", err)
-			}
+			logger.InfoContext(ctx, "Failed to fetch CSRF token, proceeding without it", "error", err)
 			// Continue without token - some services might not require it
 		}
 
@@ -658,9 +678,7 @@ func (c *ODataClient) CallFunction(ctx context.Context, functionName string, par
 			return nil, fmt.Errorf("failed to marshal function parameters: %w", marshalErr)
 		}
 
-		if c.verbose {
-			fmt.Fprintf(os.Stderr, "[VERBOSE] Calling function with data: %s\n", string(jsonData))
-		}
+		logger.DebugContext(ctx, "Calling function", "function", functionName, logging.KeyPayload, string(jsonData))
 
 		req, err = c.buildRequest(ctx, constants.POST, endpoint, bytes.NewReader(jsonData))
 		if err == nil {
@@ -674,7 +692,9 @@ func (c *ODataClient) CallFunction(ctx context.Context, functionName string, par
 		return nil, err
 	}
 
-	resp, err := c.doRequest(req)
+	// The call is what a dry run describes even over GET: functions may change data
+	// whatever their method
+	resp, err := c.send(req, true)
 	if err != nil {
 		return nil, err
 	}
@@ -750,18 +770,25 @@ func (c *ODataClient) parseODataResponse(resp *http.Response) (*models.ODataResp
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, fmt.Errorf("%w: %w", ErrPreconditionFailed, c.parseErrorFromBody(body, resp.StatusCode))
+	}
 	if resp.StatusCode >= 400 {
 		return nil, c.parseErrorFromBody(body, resp.StatusCode)
 	}
 
 	// Handle empty responses (e.g., from DELETE operations)
 	if len(body) == 0 {
-		return &models.ODataResponse{}, nil
+		return &models.ODataResponse{ETag: resp.Header.Get(constants.ETag)}, nil
 	}
 
 	// Log raw response for debugging
-	if c.verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] Raw response: %s\n", string(body))
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	if logger.Enabled(ctx, logging.LevelDebug) {
+		logger.DebugContext(ctx, "Received response", "status", resp.StatusCode, logging.KeyPayload, string(body))
 	}
 
 	// Parse using the appropriate parser
@@ -771,7 +798,7 @@ func (c *ODataClient) parseODataResponse(resp *http.Response) (*models.ODataResp
 	}
 
 	// Convert to ODataResponse model
-	var odataResp models.ODataResponse
+	odataResp := models.ODataResponse{ETag: resp.Header.Get(constants.ETag)}
 
 	switch v := parsedResponse.(type) {
 	case map[string]interface{}:
@@ -849,77 +876,12 @@ func (c *ODataClient) parseODataResponse(resp *http.Response) (*models.ODataResp
 func (c *ODataClient) parseError(resp *http.Response) error {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("HTTP %d: failed to read error response", resp.StatusCode)
+		return &ServiceError{StatusCode: resp.StatusCode, Body: "failed to read error response"}
 	}
 
 	return c.parseErrorFromBody(body, resp.StatusCode)
 }
 
-// parseErrorFromBody parses error from response body
-func (c *ODataClient) parseErrorFromBody(body []byte, statusCode int) error {
-	// Try to parse as JSON error
-	var errorResp struct {
-		Error *models.ODataError `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error != nil {
-		return c.buildDetailedError(errorResp.Error, statusCode, body)
-	}
-
-	// Fallback to generic error
-	return fmt.Errorf("HTTP %d: %s", statusCode, string(body))
-}
-
-// buildDetailedError creates a comprehensive error message from OData error details
-func (c *ODataClient) buildDetailedError(odataErr *models.ODataError, statusCode int, rawBody []byte) error {
-	var errMsg strings.Builder
-
-	// Start with basic error info
-	errMsg.WriteString(fmt.Sprintf("OData error (HTTP %d)", statusCode))
-
-	// Add error code if available
-	if odataErr.Code != "" {
-		errMsg.WriteString(fmt.Sprintf(" [%s]", odataErr.Code))
-	}
-
-	// Add main message
-	errMsg.WriteString(fmt.Sprintf(": %s", odataErr.Message))
-
-	// Add target if available (which field/entity caused the error)
-	if odataErr.Target != "" {
-		errMsg.WriteString(fmt.Sprintf(" (target: %s)", odataErr.Target))
-	}
-
-	// Add severity if available
-	if odataErr.Severity != "" {
-		errMsg.WriteString(fmt.Sprintf(" [severity: %s]", odataErr.Severity))
-	}
-
-	// Add details if available
-	if len(odataErr.Details) > 0 {
-		errMsg.WriteString(" | Details: ")
-		for i, detail := range odataErr.Details {
-			if i > 0 {
-				errMsg.WriteString("; ")
-			}
-			errMsg.WriteString(detail.Message)
-			if detail.Target != "" {
-				errMsg.WriteString(fmt.Sprintf(" (target: %s)", detail.Target))
-			}
-		}
-	}
-
-	// Add inner error info if available and verbose mode is on
-	if c.verbose && len(odataErr.InnerError) > 0 {
-		errMsg.WriteString(" | Inner error: ")
-		if innerErrBytes, err := json.Marshal(odataErr.InnerError); err == nil {
-			errMsg.WriteString(string(innerErrBytes))
-		}
-	}
-
-	return fmt.Errorf(errMsg.String())
-}
-
 // optimizeResponse applies optimizations to the response
 func (c *ODataClient) optimizeResponse(resp *models.ODataResponse) {
 	// TODO: Implement GUID conversion and other optimizations
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// userSessionIdle is how long the CSRF token and cookies of an inactive user are kept
+const userSessionIdle = time.Hour
+
+// Credentials authenticate the requests of one end user instead of the configured
+// technical user. Requests with different IDs never share CSRF tokens or session cookies.
+type Credentials struct {
+	ID       string // Stable user identifier selecting the user's session; never sent
+	Bearer   string // Access token sent as Authorization: Bearer
+	Username string // Basic authentication, used when Bearer is empty
+	Password string
+}
+
+type credentialsKey struct{}
+
+// WithCredentials returns a context whose requests authenticate as the given user
+func WithCredentials(ctx context.Context, creds *Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, creds)
+}
+
+func credentialsFromContext(ctx context.Context) *Credentials {
+	if ctx == nil {
+		return nil
+	}
+	creds, _ := ctx.Value(credentialsKey{}).(*Credentials)
+	return creds
+}
+
+// session is the CSRF token and cookies the service issued to one user
+type session struct {
+	csrfToken string
+	cookies   []*http.Cookie
+	used      time.Time
+}
+
+// sessionFor returns the session of the context's user, or the shared session of the
+// configured credentials
+func (c *ODataClient) sessionFor(ctx context.Context) *session {
+	creds := credentialsFromContext(ctx)
+	if creds == nil {
+		return &c.session
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	s, ok := c.userSessions[creds.ID]
+	if !ok {
+		for id, idle := range c.userSessions {
+			if now.Sub(idle.used) > userSessionIdle {
+				delete(c.userSessions, id)
+			}
+		}
+		if c.userSessions == nil {
+			c.userSessions = make(map[string]*session)
+		}
+		s = &session{}
+		c.userSessions[creds.ID] = s
+	}
+	s.used = now
+	return s
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCredentialsIsolateSessions(t *testing.T) {
+	var mu sync.Mutex
+	var problems []string
+
+	// The service issues CSRF tokens and session cookies bound to the authenticated user
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if u, _, ok := r.BasicAuth(); ok {
+			user = u
+		}
+		if r.Method == http.MethodGet && strings.EqualFold(r.Header.Get("X-CSRF-Token"), "Fetch") {
+			w.Header().Set("X-CSRF-Token", "token-"+user)
+			http.SetCookie(w, &http.Cookie{Name: "SAP_SESSIONID", Value: "session-" + user})
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		mu.Lock()
+		if token := r.Header.Get("X-CSRF-Token"); token != "token-"+user {
+			problems = append(problems, user+" sent CSRF token "+token)
+		}
+		if cookie, err := r.Cookie("SAP_SESSIONID"); err != nil || cookie.Value != "session-"+user {
+			problems = append(problems, user+" sent session cookie "+r.Header.Get("Cookie"))
+		}
+		if _, err := r.Cookie("MYSAPSSO2"); err == nil {
+			problems = append(problems, user+" sent the configured cookie")
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"d":{}}`))
+	}))
+	defer server.Close()
+
+	c := NewODataClient(server.URL, false)
+	c.SetBasicAuth("TECHUSER", "secret")
+	c.SetCookies(map[string]string{"MYSAPSSO2": "technical"})
+
+	users := []*Credentials{
+		{ID: "oauth:alice", Bearer: "alice-token"},
+		{ID: "basic:BOB", Username: "BOB", Password: "pw"},
+	}
+	for round := 0; round < 2; round++ {
+		for _, creds := range users {
+			ctx := WithCredentials(context.Background(), creds)
+			if _, err := c.CreateEntity(ctx, "Products", map[string]interface{}{"ID": 1}); err != nil {
+				t.Fatalf("CreateEntity(%s) error = %v", creds.ID, err)
+			}
+		}
+	}
+
+	if _, err := c.CreateEntity(context.Background(), "Products", map[string]interface{}{"ID": 1}); err != nil {
+		t.Fatalf("CreateEntity(technical user) error = %v", err)
+	}
+	for _, p := range problems {
+		if !strings.HasPrefix(p, "TECHUSER sent the configured cookie") {
+			t.Error(p)
+		}
+	}
+	if len(c.userSessions) != 2 {
+		t.Errorf("user sessions = %d, want 2", len(c.userSessions))
+	}
+	if c.session.csrfToken != "token-TECHUSER" {
+		t.Errorf("shared CSRF token = %q", c.session.csrfToken)
+	}
+}
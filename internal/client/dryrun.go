@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/debug"
+)
+
+// ErrDryRun is returned by request methods when the context is in dry-run mode.
+// The request was fully built but not sent; its details are in the DryRunRequest.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// DryRunRequest captures a resolved request instead of sending it
+type DryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context in which modifying requests are captured instead of sent.
+// If ctx is already in dry-run mode, its existing recorder is returned.
+func WithDryRun(ctx context.Context) (context.Context, *DryRunRequest) {
+	if rec := DryRunFromContext(ctx); rec != nil {
+		return ctx, rec
+	}
+	rec := &DryRunRequest{}
+	return context.WithValue(ctx, dryRunKey{}, rec), rec
+}
+
+// DryRunFromContext returns the dry-run recorder of ctx, or nil outside dry-run mode
+func DryRunFromContext(ctx context.Context) *DryRunRequest {
+	if ctx == nil {
+		return nil
+	}
+	rec, _ := ctx.Value(dryRunKey{}).(*DryRunRequest)
+	return rec
+}
+
+// capture records the request with sensitive header values masked
+func (d *DryRunRequest) capture(req *http.Request, body []byte) {
+	d.Method = req.Method
+	d.URL = debug.MaskURL(req.URL.String())
+	d.Headers = make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		value := req.Header.Get(name)
+		if name == "Cookie" {
+			// Session cookies carry credentials even though the header name does not say so
+			value = debug.MaskToken(value)
+		}
+		d.Headers[name] = debug.MaskHeader(name, value)
+	}
+
+	// The CSRF token is fetched right before a real modifying request is sent
+	if req.Method != constants.GET {
+		d.Headers[constants.CSRFTokenHeader] = "(fetched before sending)"
+	}
+
+	if len(body) > 0 {
+		if json.Valid(body) {
+			d.Body = json.RawMessage(body)
+		} else {
+			d.Body = string(body)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package client
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error categories of a ServiceError, by HTTP status
+const (
+	CategoryInvalidRequest     = "invalid_request"     // 400, 422: the model can fix the request
+	CategoryUnauthorized       = "unauthorized"        // 401
+	CategoryForbidden          = "forbidden"           // 403
+	CategoryNotFound           = "not_found"           // 404
+	CategoryNotSupported       = "not_supported"       // 405, 501
+	CategoryConflict           = "conflict"            // 409
+	CategoryPreconditionFailed = "precondition_failed" // 412, 428: the entity changed since it was read
+	CategoryThrottled          = "throttled"           // 429
+	CategoryUnavailable        = "unavailable"         // 502, 503, 504
+	CategoryServiceError       = "service_error"       // Other 5xx
+	CategoryRequestFailed      = "request_failed"      // Anything else
+)
+
+// ServiceError is an error response from the OData service: the HTTP status and what the
+// service said about it. SAP services add messages in innererror.errordetails and a
+// transaction ID that identifies the request in the backend logs.
+type ServiceError struct {
+	StatusCode    int
+	Code          string
+	Message       string
+	Target        string
+	Severity      string
+	Details       []ServiceErrorDetail
+	TransactionID string
+	InnerError    map[string]interface{} // Raw innererror, included in the message in verbose mode
+	Body          string                 // Response body, when it was not an OData error
+
+	verbose bool
+}
+
+// ServiceErrorDetail is one message of details (v4) or innererror.errordetails (SAP)
+type ServiceErrorDetail struct {
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Target   string `json:"target,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// Error returns the status, code, message, target and details on one line
+func (e *ServiceError) Error() string {
+	if e.Message == "" && e.Code == "" {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+	}
+
+	var errMsg strings.Builder
+	errMsg.WriteString(fmt.Sprintf("OData error (HTTP %d)", e.StatusCode))
+	if e.Code != "" {
+		errMsg.WriteString(fmt.Sprintf(" [%s]", e.Code))
+	}
+	errMsg.WriteString(fmt.Sprintf(": %s", e.Message))
+
+	// Which field or entity caused the error
+	if e.Target != "" {
+		errMsg.WriteString(fmt.Sprintf(" (target: %s)", e.Target))
+	}
+	if e.Severity != "" {
+		errMsg.WriteString(fmt.Sprintf(" [severity: %s]", e.Severity))
+	}
+
+	if len(e.Details) > 0 {
+		errMsg.WriteString(" | Details: ")
+		for i, detail := range e.Details {
+			if i > 0 {
+				errMsg.WriteString("; ")
+			}
+			errMsg.WriteString(detail.Message)
+			if detail.Target != "" {
+				errMsg.WriteString(fmt.Sprintf(" (target: %s)", detail.Target))
+			}
+		}
+	}
+	if e.TransactionID != "" {
+		errMsg.WriteString(fmt.Sprintf(" | Transaction ID: %s", e.TransactionID))
+	}
+
+	if e.verbose && len(e.InnerError) > 0 {
+		errMsg.WriteString(" | Inner error: ")
+		if innerErrBytes, err := json.Marshal(e.InnerError); err == nil {
+			errMsg.WriteString(string(innerErrBytes))
+		}
+	}
+	return errMsg.String()
+}
+
+// HTTPStatus returns the HTTP status code of the response
+func (e *ServiceError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Category classifies the error by its HTTP status
+func (e *ServiceError) Category() string {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CategoryInvalidRequest
+	case http.StatusUnauthorized:
+		return CategoryUnauthorized
+	case http.StatusForbidden:
+		return CategoryForbidden
+	case http.StatusNotFound:
+		return CategoryNotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return CategoryNotSupported
+	case http.StatusConflict:
+		return CategoryConflict
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return CategoryPreconditionFailed
+	case http.StatusTooManyRequests:
+		return CategoryThrottled
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CategoryUnavailable
+	}
+	if e.StatusCode >= 500 {
+		return CategoryServiceError
+	}
+	return CategoryRequestFailed
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *ServiceError) Retryable() bool {
+	switch e.Category() {
+	case CategoryThrottled, CategoryUnavailable:
+		return true
+	}
+	return false
+}
+
+// ErrorDetails returns the error as machine-readable fields for tool results
+func (e *ServiceError) ErrorDetails() map[string]interface{} {
+	details := map[string]interface{}{
+		"category":  e.Category(),
+		"status":    e.StatusCode,
+		"retryable": e.Retryable(),
+	}
+	if e.Code != "" {
+		details["code"] = e.Code
+	}
+	if e.Message != "" {
+		details["message"] = e.Message
+	} else if e.Body != "" {
+		details["message"] = e.Body
+	}
+	if e.Target != "" {
+		details["target"] = e.Target
+	}
+	if e.Severity != "" {
+		details["severity"] = e.Severity
+	}
+	if len(e.Details) > 0 {
+		details["details"] = e.Details
+	}
+	if e.TransactionID != "" {
+		details["transaction_id"] = e.TransactionID
+	}
+	return details
+}
+
+// parseErrorFromBody builds a ServiceError from an error response body: OData v4 JSON,
+// v2 JSON (message.value) or the XML error format some services use for $metadata and $batch
+func (c *ODataClient) parseErrorFromBody(body []byte, statusCode int) error {
+	svcErr := &ServiceError{StatusCode: statusCode, verbose: c.verbose}
+	if !parseJSONError(body, svcErr) && !parseXMLError(body, svcErr) {
+		svcErr.Body = string(body)
+	}
+	return svcErr
+}
+
+// parseJSONError fills e from a JSON error body and reports whether it was one
+func parseJSONError(body []byte, e *ServiceError) bool {
+	var errorResp struct {
+		Error *struct {
+			Code     string          `json:"code"`
+			Message  json.RawMessage `json:"message"`
+			Target   string          `json:"target"`
+			Severity string          `json:"severity"`
+			Details  []struct {
+				Code     string `json:"code"`
+				Message  string `json:"message"`
+				Target   string `json:"target"`
+				Severity string `json:"severity"`
+			} `json:"details"`
+			InnerError map[string]interface{} `json:"innererror"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err != nil || errorResp.Error == nil {
+		return false
+	}
+	odataErr := errorResp.Error
+
+	// v4 sends the message as a string, v2 as {"lang": "en", "value": "..."}
+	var message struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(odataErr.Message, &e.Message); err != nil {
+		if json.Unmarshal(odataErr.Message, &message) == nil {
+			e.Message = message.Value
+		}
+	}
+	if odataErr.Code == "" && e.Message == "" {
+		return false
+	}
+
+	e.Code = odataErr.Code
+	e.Target = odataErr.Target
+	e.Severity = odataErr.Severity
+	e.InnerError = odataErr.InnerError
+	for _, detail := range odataErr.Details {
+		e.addDetail(ServiceErrorDetail{Code: detail.Code, Message: detail.Message, Target: detail.Target, Severity: detail.Severity})
+	}
+	e.parseInnerError()
+	return true
+}
+
+// parseInnerError takes the transaction ID and errordetails messages from an SAP innererror
+func (e *ServiceError) parseInnerError() {
+	for key, value := range e.InnerError {
+		switch strings.ToLower(key) {
+		case "transactionid":
+			e.TransactionID, _ = value.(string)
+		case "errordetails":
+			entries, _ := value.([]interface{})
+			for _, entry := range entries {
+				fields, _ := entry.(map[string]interface{})
+				detail := ServiceErrorDetail{}
+				detail.Code, _ = fields["code"].(string)
+				detail.Message, _ = fields["message"].(string)
+				detail.Severity, _ = fields["severity"].(string)
+				// SAP names the field in propertyref, the entity in target
+				if detail.Target, _ = fields["propertyref"].(string); detail.Target == "" {
+					detail.Target, _ = fields["target"].(string)
+				}
+				e.addDetail(detail)
+			}
+		}
+	}
+}
+
+// addDetail adds a detail message, unless it repeats the main message
+func (e *ServiceError) addDetail(detail ServiceErrorDetail) {
+	if detail.Message == "" || (detail.Message == e.Message && detail.Code == e.Code) {
+		return
+	}
+	e.Details = append(e.Details, detail)
+}
+
+// parseXMLError fills e from an XML error body and reports whether it was one
+func parseXMLError(body []byte, e *ServiceError) bool {
+	var xmlErr struct {
+		XMLName    xml.Name `xml:"error"`
+		Code       string   `xml:"code"`
+		Message    string   `xml:"message"`
+		InnerError struct {
+			TransactionID string `xml:"transactionid"`
+			ErrorDetails  []struct {
+				Code        string `xml:"code"`
+				Message     string `xml:"message"`
+				PropertyRef string `xml:"propertyref"`
+				Target      string `xml:"target"`
+				Severity    string `xml:"severity"`
+			} `xml:"errordetails>errordetail"`
+		} `xml:"innererror"`
+	}
+	if err := xml.Unmarshal(body, &xmlErr); err != nil || (xmlErr.Code == "" && xmlErr.Message == "") {
+		return false
+	}
+
+	e.Code = xmlErr.Code
+	e.Message = strings.TrimSpace(xmlErr.Message)
+	e.TransactionID = xmlErr.InnerError.TransactionID
+	for _, detail := range xmlErr.InnerError.ErrorDetails {
+		target := detail.PropertyRef
+		if target == "" {
+			target = detail.Target
+		}
+		e.addDetail(ServiceErrorDetail{Code: detail.Code, Message: detail.Message, Target: target, Severity: detail.Severity})
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseServiceError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   ServiceError
+		text   string
+	}{
+		{
+			name:   "OData v4",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":"BAD","message":"Invalid property","target":"Price","details":[{"code":"D1","message":"Price must be positive","target":"Price"}]}}`,
+			want: ServiceError{
+				StatusCode: 400, Code: "BAD", Message: "Invalid property", Target: "Price",
+				Details: []ServiceErrorDetail{{Code: "D1", Message: "Price must be positive", Target: "Price"}},
+			},
+			text: "OData error (HTTP 400) [BAD]: Invalid property (target: Price) | Details: Price must be positive (target: Price)",
+		},
+		{
+			name:   "SAP v2 with errordetails",
+			status: http.StatusBadRequest,
+			body: `{"error":{"code":"SY/530","message":{"lang":"en","value":"Order 4711 is locked"},"innererror":{"transactionid":"0A1B2C","errordetails":[
+				{"code":"SY/530","message":"Order 4711 is locked","propertyref":"","severity":"error","target":""},
+				{"code":"V1/042","message":"Customer is blocked","propertyref":"CustomerID","severity":"error","target":"/Orders"}]}}}`,
+			want: ServiceError{
+				StatusCode: 400, Code: "SY/530", Message: "Order 4711 is locked", TransactionID: "0A1B2C",
+				Details: []ServiceErrorDetail{{Code: "V1/042", Message: "Customer is blocked", Target: "CustomerID", Severity: "error"}},
+			},
+			text: "OData error (HTTP 400) [SY/530]: Order 4711 is locked | Details: Customer is blocked (target: CustomerID) | Transaction ID: 0A1B2C",
+		},
+		{
+			name:   "XML",
+			status: http.StatusNotFound,
+			body: `<?xml version="1.0" encoding="utf-8"?><error xmlns="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata">
+				<code>/IWBEP/CM_MGW_RT/020</code><message xml:lang="en">Resource not found</message>
+				<innererror><transactionid>9F8E</transactionid></innererror></error>`,
+			want: ServiceError{StatusCode: 404, Code: "/IWBEP/CM_MGW_RT/020", Message: "Resource not found", TransactionID: "9F8E"},
+			text: "OData error (HTTP 404) [/IWBEP/CM_MGW_RT/020]: Resource not found | Transaction ID: 9F8E",
+		},
+		{
+			name:   "Plain text",
+			status: http.StatusBadGateway,
+			body:   "upstream unavailable",
+			want:   ServiceError{StatusCode: 502, Body: "upstream unavailable"},
+			text:   "HTTP 502: upstream unavailable",
+		},
+	}
+
+	c := NewODataClient("http://localhost", false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var svcErr *ServiceError
+			if !errors.As(c.parseErrorFromBody([]byte(tt.body), tt.status), &svcErr) {
+				t.Fatal("not a ServiceError")
+			}
+			svcErr.InnerError = nil
+			if !reflect.DeepEqual(*svcErr, tt.want) {
+				t.Errorf("parsed %+v, want %+v", *svcErr, tt.want)
+			}
+			if svcErr.Error() != tt.text {
+				t.Errorf("Error() = %q, want %q", svcErr.Error(), tt.text)
+			}
+		})
+	}
+}
+
+func TestServiceErrorCategory(t *testing.T) {
+	tests := map[int]string{
+		400: CategoryInvalidRequest,
+		401: CategoryUnauthorized,
+		404: CategoryNotFound,
+		412: CategoryPreconditionFailed,
+		429: CategoryThrottled,
+		500: CategoryServiceError,
+		503: CategoryUnavailable,
+	}
+	for status, want := range tests {
+		e := &ServiceError{StatusCode: status}
+		if got := e.Category(); got != want {
+			t.Errorf("Category() of HTTP %d = %s, want %s", status, got, want)
+		}
+		if got := e.ErrorDetails()["retryable"]; got != (status == 429 || status == 503) {
+			t.Errorf("retryable of HTTP %d = %v", status, got)
+		}
+	}
+}
+
+func TestPreconditionFailedIsServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.Header().Set("X-CSRF-Token", "token")
+			return
+		}
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte(`{"error":{"code":"ETAG","message":{"value":"Entity was modified"}}}`))
+	}))
+	defer server.Close()
+
+	c := NewODataClient(server.URL, false)
+	_, err := c.UpdateEntity(context.Background(), "Products", map[string]interface{}{"ID": 1}, map[string]interface{}{"Name": "x"}, "PATCH")
+	var svcErr *ServiceError
+	if !errors.Is(err, ErrPreconditionFailed) || !errors.As(err, &svcErr) {
+		t.Fatalf("expected ErrPreconditionFailed wrapping a ServiceError, got %v", err)
+	}
+	if svcErr.Category() != CategoryPreconditionFailed || !strings.Contains(err.Error(), "Entity was modified") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
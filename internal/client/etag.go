@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPreconditionFailed is returned when the service rejects a write because the entity's
+// ETag no longer matches the If-Match header (HTTP 412)
+var ErrPreconditionFailed = errors.New("precondition failed: the entity was changed since it was read")
+
+type ifMatchKey struct{}
+
+// WithIfMatch returns a context whose modifying requests carry an If-Match header, so the
+// service rejects them if the entity changed since the ETag was read
+func WithIfMatch(ctx context.Context, etag string) context.Context {
+	if etag == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ifMatchKey{}, etag)
+}
+
+// ifMatchFromContext returns the ETag set with WithIfMatch, or ""
+func ifMatchFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	etag, _ := ctx.Value(ifMatchKey{}).(string)
+	return etag
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/debug"
+)
+
+// LoggedRequest is one request sent to the service, as recorded for the audit log
+type LoggedRequest struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Location   string `json:"location,omitempty"` // Location header of a create response
+	Error      string `json:"error,omitempty"`
+}
+
+// RequestLog collects the requests sent on behalf of one tool call
+type RequestLog struct {
+	mu       sync.Mutex
+	requests []LoggedRequest
+}
+
+type requestLogKey struct{}
+
+// WithRequestLog returns a context in which every request sent is recorded.
+// If ctx already records requests, its existing log is returned.
+func WithRequestLog(ctx context.Context) (context.Context, *RequestLog) {
+	if log := RequestLogFromContext(ctx); log != nil {
+		return ctx, log
+	}
+	log := &RequestLog{}
+	return context.WithValue(ctx, requestLogKey{}, log), log
+}
+
+// RequestLogFromContext returns the request log of ctx, or nil if requests are not recorded
+func RequestLogFromContext(ctx context.Context) *RequestLog {
+	if ctx == nil {
+		return nil
+	}
+	log, _ := ctx.Value(requestLogKey{}).(*RequestLog)
+	return log
+}
+
+// Requests returns a copy of the recorded requests in the order they were sent
+func (l *RequestLog) Requests() []LoggedRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LoggedRequest(nil), l.requests...)
+}
+
+// record adds a finished request with its URL masked
+func (l *RequestLog) record(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	entry := LoggedRequest{
+		Method:     req.Method,
+		URL:        debug.MaskURL(req.URL.String()),
+		DurationMs: elapsed.Milliseconds(),
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		entry.Location = debug.MaskURL(resp.Header.Get("Location"))
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	l.mu.Lock()
+	l.requests = append(l.requests, entry)
+	l.mu.Unlock()
+}
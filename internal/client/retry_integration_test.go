@@ -11,6 +11,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/zmcp/odata-mcp/internal/progress"
 )
 
 func TestRetryWithMockServer(t *testing.T) {
@@ -327,3 +329,41 @@ func TestRetryContextCancellation(t *testing.T) {
 		t.Errorf("Expected fewer attempts due to cancellation, got %d", actualAttempts)
 	}
 }
+
+func TestRetryReportsProgress(t *testing.T) {
+	var attemptCount int32
+
+	// First attempt is throttled, the second succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attemptCount, 1) == 1 {
+			w.WriteHeader(429)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewODataClient(server.URL, false)
+	client.retryConfig = &RetryConfig{
+		MaxRetries:        3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        time.Millisecond,
+		BackoffMultiplier: 1.0,
+		RetryableStatuses: []int{429},
+	}
+
+	var messages []string
+	ctx := progress.WithReporter(context.Background(), func(_, _ float64, message string) {
+		messages = append(messages, message)
+	})
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if _, err := client.doRequestWithRetry(req, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 1 || messages[0] != "retry 1/3 after 429" {
+		t.Errorf("progress messages = %q", messages)
+	}
+}
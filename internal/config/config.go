@@ -4,8 +4,11 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
 )
 
 // Config holds all configuration options for the OData MCP bridge
@@ -38,6 +41,11 @@ type Config struct {
 	SortTools bool `mapstructure:"sort_tools"`
 	Trace     bool `mapstructure:"trace"`
 
+	// Logging
+	LogLevel  string `mapstructure:"log_level"`  // Minimum level written to stderr and the log file (default: warning, debug with --verbose)
+	LogFile   string `mapstructure:"log_file"`   // Also append log records to this file
+	LogFormat string `mapstructure:"log_format"` // Log record format: text or json (default: text)
+
 	// Response enhancement options
 	PaginationHints  bool `mapstructure:"pagination_hints"`  // Add pagination support with hints
 	LegacyDates      bool `mapstructure:"legacy_dates"`      // Support epoch timestamp format
@@ -64,8 +72,9 @@ type Config struct {
 	// Claude Code compatibility
 	ClaudeCodeFriendly bool `mapstructure:"claude_code_friendly"` // Remove $ prefix from OData parameters
 
-	// Protocol version override (for AI Foundry compatibility)
-	ProtocolVersion string `mapstructure:"protocol_version"` // Override MCP protocol version (default: 2024-11-05)
+	// Protocol version and compatibility
+	ProtocolVersion string `mapstructure:"protocol_version"` // MCP protocol version offered to clients that ask for an unsupported one (default: 2025-06-18)
+	NullIDCompat    bool   `mapstructure:"null_id_compat"`   // Answer requests with a null ID with ID 0 instead of null
 
 	// Retry configuration
 	RetryMaxAttempts       int     `mapstructure:"retry_max_attempts"`       // Maximum retry attempts (default: 3)
@@ -77,9 +86,74 @@ type Config struct {
 	HTTPTimeout     int `mapstructure:"http_timeout"`     // HTTP request timeout in seconds (default: 30)
 	MetadataTimeout int `mapstructure:"metadata_timeout"` // Metadata fetch timeout in seconds (default: 60)
 
+	// Concurrency
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"` // Requests the stdio transport handles at once (default: 4)
+
+	// Resources
+	ResourcePollInterval int `mapstructure:"resource_poll_interval"` // Seconds between ETag checks of subscribed resources (default: 30)
+
+	// Tool listing
+	ToolsPageSize int `mapstructure:"tools_page_size"` // Tools per tools/list response; clients page with cursors (0 = all at once)
+
 	// Lazy metadata mode (token optimization for large services)
 	LazyMetadata  bool `mapstructure:"lazy_metadata"`  // Enable lazy metadata mode (10 generic tools instead of per-entity)
 	LazyThreshold int  `mapstructure:"lazy_threshold"` // Auto-enable lazy mode if estimated tool count exceeds threshold (0 = disabled)
+
+	// Aggregation
+	AggregateMaxRows int `mapstructure:"aggregate_max_rows"` // Row cap for client-side aggregation and group cap for $apply (default: 10000)
+	SummarizeMaxRows int `mapstructure:"summarize_max_rows"` // Row budget for summarize mode on filter/list tools (default: 10000)
+
+	// Export
+	ExportDir string `mapstructure:"export_dir"` // Directory export tools write into (empty = export tools disabled)
+
+	// Bulk import
+	ImportDir     string `mapstructure:"import_dir"`      // Directory bulk tools read CSV/JSONL files from (empty = bulk tools disabled)
+	BulkChunkSize int    `mapstructure:"bulk_chunk_size"` // Rows per $batch changeset (default: 50)
+
+	// Dry run
+	DryRun bool `mapstructure:"dry_run"` // Return the resolved request of create/update/delete/function calls instead of sending it
+
+	// Human confirmation
+	Confirm string `mapstructure:"confirm"` // Operations requiring user approval: comma-separated delete, update, function, or all/none
+
+	// Access policy
+	PolicyFile string `mapstructure:"policy_file"` // YAML/JSON file with per-entity-set and per-function rules
+
+	// PII redaction
+	RedactProperties string   `mapstructure:"redact_properties"` // Comma-separated property name globs whose values are redacted
+	RedactTypes      string   `mapstructure:"redact_types"`      // Comma-separated Edm types whose values are redacted
+	RedactPatterns   []string `mapstructure:"redact_patterns"`   // Regexes (or email, iban) replaced inside string values
+	RedactMode       string   `mapstructure:"redact_mode"`       // mask (default) or hash
+
+	// Audit log
+	AuditLog        string `mapstructure:"audit_log"`         // File path, syslog, syslog+udp://host:port or syslog+tcp://host:port
+	AuditMaxSize    int    `mapstructure:"audit_max_size"`    // Rotate the audit file at this size in MB (0 = never)
+	AuditMaxBackups int    `mapstructure:"audit_max_backups"` // Rotated audit files to keep (0 = all)
+
+	// Change journal
+	JournalFile string `mapstructure:"journal_file"` // JSON lines file recording before/after images of writes for undo
+
+	// HTTP transport authentication
+	OAuthIssuer   string   `mapstructure:"oauth_issuer"`   // Trusted authorization server; enables bearer JWT validation
+	OAuthJWKSURL  string   `mapstructure:"oauth_jwks_url"` // Signing keys URL (default: discovered from the issuer)
+	OAuthAudience string   `mapstructure:"oauth_audience"` // Required aud claim (default: the resource URL)
+	OAuthResource string   `mapstructure:"oauth_resource"` // Canonical URL of the MCP endpoint, advertised in the metadata
+	OAuthScopes   string   `mapstructure:"oauth_scopes"`   // Comma-separated scopes every token must carry
+	APIKeys       []string `mapstructure:"api_keys"`       // Static API keys, "KEY" or "NAME=KEY"
+
+	// Streamable HTTP sessions
+	SessionTimeout int    `mapstructure:"session_timeout"` // Seconds an idle Mcp-Session-Id session is kept (default: 1800)
+	EventRetention int    `mapstructure:"event_retention"` // Seconds SSE events are kept for Last-Event-ID replay (default: 300)
+	EventStoreDir  string `mapstructure:"event_store_dir"` // Directory for stored SSE events (empty = in memory)
+
+	// Per-user credential passthrough
+	Passthrough               string `mapstructure:"passthrough"`                  // forward, exchange or credentials (empty = configured credentials)
+	TokenExchangeURL          string `mapstructure:"token_exchange_url"`           // Token endpoint for RFC 8693 token exchange
+	TokenExchangeClientID     string `mapstructure:"token_exchange_client_id"`     // Client authenticating the exchange
+	TokenExchangeClientSecret string `mapstructure:"token_exchange_client_secret"` // Client secret for the exchange
+	TokenExchangeAudience     string `mapstructure:"token_exchange_audience"`      // Audience of exchanged tokens
+	TokenExchangeScope        string `mapstructure:"token_exchange_scope"`         // Scope of exchanged tokens
+	UserCredentialsFile       string `mapstructure:"user_credentials_file"`        // YAML/JSON map of caller to basic credentials
 }
 
 // HasBasicAuth returns true if username and password are configured
@@ -92,6 +166,16 @@ func (c *Config) HasCookieAuth() bool {
 	return len(c.Cookies) > 0
 }
 
+// HasHTTPAuth returns true if the HTTP transports require authentication
+func (c *Config) HasHTTPAuth() bool {
+	return c.OAuthIssuer != "" || len(c.APIKeys) > 0
+}
+
+// HasRedaction returns true if any redaction rule is configured
+func (c *Config) HasRedaction() bool {
+	return strings.TrimSpace(c.RedactProperties) != "" || strings.TrimSpace(c.RedactTypes) != "" || len(c.RedactPatterns) > 0
+}
+
 // UsePostfix returns true if tool postfix should be used instead of prefix
 func (c *Config) UsePostfix() bool {
 	return !c.NoPostfix
@@ -141,3 +225,45 @@ func (c *Config) IsOperationEnabled(opType rune) bool {
 	// If neither flag is specified, all operations are enabled by default
 	return true
 }
+
+// ValidateConfirm checks the --confirm policy for unknown operations
+func (c *Config) ValidateConfirm() error {
+	for _, op := range strings.Split(c.Confirm, ",") {
+		switch strings.ToLower(strings.TrimSpace(op)) {
+		case "", constants.ConfirmNone, constants.ConfirmAll, constants.ConfirmDelete, constants.ConfirmUpdate, constants.ConfirmFunction:
+		default:
+			return fmt.Errorf("invalid --confirm operation %q (valid: delete, update, function, all, none)", strings.TrimSpace(op))
+		}
+	}
+	return nil
+}
+
+// ValidatePassthrough checks that credential passthrough has the caller identities it needs
+func (c *Config) ValidatePassthrough() error {
+	switch c.Passthrough {
+	case "":
+		return nil
+	case "forward", "exchange":
+		if c.OAuthIssuer == "" {
+			return fmt.Errorf("--passthrough %s requires OAuth callers (--oauth-issuer)", c.Passthrough)
+		}
+	case "credentials":
+		if !c.HasHTTPAuth() {
+			return fmt.Errorf("--passthrough credentials requires authenticated callers (--oauth-issuer or --api-key)")
+		}
+	default:
+		return fmt.Errorf("invalid --passthrough mode %q (valid: forward, exchange, credentials)", c.Passthrough)
+	}
+	return nil
+}
+
+// RequiresConfirmation checks if the --confirm policy covers an operation (delete, update or function)
+func (c *Config) RequiresConfirmation(operation string) bool {
+	for _, op := range strings.Split(c.Confirm, ",") {
+		op = strings.ToLower(strings.TrimSpace(op))
+		if op == operation || op == constants.ConfirmAll {
+			return true
+		}
+	}
+	return false
+}
@@ -80,6 +80,7 @@ const (
 	UserAgent     = "User-Agent"
 	IfMatch       = "If-Match"
 	IfNoneMatch   = "If-None-Match"
+	ETag          = "ETag"
 )
 
 // Content types
@@ -101,38 +102,46 @@ const (
 
 // Tool operation types
 const (
-	OpFilter = "filter"
-	OpCount  = "count"
-	OpSearch = "search"
-	OpGet    = "get"
-	OpCreate = "create"
-	OpUpdate = "update"
-	OpDelete = "delete"
-	OpInfo   = "info"
+	OpFilter    = "filter"
+	OpCount     = "count"
+	OpSearch    = "search"
+	OpGet       = "get"
+	OpCreate    = "create"
+	OpUpdate    = "update"
+	OpDelete    = "delete"
+	OpInfo      = "info"
+	OpAggregate = "aggregate"
+	OpExport    = "export"
+	OpBulk      = "bulk"
+	OpUndo      = "undo"
 )
 
 // Tool operation names (for shrinking)
 var ToolOperationNames = map[string]string{
-	OpFilter: "filter",
-	OpCount:  "count",
-	OpSearch: "search",
-	OpGet:    "get",
-	OpCreate: "create",
-	OpUpdate: "update",
-	OpDelete: "delete",
-	OpInfo:   "info",
+	OpFilter:    "filter",
+	OpCount:     "count",
+	OpSearch:    "search",
+	OpGet:       "get",
+	OpCreate:    "create",
+	OpUpdate:    "update",
+	OpDelete:    "delete",
+	OpInfo:      "info",
+	OpAggregate: "aggregate",
+	OpExport:    "export",
 }
 
 // Shortened tool operation names
 var ShortenedToolOperationNames = map[string]string{
-	OpFilter: "filter",
-	OpCount:  "count",
-	OpSearch: "search",
-	OpGet:    "get",
-	OpCreate: "create",
-	OpUpdate: "upd",
-	OpDelete: "del",
-	OpInfo:   "info",
+	OpFilter:    "filter",
+	OpCount:     "count",
+	OpSearch:    "search",
+	OpGet:       "get",
+	OpCreate:    "create",
+	OpUpdate:    "upd",
+	OpDelete:    "del",
+	OpInfo:      "info",
+	OpAggregate: "agg",
+	OpExport:    "exp",
 }
 
 // Error messages
@@ -156,11 +165,39 @@ const (
 	DefaultMaxResponseSize   = 5 * 1024 * 1024 // 5MB (aligned with CLI default)
 	DefaultMaxItems          = 100             // Aligned with CLI default
 	DefaultToolNameMaxLength = 64
+	DefaultPageSize          = 1000  // Rows per request when paging through an entity set
+	DefaultAggregateMaxRows  = 10000 // Row cap for client-side aggregation fallback
+	DefaultSummarizeMaxRows  = 10000 // Row budget for summarize mode
+	DefaultSummaryTopValues  = 5     // Most frequent values reported per column
+	MaxSummaryTopValues      = 50
+	MaxSummaryDistinctValues = 10000 // Distinct values tracked per column before counts become a lower bound
+	DefaultBulkChunkSize     = 50    // Rows per $batch changeset for bulk tools
+	MaxBulkChunkSize         = 1000
+	ElicitationTimeout       = 300 // seconds - a human answers elicitation requests
+	ConfirmTokenTTL          = 600 // seconds a confirm_token stays valid
 )
 
+// Confirmation policy operations (--confirm)
+const (
+	ConfirmDelete   = "delete"
+	ConfirmUpdate   = "update"
+	ConfirmFunction = "function"
+	ConfirmAll      = "all"
+	ConfirmNone     = "none"
+)
+
+// SAP analytical aggregation roles (sap:aggregation-role)
+const (
+	AggregationRoleDimension = "dimension"
+	AggregationRoleMeasure   = "measure"
+)
+
+// Aggregation methods accepted by the aggregate tools (OData v4 $apply names)
+var AggregationMethods = []string{"sum", "min", "max", "average", "count", "countdistinct"}
+
 // MCP-specific constants
 const (
-	MCPProtocolVersion = "2024-11-05"
+	MCPProtocolVersion = "2025-06-18" // Offered to clients that ask for a version the server does not support
 	MCPServerName      = "odata-mcp-bridge"
 	MCPServerVersion   = "1.0.0"
 )
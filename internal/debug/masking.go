@@ -4,6 +4,9 @@
 package debug
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/url"
 	"strings"
 )
@@ -47,6 +50,18 @@ func MaskValue(value string, showLastChars int) string {
 	return strings.Repeat("*", len(value)-showLastChars) + value[len(value)-showLastChars:]
 }
 
+// HashValue replaces a value with a short keyed SHA-256 digest. Equal values give equal
+// digests under the same key, so hashed columns can still be compared and grouped, while
+// the key keeps small value spaces (salaries, birth dates) from being brute-forced.
+func HashValue(value string, key []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
 // MaskURL removes sensitive information from a URL
 // - Masks password in userinfo (user:password@host)
 // - Masks sensitive query parameters
@@ -117,3 +132,15 @@ func IsSensitiveKey(key string) bool {
 	}
 	return false
 }
+
+// MaskAttr masks a logged value by its key: values of sensitive keys are masked like
+// tokens, URLs lose their passwords and sensitive query parameters
+func MaskAttr(key, value string) string {
+	if IsSensitiveKey(key) {
+		return MaskToken(value)
+	}
+	if strings.Contains(value, "://") {
+		return MaskURL(value)
+	}
+	return value
+}
@@ -134,34 +134,13 @@ func (m *Manager) SetCLIHint(hintJSON string) error {
 
 // GetHints returns all matching hints for a service URL
 func (m *Manager) GetHints(serviceURL string) map[string]interface{} {
-	var matchingHints []ServiceHint
-
-	// Add CLI hint if present
-	if m.cliHint != nil {
-		matchingHints = append(matchingHints, *m.cliHint)
-	}
-
-	// Find all matching hints
-	for _, hint := range m.hints {
-		if m.matchesPattern(serviceURL, hint.Pattern) {
-			matchingHints = append(matchingHints, hint)
-		}
-	}
+	matchingHints := m.matchingHints(serviceURL)
 
 	// No hints found
 	if len(matchingHints) == 0 {
 		return nil
 	}
 
-	// Sort by priority (higher first)
-	for i := 0; i < len(matchingHints)-1; i++ {
-		for j := i + 1; j < len(matchingHints); j++ {
-			if matchingHints[j].Priority > matchingHints[i].Priority {
-				matchingHints[i], matchingHints[j] = matchingHints[j], matchingHints[i]
-			}
-		}
-	}
-
 	// Merge hints (higher priority overrides)
 	result := make(map[string]interface{})
 
@@ -302,6 +281,67 @@ func (m *Manager) matchesPattern(url, pattern string) bool {
 	return true
 }
 
+// matchingHints returns the hints that apply to a service URL, highest priority first
+func (m *Manager) matchingHints(serviceURL string) []ServiceHint {
+	var matchingHints []ServiceHint
+
+	// Add CLI hint if present
+	if m.cliHint != nil {
+		matchingHints = append(matchingHints, *m.cliHint)
+	}
+
+	// Find all matching hints
+	for _, hint := range m.hints {
+		if m.matchesPattern(serviceURL, hint.Pattern) {
+			matchingHints = append(matchingHints, hint)
+		}
+	}
+
+	// Sort by priority (higher first)
+	for i := 0; i < len(matchingHints)-1; i++ {
+		for j := i + 1; j < len(matchingHints); j++ {
+			if matchingHints[j].Priority > matchingHints[i].Priority {
+				matchingHints[i], matchingHints[j] = matchingHints[j], matchingHints[i]
+			}
+		}
+	}
+
+	return matchingHints
+}
+
+// Examples returns the usage examples of all hints matching a service URL, highest priority first
+func (m *Manager) Examples(serviceURL string) []Example {
+	var examples []Example
+	for _, hint := range m.matchingHints(serviceURL) {
+		examples = append(examples, hint.Examples...)
+	}
+	return examples
+}
+
+// FieldHints returns the field hints matching a service URL; higher priority hints override
+func (m *Manager) FieldHints(serviceURL string) map[string]FieldHint {
+	fields := make(map[string]FieldHint)
+	matchingHints := m.matchingHints(serviceURL)
+	for i := len(matchingHints) - 1; i >= 0; i-- {
+		for name, field := range matchingHints[i].FieldHints {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// EntityHints returns the entity hints matching a service URL; higher priority hints override
+func (m *Manager) EntityHints(serviceURL string) map[string]EntityHint {
+	entities := make(map[string]EntityHint)
+	matchingHints := m.matchingHints(serviceURL)
+	for i := len(matchingHints) - 1; i >= 0; i-- {
+		for name, entity := range matchingHints[i].EntityHints {
+			entities[name] = entity
+		}
+	}
+	return entities
+}
+
 // mergeStringSlices merges two string slices, avoiding duplicates
 func (m *Manager) mergeStringSlices(existing, new []string) []string {
 	seen := make(map[string]bool)
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package journal keeps a local record of the entities changed through the bridge, with
+// their state before and after each change, so a change can be listed and undone.
+//
+// The journal is a JSON lines file. Changes are appended as they happen; undoing a change
+// appends a marker line, so the file is never rewritten.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxLineSize bounds a single journal line when loading
+const maxLineSize = 64 * 1024 * 1024
+
+// Change is one journaled create, update or delete
+type Change struct {
+	ID         int64                  `json:"id"`
+	Time       string                 `json:"ts"`
+	Caller     string                 `json:"caller,omitempty"` // Authenticated caller that made the change, as method:subject
+	Operation  string                 `json:"operation"`        // create, update or delete
+	EntitySet  string                 `json:"entity_set"`
+	Key        map[string]interface{} `json:"key,omitempty"`
+	Before     map[string]interface{} `json:"before,omitempty"` // Entity before the change (update, delete)
+	After      map[string]interface{} `json:"after,omitempty"`  // Entity after the change (create, update)
+	BeforeETag string                 `json:"before_etag,omitempty"`
+	AfterETag  string                 `json:"after_etag,omitempty"`
+	Changed    []string               `json:"changed,omitempty"` // Properties written by an update
+	UndoneAt   string                 `json:"undone_at,omitempty"`
+}
+
+// undoMarker is the line appended when a change is undone
+type undoMarker struct {
+	Undo     int64  `json:"undo"`
+	UndoneAt string `json:"undone_at"`
+}
+
+// Journal is an append-only change journal; it is safe for concurrent use
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	changes []*Change
+	byID    map[int64]*Change
+	nextID  int64
+}
+
+// Open loads the journal at path, creating it if it does not exist
+func Open(path string) (*Journal, error) {
+	j := &Journal{path: path, byID: make(map[int64]*Change), nextID: 1}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open change journal: %w", err)
+	}
+	j.file = file
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open change journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var marker undoMarker
+		if err := json.Unmarshal(line, &marker); err == nil && marker.Undo > 0 {
+			if c := j.byID[marker.Undo]; c != nil {
+				c.UndoneAt = marker.UndoneAt
+			}
+			continue
+		}
+		var c Change
+		if err := json.Unmarshal(line, &c); err != nil || c.ID == 0 {
+			return fmt.Errorf("invalid change journal line %d in %s", lineNo, j.path)
+		}
+		j.add(&c)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read change journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) add(c *Change) {
+	j.changes = append(j.changes, c)
+	j.byID[c.ID] = c
+	if c.ID >= j.nextID {
+		j.nextID = c.ID + 1
+	}
+}
+
+// Record assigns the change an ID and time and appends it to the journal
+func (j *Journal) Record(c *Change) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	c.ID = j.nextID
+	c.Time = time.Now().UTC().Format(time.RFC3339)
+	if err := j.append(c); err != nil {
+		return err
+	}
+	j.add(c)
+	return nil
+}
+
+// MarkUndone records that a change was undone
+func (j *Journal) MarkUndone(id int64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	c := j.byID[id]
+	if c == nil {
+		return fmt.Errorf("change %d not found", id)
+	}
+	marker := undoMarker{Undo: id, UndoneAt: time.Now().UTC().Format(time.RFC3339)}
+	if err := j.append(marker); err != nil {
+		return err
+	}
+	c.UndoneAt = marker.UndoneAt
+	return nil
+}
+
+func (j *Journal) append(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write change journal: %w", err)
+	}
+	return j.file.Sync()
+}
+
+// Get returns a copy of a change, or nil if there is none with this ID
+func (j *Journal) Get(id int64) *Change {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if c := j.byID[id]; c != nil {
+		copied := *c
+		return &copied
+	}
+	return nil
+}
+
+// List returns up to limit changes made by caller, newest first, optionally for one
+// entity set only. Without authentication the caller is "".
+func (j *Journal) List(caller, entitySet string, includeUndone bool, limit int) []*Change {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var result []*Change
+	for i := len(j.changes) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		c := j.changes[i]
+		if c.Caller != caller || (entitySet != "" && c.EntitySet != entitySet) || (!includeUndone && c.UndoneAt != "") {
+			continue
+		}
+		copied := *c
+		result = append(result, &copied)
+	}
+	return result
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, op := range []string{"create", "update", "delete"} {
+		if err := j.Record(&Change{Operation: op, EntitySet: "Products", Key: map[string]interface{}{"ProductID": float64(7)}}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := j.MarkUndone(2); err != nil {
+		t.Fatalf("MarkUndone() error = %v", err)
+	}
+	if err := j.MarkUndone(9); err == nil {
+		t.Error("expected unknown change to be rejected")
+	}
+	j.Close()
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer j.Close()
+
+	if c := j.Get(2); c == nil || c.UndoneAt == "" {
+		t.Errorf("undo marker not restored: %+v", c)
+	}
+	changes := j.List("", "", false, 0)
+	if len(changes) != 2 || changes[0].ID != 3 || changes[1].ID != 1 {
+		t.Errorf("List() = %+v, want changes 3 and 1", changes)
+	}
+	if got := j.List("", "Categories", true, 0); len(got) != 0 {
+		t.Errorf("List(Categories) = %+v", got)
+	}
+	if got := j.List("", "", true, 1); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("List(limit 1) = %+v", got)
+	}
+
+	c := &Change{Operation: "update", EntitySet: "Products", Caller: "oauth:alice"}
+	if err := j.Record(c); err != nil || c.ID != 4 {
+		t.Errorf("IDs must continue after reopening, got %d, %v", c.ID, err)
+	}
+	// Each caller lists only their own changes
+	if got := j.List("oauth:alice", "", true, 0); len(got) != 1 || got[0].ID != 4 {
+		t.Errorf("List(oauth:alice) = %+v", got)
+	}
+	if got := j.List("", "", true, 0); len(got) != 3 {
+		t.Errorf("List() must not include alice's change, got %+v", got)
+	}
+}
+
+func TestOpenRejectsCorruptJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+	os.WriteFile(path, []byte("{\"id\":1,\"operation\":\"create\"}\nnot json\n"), 0o600)
+	if _, err := Open(path); err == nil {
+		t.Error("expected corrupt journal to be rejected")
+	}
+}
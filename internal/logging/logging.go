@@ -0,0 +1,294 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package logging is the structured logger of the bridge. Records go to the configured
+// sinks (stderr and optionally a file) and to MCP clients as notifications/message, at
+// the level each client chose with logging/setLevel. Attribute values are masked with
+// the debug package before any sink or client sees them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/debug"
+)
+
+// The MCP log levels (the syslog severities) as slog levels
+const (
+	LevelDebug     = slog.LevelDebug
+	LevelInfo      = slog.LevelInfo
+	LevelNotice    = slog.Level(2)
+	LevelWarning   = slog.LevelWarn
+	LevelError     = slog.LevelError
+	LevelCritical  = slog.Level(12)
+	LevelAlert     = slog.Level(16)
+	LevelEmergency = slog.Level(20)
+)
+
+// DefaultLevel is the level of the stderr and file sinks unless configured otherwise
+const DefaultLevel = LevelWarning
+
+// KeyPayload is the attribute key of request and response bodies. Payloads are written
+// to the stderr and file sinks only: they may hold values redaction hides from clients.
+const KeyPayload = "payload"
+
+var levels = []struct {
+	name  string
+	level slog.Level
+}{
+	{"debug", LevelDebug},
+	{"info", LevelInfo},
+	{"notice", LevelNotice},
+	{"warning", LevelWarning},
+	{"error", LevelError},
+	{"critical", LevelCritical},
+	{"alert", LevelAlert},
+	{"emergency", LevelEmergency},
+}
+
+// ParseLevel parses an MCP log level name
+func ParseLevel(name string) (slog.Level, error) {
+	for _, l := range levels {
+		if strings.EqualFold(name, l.name) {
+			return l.level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (use debug, info, notice, warning, error, critical, alert or emergency)", name)
+}
+
+// LevelName returns the MCP name of a level, rounding down to the nearest named level
+func LevelName(level slog.Level) string {
+	name := levels[0].name
+	for _, l := range levels {
+		if level >= l.level {
+			name = l.name
+		}
+	}
+	return name
+}
+
+// Notifier forwards log records to MCP clients; the MCP server implements it
+type Notifier interface {
+	// LogEnabled reports whether the client of ctx, or any client for a context
+	// without one, wants records of a level
+	LogEnabled(ctx context.Context, level slog.Level) bool
+
+	// Log sends a record to those clients as notifications/message
+	Log(ctx context.Context, level slog.Level, logger string, data map[string]interface{})
+}
+
+// Options configure the stderr and file sinks
+type Options struct {
+	Level  slog.Level // Minimum level written to the sinks
+	Format string     // "text" (default) or "json"
+	File   string     // Also append records to this file
+	Output io.Writer  // Instead of os.Stderr
+}
+
+// core is the configuration shared by all loggers, so packages can create their loggers
+// before Setup runs
+type core struct {
+	mu       sync.RWMutex
+	sinks    []slog.Handler
+	level    slog.Level
+	notifier Notifier
+}
+
+var std = &core{
+	sinks: []slog.Handler{newSink(os.Stderr, "text")},
+	level: DefaultLevel,
+}
+
+// Setup configures the sinks. The returned closer closes the log file, if any.
+func Setup(opts Options) (io.Closer, error) {
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = "text"
+	}
+	if format != "text" && format != "json" {
+		return nil, fmt.Errorf("unknown log format %q (use text or json)", opts.Format)
+	}
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	sinks := []slog.Handler{newSink(output, format)}
+
+	var closer io.Closer = noFile{}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		sinks = append(sinks, newSink(file, format))
+		closer = file
+	}
+
+	std.mu.Lock()
+	std.sinks = sinks
+	std.level = opts.Level
+	std.mu.Unlock()
+	return closer, nil
+}
+
+// noFile is the closer of a setup without a log file
+type noFile struct{}
+
+func (noFile) Close() error { return nil }
+
+// SetNotifier sets where records for MCP clients go
+func SetNotifier(notifier Notifier) {
+	std.mu.Lock()
+	std.notifier = notifier
+	std.mu.Unlock()
+}
+
+// Logger returns the logger of a component, e.g. "client"; the name is the logger field
+// of notifications/message
+func Logger(name string) *slog.Logger {
+	return slog.New(&handler{core: std, name: name, notify: true})
+}
+
+// LocalLogger returns a logger whose records never go to MCP clients. Transports use it,
+// so that a message they fail to deliver does not cause another one.
+func LocalLogger(name string) *slog.Logger {
+	return slog.New(&handler{core: std, name: name})
+}
+
+// newSink creates a text or JSON handler that prints MCP level names
+func newSink(w io.Writer, format string) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level: LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				if level, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String(slog.LevelKey, strings.ToUpper(LevelName(level)))
+				}
+			}
+			return a
+		},
+	}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// handler masks the attributes of a record and hands it to the sinks and the notifier
+type handler struct {
+	core   *core
+	name   string
+	notify bool
+	attrs  []slog.Attr
+	prefix string // Group prefix of the attribute keys, e.g. "request."
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	h.core.mu.RLock()
+	sinkLevel, notifier := h.core.level, h.core.notifier
+	h.core.mu.RUnlock()
+	return level >= sinkLevel || (h.notify && notifier != nil && notifier.LogEnabled(ctx, level))
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, maskAttr(h.prefix, a))
+		return true
+	})
+
+	h.core.mu.RLock()
+	sinks, sinkLevel, notifier := h.core.sinks, h.core.level, h.core.notifier
+	h.core.mu.RUnlock()
+
+	var err error
+	if r.Level >= sinkLevel {
+		out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		if h.name != "" {
+			out.AddAttrs(slog.String("logger", h.name))
+		}
+		out.AddAttrs(attrs...)
+		for _, sink := range sinks {
+			if sinkErr := sink.Handle(ctx, out); sinkErr != nil && err == nil {
+				err = sinkErr
+			}
+		}
+	}
+
+	if h.notify && notifier != nil && notifier.LogEnabled(ctx, r.Level) {
+		data := map[string]interface{}{"message": r.Message}
+		for _, a := range attrs {
+			if a.Key == KeyPayload || strings.HasSuffix(a.Key, "."+KeyPayload) {
+				continue
+			}
+			data[a.Key] = attrValue(a.Value)
+		}
+		notifier.Log(ctx, r.Level, h.name, data)
+	}
+	return err
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = append(clone.attrs, maskAttr(h.prefix, a))
+	}
+	return &clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// maskAttr prefixes the key of an attribute with its group and masks its value
+func maskAttr(prefix string, a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	key := prefix + a.Key
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(key, debug.MaskAttr(a.Key, value.String()))
+	case slog.KindGroup:
+		members := make([]slog.Attr, 0, len(value.Group()))
+		for _, member := range value.Group() {
+			members = append(members, maskAttr("", member))
+		}
+		return slog.Attr{Key: key, Value: slog.GroupValue(members...)}
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(key, debug.MaskAttr(a.Key, err.Error()))
+		}
+	}
+	return slog.Attr{Key: key, Value: value}
+}
+
+// attrValue converts an attribute value for the JSON of notifications/message
+func attrValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]interface{}, len(v.Group()))
+		for _, member := range v.Group() {
+			group[member.Key] = attrValue(member.Value)
+		}
+		return group
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	}
+	return v.Any()
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Protocol versions that introduced the tool fields; older clients never see them
+const (
+	versionToolAnnotations   = "2025-03-26"
+	versionStructuredContent = "2025-06-18"
+)
+
+// ToolAnnotations describe how a tool behaves. They are hints for the client, e.g. to
+// auto-approve read-only tools, and are not enforced by the server.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`    // Does not modify its environment
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"` // May change or delete existing data
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`  // Repeating a call has no further effect
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`   // Interacts with an open set of external entities
+}
+
+// SetToolAnnotations sets the behaviour hints of a registered tool
+func (s *Server) SetToolAnnotations(name string, annotations *ToolAnnotations) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tool, exists := s.tools[name]; exists {
+		tool.Annotations = annotations
+	}
+}
+
+// SetToolOutputSchema sets the JSON schema of the structured result of a registered tool.
+// Tools with an output schema return their JSON result as structuredContent as well.
+func (s *Server) SetToolOutputSchema(name string, schema map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tool, exists := s.tools[name]; exists {
+		tool.OutputSchema = schema
+	}
+}
+
+// supportsVersion checks whether a negotiated protocol version is at least minimum.
+// Versions are dates, so they compare as strings.
+func supportsVersion(version, minimum string) bool {
+	return version >= minimum
+}
+
+// toolForVersion returns the tool as a client speaking a protocol version sees it,
+// without the fields that version does not know
+func toolForVersion(tool *Tool, version string) *Tool {
+	annotations := tool.Annotations != nil && supportsVersion(version, versionToolAnnotations)
+	outputSchema := tool.OutputSchema != nil && supportsVersion(version, versionStructuredContent)
+	if annotations == (tool.Annotations != nil) && outputSchema == (tool.OutputSchema != nil) {
+		return tool
+	}
+	stripped := *tool
+	if !annotations {
+		stripped.Annotations = nil
+	}
+	if !outputSchema {
+		stripped.OutputSchema = nil
+	}
+	return &stripped
+}
+
+// structuredContent parses a tool result into the object returned as structuredContent,
+// or returns nil if the result is not a JSON object
+func structuredContent(result interface{}) map[string]interface{} {
+	text, ok := result.(string)
+	if !ok || !strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return nil
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &content); err != nil {
+		return nil
+	}
+	return content
+}
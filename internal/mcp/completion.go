@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// maxCompletionValues is the most values a completion/complete response may carry
+const maxCompletionValues = 100
+
+// Completion reference types. ref/tool is an extension for completing tool arguments,
+// e.g. entity_set in lazy mode.
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+	RefTool     = "ref/tool"
+)
+
+// CompletionRequest is an argument value to complete
+type CompletionRequest struct {
+	RefType   string            // RefPrompt, RefResource or RefTool
+	RefName   string            // Prompt or tool name, or the resource URI (template)
+	Argument  string            // Name of the argument being completed
+	Value     string            // What has been entered so far
+	Arguments map[string]string // Values of other arguments already resolved
+}
+
+// Completer suggests values for an argument; at most 100 are returned to the client
+type Completer func(ctx context.Context, req *CompletionRequest) ([]string, error)
+
+// SetCompleter sets the function that answers completion/complete requests
+func (s *Server) SetCompleter(completer Completer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completer = completer
+}
+
+// handleCompletionComplete handles the completion/complete request
+func (s *Server) handleCompletionComplete(ctx context.Context, req *Request) (*transport.Message, error) {
+	ref, _ := req.Params["ref"].(map[string]interface{})
+	argument, _ := req.Params["argument"].(map[string]interface{})
+	creq := &CompletionRequest{Arguments: make(map[string]string)}
+	creq.RefType, _ = ref["type"].(string)
+	creq.Argument, _ = argument["name"].(string)
+	creq.Value, _ = argument["value"].(string)
+	if completionContext, ok := req.Params["context"].(map[string]interface{}); ok {
+		if args, ok := completionContext["arguments"].(map[string]interface{}); ok {
+			for name, value := range args {
+				if text, ok := value.(string); ok {
+					creq.Arguments[name] = text
+				}
+			}
+		}
+	}
+
+	s.mu.RLock()
+	completer := s.completer
+	var known bool
+	switch creq.RefType {
+	case RefPrompt, RefTool:
+		creq.RefName, _ = ref["name"].(string)
+		if creq.RefType == RefPrompt {
+			_, known = s.prompts[creq.RefName]
+		} else {
+			_, known = s.tools[creq.RefName]
+		}
+	case RefResource:
+		creq.RefName, _ = ref["uri"].(string)
+		_, known = s.resources[creq.RefName]
+		for _, tmpl := range s.templates {
+			known = known || tmpl.template.URITemplate == creq.RefName
+		}
+	}
+	s.mu.RUnlock()
+
+	if !known {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", fmt.Sprintf("Unknown completion reference: %s %s", creq.RefType, creq.RefName)), nil
+	}
+	if creq.Argument == "" {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", "Missing argument name"), nil
+	}
+
+	var values []string
+	if completer != nil {
+		// Completions may query the service, so they run with the caller's credentials
+		handler := s.withMiddleware("completion/complete", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return completer(ctx, creq)
+		})
+		result, err := handler(ctx, map[string]interface{}{
+			"ref":      creq.RefName,
+			"argument": creq.Argument,
+			"value":    creq.Value,
+		})
+		if err != nil {
+			errorCode, errorMessage, errorData := s.categorizeError(err, "completion/complete")
+			return s.createErrorResponse(req.ID, errorCode, errorMessage, errorData), nil
+		}
+		values, _ = result.([]string)
+	}
+
+	completion := map[string]interface{}{
+		"values":  []string{},
+		"total":   len(values),
+		"hasMore": len(values) > maxCompletionValues,
+	}
+	if len(values) > maxCompletionValues {
+		values = values[:maxCompletionValues]
+	}
+	if values != nil {
+		completion["values"] = values
+	}
+	return s.createResponse(req.ID, map[string]interface{}{
+		"completion": completion,
+	})
+}
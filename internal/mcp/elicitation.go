@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// ErrElicitationUnsupported is returned when the client did not declare the elicitation
+// capability or the transport cannot deliver requests to it
+var ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+// Elicitation actions returned by the client
+const (
+	ElicitAccept  = "accept"
+	ElicitDecline = "decline"
+	ElicitCancel  = "cancel"
+)
+
+// ElicitResult is the client's answer to an elicitation/create request
+type ElicitResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// SupportsElicitation reports whether elicitation requests can reach the user of a call
+func (s *Server) SupportsElicitation(ctx context.Context) bool {
+	s.mu.RLock()
+	declared := false
+	if sess := s.lookupSession(ctx); sess != nil {
+		_, declared = sess.clientCapabilities["elicitation"]
+	}
+	trans := s.transport
+	s.mu.RUnlock()
+
+	if !declared || trans == nil {
+		return false
+	}
+	if writer, ok := trans.(transport.ContextWriter); ok {
+		return writer.CanSendRequestsFor(ctx)
+	}
+	sender, ok := trans.(transport.RequestSender)
+	return ok && sender.CanSendRequests()
+}
+
+// Elicit asks the user for input through the client (elicitation/create).
+// requestedSchema is a flat JSON schema object describing the expected answer.
+func (s *Server) Elicit(ctx context.Context, message string, requestedSchema map[string]interface{}) (*ElicitResult, error) {
+	if !s.SupportsElicitation(ctx) {
+		return nil, ErrElicitationUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(constants.ElicitationTimeout)*time.Second)
+	defer cancel()
+
+	raw, err := s.sendRequest(ctx, "elicitation/create", map[string]interface{}{
+		"message":         message,
+		"requestedSchema": requestedSchema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result ElicitResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid elicitation response: %w", err)
+	}
+	return &result, nil
+}
+
+// sendRequest sends a server-initiated request and waits for the client's response
+func (s *Server) sendRequest(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	if s.transport == nil {
+		return nil, fmt.Errorf("transport not set")
+	}
+
+	id := fmt.Sprintf("srv-%d", atomic.AddInt64(&s.requestSeq, 1))
+	idBytes, _ := json.Marshal(id)
+
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(chan *transport.Message, 1)
+	s.mu.Lock()
+	s.pending[id] = responses
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.writeMessage(ctx, &transport.Message{
+		JSONRPC: "2.0",
+		ID:      idBytes,
+		Method:  method,
+		Params:  paramsBytes,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no response to %s: %w", method, ctx.Err())
+	}
+}
+
+// handleClientResponse delivers a response to the server-initiated request waiting for it
+func (s *Server) handleClientResponse(msg *transport.Message) {
+	var id string
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return
+	}
+
+	s.mu.RLock()
+	responses, ok := s.pending[id]
+	s.mu.RUnlock()
+
+	if ok {
+		select {
+		case responses <- msg:
+		default:
+			// Duplicate response; the first one wins
+		}
+	}
+}
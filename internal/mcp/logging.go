@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zmcp/odata-mcp/internal/logging"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// handleLoggingSetLevel handles logging/setLevel: the client receives log records of the
+// level and above as notifications/message from now on
+func (s *Server) handleLoggingSetLevel(ctx context.Context, req *Request) (*transport.Message, error) {
+	name, _ := req.Params["level"].(string)
+	level, err := logging.ParseLevel(name)
+	if err != nil {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", err.Error()), nil
+	}
+
+	s.mu.Lock()
+	s.session(ctx).logLevel = &level
+	s.mu.Unlock()
+	return s.createResponse(req.ID, map[string]interface{}{})
+}
+
+// logTargets returns the sessions that want records of a level: the session of ctx, or
+// for a context without a client (e.g. startup and background work) the only session.
+// With several sessions such records are not sent at all, since they may describe what
+// another user did. Callers hold s.mu.
+func (s *Server) logTargets(ctx context.Context, level slog.Level) []string {
+	id := transport.SessionFromContext(ctx)
+	if sess, ok := s.sessions[id]; ok {
+		if sess.logLevel != nil && level >= *sess.logLevel {
+			return []string{id}
+		}
+		return nil
+	}
+	if id != "" || len(s.sessions) != 1 {
+		return nil
+	}
+	for sid, sess := range s.sessions {
+		if sess.logLevel != nil && level >= *sess.logLevel {
+			return []string{sid}
+		}
+	}
+	return nil
+}
+
+// LogEnabled reports whether a client wants log records of a level; see logging.Notifier
+func (s *Server) LogEnabled(ctx context.Context, level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transport != nil && len(s.logTargets(ctx, level)) > 0
+}
+
+// Log sends a log record to the clients that want it as notifications/message
+func (s *Server) Log(ctx context.Context, level slog.Level, logger string, data map[string]interface{}) {
+	s.mu.RLock()
+	targets := s.logTargets(ctx, level)
+	s.mu.RUnlock()
+
+	params := map[string]interface{}{
+		"level": logging.LevelName(level),
+		"data":  data,
+	}
+	if logger != "" {
+		params["logger"] = logger
+	}
+	for _, id := range targets {
+		target := ctx
+		if transport.SessionFromContext(ctx) != id {
+			target = transport.WithSession(context.Background(), id)
+		}
+		// A record that cannot be delivered is dropped; logging the failure would recurse
+		_ = s.SendNotification(target, "notifications/message", params)
+	}
+}
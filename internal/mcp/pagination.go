@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// SetToolsPageSize sets how many tools one tools/list response holds; the client gets a
+// nextCursor for the rest. 0 lists all tools at once.
+func (s *Server) SetToolsPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	s.toolsPageSize = size
+}
+
+// SetToolGroup puts a tool in a group. A tools/list request can name the groups it wants
+// (the "groups" param, an extension); tools without a group are always listed.
+func (s *Server) SetToolGroup(name, group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tool, exists := s.tools[name]; exists {
+		tool.group = group
+	}
+}
+
+// toolsCursor is the position in the tool list behind an opaque tools/list cursor. It
+// carries the groups of the first request, so later pages list the same tools.
+type toolsCursor struct {
+	Offset int      `json:"o"`
+	Groups []string `json:"g,omitempty"`
+}
+
+// encode returns the cursor as an opaque string
+func (c toolsCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// includes reports whether a tool is in one of the requested groups
+func (c toolsCursor) includes(tool *Tool) bool {
+	if len(c.Groups) == 0 || tool.group == "" {
+		return true
+	}
+	for _, group := range c.Groups {
+		if group == tool.group {
+			return true
+		}
+	}
+	return false
+}
+
+// toolsListCursor reads the cursor of a tools/list request, or the groups of a first one
+func toolsListCursor(params map[string]interface{}) (toolsCursor, error) {
+	var cursor toolsCursor
+	if raw, ok := params["cursor"].(string); ok && raw != "" {
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.Offset < 0 {
+			return toolsCursor{}, fmt.Errorf("invalid cursor %q", raw)
+		}
+		return cursor, nil
+	}
+
+	if raw, exists := params["groups"]; exists && raw != nil {
+		groups, ok := raw.([]interface{})
+		if !ok {
+			return toolsCursor{}, fmt.Errorf("groups must be an array of strings")
+		}
+		for _, g := range groups {
+			group, ok := g.(string)
+			if !ok {
+				return toolsCursor{}, fmt.Errorf("groups must be an array of strings")
+			}
+			cursor.Groups = append(cursor.Groups, group)
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zmcp/odata-mcp/internal/progress"
+)
+
+// withProgress installs a progress reporter for a request whose _meta carries a
+// progressToken; requests without one get ctx back unchanged
+func (s *Server) withProgress(ctx context.Context, req *Request) context.Context {
+	meta, _ := req.Params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	if !ok || token == nil {
+		return ctx
+	}
+	switch token.(type) {
+	case string, float64:
+	default:
+		return ctx
+	}
+	return progress.WithReporter(ctx, s.progressReporter(ctx, token))
+}
+
+// progressReporter sends the progress reports of a request as notifications/progress.
+// Progress must increase with every notification, so a report that does not advance it
+// (such as a retry) counts one past the last one.
+func (s *Server) progressReporter(ctx context.Context, token interface{}) progress.Reporter {
+	var mu sync.Mutex
+	var last float64
+	return func(value, total float64, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if value <= last {
+			value = last + 1
+		}
+		last = value
+
+		params := map[string]interface{}{
+			"progressToken": token,
+			"progress":      value,
+		}
+		if total > 0 {
+			params["total"] = total
+		}
+		if message != "" {
+			params["message"] = message
+		}
+		s.SendNotification(ctx, "notifications/progress", params)
+	}
+}
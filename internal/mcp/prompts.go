@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// ErrInvalidPromptArguments is returned by prompt handlers for unusable arguments
+var ErrInvalidPromptArguments = errors.New("invalid prompt arguments")
+
+// Prompt is a reusable message template a client can offer, e.g. as a slash command
+type Prompt struct {
+	Name        string            `json:"name"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Arguments   []*PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument a prompt accepts
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptMessage is one message of a prompt, with text content
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// PromptContent is the content of a prompt message
+type PromptContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// PromptResult is the result of prompts/get
+type PromptResult struct {
+	Description string           `json:"description,omitempty"`
+	Messages    []*PromptMessage `json:"messages"`
+}
+
+// PromptHandler renders a prompt for its arguments
+type PromptHandler func(ctx context.Context, args map[string]string) (*PromptResult, error)
+
+// UserMessage returns a user prompt message with text content
+func UserMessage(text string) *PromptMessage {
+	return &PromptMessage{Role: "user", Content: PromptContent{Type: "text", Text: text}}
+}
+
+// AddPrompt registers a prompt with the server
+func (s *Server) AddPrompt(prompt *Prompt, handler PromptHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.prompts[prompt.Name]; !exists {
+		s.promptOrder = append(s.promptOrder, prompt.Name)
+	}
+	s.prompts[prompt.Name] = prompt
+	s.promptHandlers[prompt.Name] = handler
+}
+
+// handlePromptsListV2 handles the prompts/list request for transport
+func (s *Server) handlePromptsListV2(req *Request) (*transport.Message, error) {
+	s.mu.RLock()
+	prompts := make([]*Prompt, 0, len(s.promptOrder))
+	for _, name := range s.promptOrder {
+		prompts = append(prompts, s.prompts[name])
+	}
+	s.mu.RUnlock()
+
+	return s.createResponse(req.ID, map[string]interface{}{
+		"prompts": prompts,
+	})
+}
+
+// handlePromptsGet handles the prompts/get request
+func (s *Server) handlePromptsGet(ctx context.Context, req *Request) (*transport.Message, error) {
+	name, _ := req.Params["name"].(string)
+
+	s.mu.RLock()
+	prompt, exists := s.prompts[name]
+	handler := s.promptHandlers[name]
+	s.mu.RUnlock()
+	if !exists {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", fmt.Sprintf("Unknown prompt: %s", name)), nil
+	}
+
+	// Clients send arguments as strings; other JSON values are passed on formatted
+	args := make(map[string]string)
+	if raw, ok := req.Params["arguments"].(map[string]interface{}); ok {
+		for key, value := range raw {
+			if text, ok := value.(string); ok {
+				args[key] = text
+			} else if value != nil {
+				args[key] = fmt.Sprint(value)
+			}
+		}
+	}
+	for _, arg := range prompt.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return s.createErrorResponse(req.ID, -32602, "Invalid params", fmt.Sprintf("Missing required argument: %s", arg.Name)), nil
+		}
+	}
+
+	result, err := handler(ctx, args)
+	if errors.Is(err, ErrInvalidPromptArguments) {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", err.Error()), nil
+	}
+	if err != nil {
+		return s.createErrorResponse(req.ID, -32603, "Internal error", err.Error()), nil
+	}
+	if result.Description == "" {
+		result.Description = prompt.Description
+	}
+	return s.createResponse(req.ID, result)
+}
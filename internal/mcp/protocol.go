@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SupportedProtocolVersions are the MCP protocol versions the server speaks, newest first
+var SupportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// IsSupportedProtocolVersion reports whether the server speaks a protocol version
+func IsSupportedProtocolVersion(version string) bool {
+	for _, v := range SupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProtocolVersion sets the protocol version offered to clients that ask for one the
+// server does not support (or for none)
+func (s *Server) SetProtocolVersion(version string) error {
+	if !IsSupportedProtocolVersion(version) {
+		return fmt.Errorf("unsupported MCP protocol version %q (supported: %s)", version, strings.Join(SupportedProtocolVersions, ", "))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protocolVersion = version
+	return nil
+}
+
+// SetNullIDCompat answers requests with a null ID with ID 0 instead of null. JSON-RPC
+// requires null, but some clients reject responses with a null ID.
+func (s *Server) SetNullIDCompat(enabled bool) {
+	s.nullIDCompat.Store(enabled)
+}
+
+// negotiateVersion returns the version agreed for a client that asked for one in
+// initialize: the same one if the server supports it, the offered one otherwise.
+// Callers hold s.mu.
+func (s *Server) negotiateVersion(requested string) string {
+	if IsSupportedProtocolVersion(requested) {
+		return requested
+	}
+	return s.protocolVersion
+}
+
+// responseID returns the ID of the response to a request. A missing or null request ID is
+// answered with null, or with 0 in null-ID compatibility mode.
+func (s *Server) responseID(id interface{}) json.RawMessage {
+	var idBytes json.RawMessage
+	switch v := id.(type) {
+	case json.RawMessage:
+		idBytes = v
+	case nil:
+	default:
+		idBytes, _ = json.Marshal(id)
+	}
+	if len(idBytes) == 0 || string(idBytes) == "null" {
+		if s.nullIDCompat.Load() {
+			return json.RawMessage("0")
+		}
+		return json.RawMessage("null")
+	}
+	return idBytes
+}
+
+// handleNotification handles a notification. Notifications are never answered, not even
+// with an error, so unknown ones are ignored.
+func (s *Server) handleNotification(ctx context.Context, req *Request) {
+	switch req.Method {
+	case "notifications/initialized", "initialized":
+		s.handleInitialized(ctx, req)
+	}
+	// notifications/cancelled is handled by the transports that can cancel a request in
+	// flight; progress and roots/list_changed need nothing from this server
+}
+
+// requiresInitialize reports whether a request must wait for initialize: everything but
+// initialize itself and ping
+func requiresInitialize(method string) bool {
+	return method != "initialize" && method != "ping"
+}
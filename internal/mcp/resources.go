@@ -0,0 +1,317 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// DefaultResourcePollInterval is how often subscribed resources are checked for changes
+const DefaultResourcePollInterval = 30 * time.Second
+
+// ErrResourceNotFound is returned for URIs that name no resource
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Resource is a document a client can read as context
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a family of resources by an RFC 6570 URI template
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is the content of a read resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+	// ETag identifies the version of the contents for subscriptions; without one,
+	// changes are detected by comparing the text
+	ETag string `json:"-"`
+}
+
+// ResourceReader reads the resource at uri
+type ResourceReader func(ctx context.Context, uri string) (*ResourceContents, error)
+
+type registeredResource struct {
+	resource *Resource
+	reader   ResourceReader
+}
+
+type registeredTemplate struct {
+	template *ResourceTemplate
+	pattern  *regexp.Regexp
+	reader   ResourceReader
+}
+
+// subscription is a resource a session asked to be notified about
+type subscription struct {
+	ctx     context.Context // Context of the subscribe request, without its cancellation
+	version string          // ETag or content hash seen last
+}
+
+// AddResource registers a resource with a fixed URI
+func (s *Server) AddResource(resource *Resource, reader ResourceReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.resources[resource.URI]; !exists {
+		s.resourceOrder = append(s.resourceOrder, resource.URI)
+	}
+	s.resources[resource.URI] = &registeredResource{resource: resource, reader: reader}
+}
+
+// AddResourceTemplate registers a resource template; reads of URIs that match the
+// template go to reader
+func (s *Server) AddResourceTemplate(template *ResourceTemplate, reader ResourceReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = append(s.templates, &registeredTemplate{
+		template: template,
+		pattern:  templatePattern(template.URITemplate),
+		reader:   reader,
+	})
+}
+
+// SetResourcePollInterval sets how often subscribed resources are checked for changes
+func (s *Server) SetResourcePollInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d > 0 {
+		s.resourcePollInterval = d
+	}
+}
+
+// templatePattern turns a URI template into a regular expression matching its URIs.
+// Every {variable} matches one or more characters.
+func templatePattern(uriTemplate string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	rest := uriTemplate
+	for {
+		open := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if open < 0 || end < open {
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		pattern.WriteString(regexp.QuoteMeta(rest[:open]))
+		pattern.WriteString("(.+?)")
+		rest = rest[end+1:]
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
+
+// resourceReader finds the reader for a URI: a fixed resource, else the first matching template
+func (s *Server) resourceReader(uri string) (ResourceReader, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if res, ok := s.resources[uri]; ok {
+		return res.reader, true
+	}
+	for _, tmpl := range s.templates {
+		if tmpl.pattern.MatchString(uri) {
+			return tmpl.reader, true
+		}
+	}
+	return nil, false
+}
+
+// readResource reads a resource through the tool middleware, so reads are audited,
+// redacted and made with the caller's credentials like tool calls
+func (s *Server) readResource(ctx context.Context, uri string) (*ResourceContents, error) {
+	reader, ok := s.resourceReader(uri)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	var contents *ResourceContents
+	handler := s.withMiddleware("resources/read", func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		var err error
+		if contents, err = reader(ctx, uri); err != nil {
+			return nil, err
+		}
+		return contents.Text, nil
+	})
+
+	text, err := handler(ctx, map[string]interface{}{"uri": uri})
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := text.(string); ok {
+		contents.Text = s
+	}
+	return contents, nil
+}
+
+// resourceVersion identifies the version of read contents for change detection
+func resourceVersion(contents *ResourceContents) string {
+	if contents.ETag != "" {
+		return contents.ETag
+	}
+	sum := sha256.Sum256([]byte(contents.Text))
+	return hex.EncodeToString(sum[:])
+}
+
+// handleResourcesListV2 handles the resources/list request for transport
+func (s *Server) handleResourcesListV2(req *Request) (*transport.Message, error) {
+	s.mu.RLock()
+	resources := make([]*Resource, 0, len(s.resourceOrder))
+	for _, uri := range s.resourceOrder {
+		resources = append(resources, s.resources[uri].resource)
+	}
+	s.mu.RUnlock()
+
+	return s.createResponse(req.ID, map[string]interface{}{
+		"resources": resources,
+	})
+}
+
+// handleResourceTemplatesList handles the resources/templates/list request
+func (s *Server) handleResourceTemplatesList(req *Request) (*transport.Message, error) {
+	s.mu.RLock()
+	templates := make([]*ResourceTemplate, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		templates = append(templates, tmpl.template)
+	}
+	s.mu.RUnlock()
+
+	return s.createResponse(req.ID, map[string]interface{}{
+		"resourceTemplates": templates,
+	})
+}
+
+// handleResourcesRead handles the resources/read request
+func (s *Server) handleResourcesRead(ctx context.Context, req *Request) (*transport.Message, error) {
+	uri, _ := req.Params["uri"].(string)
+	if uri == "" {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", "Missing resource uri"), nil
+	}
+
+	contents, err := s.readResource(ctx, uri)
+	if errors.Is(err, ErrResourceNotFound) {
+		return s.createErrorResponse(req.ID, -32002, "Resource not found", uri), nil
+	}
+	if err != nil {
+		errorCode, errorMessage, errorData := s.categorizeError(err, "resources/read")
+		return s.createErrorResponse(req.ID, errorCode, errorMessage, errorData), nil
+	}
+
+	return s.createResponse(req.ID, map[string]interface{}{
+		"contents": []*ResourceContents{contents},
+	})
+}
+
+// handleResourcesSubscribe handles the resources/subscribe request. The resource is read
+// once to check access and remember its version; the poller then reports changes with
+// notifications/resources/updated.
+func (s *Server) handleResourcesSubscribe(ctx context.Context, req *Request) (*transport.Message, error) {
+	uri, _ := req.Params["uri"].(string)
+	if uri == "" {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", "Missing resource uri"), nil
+	}
+
+	// Polls run after the request ended, with the caller's identity and session
+	subCtx := context.WithoutCancel(ctx)
+	contents, err := s.readResource(subCtx, uri)
+	if errors.Is(err, ErrResourceNotFound) {
+		return s.createErrorResponse(req.ID, -32002, "Resource not found", uri), nil
+	}
+	if err != nil {
+		errorCode, errorMessage, errorData := s.categorizeError(err, "resources/subscribe")
+		return s.createErrorResponse(req.ID, errorCode, errorMessage, errorData), nil
+	}
+
+	s.mu.Lock()
+	sess := s.session(ctx)
+	if sess.subscriptions == nil {
+		sess.subscriptions = make(map[string]*subscription)
+	}
+	sess.subscriptions[uri] = &subscription{ctx: subCtx, version: resourceVersion(contents)}
+	s.mu.Unlock()
+
+	s.pollOnce.Do(func() { go s.pollSubscriptions() })
+	return s.createResponse(req.ID, map[string]interface{}{})
+}
+
+// handleResourcesUnsubscribe handles the resources/unsubscribe request
+func (s *Server) handleResourcesUnsubscribe(ctx context.Context, req *Request) (*transport.Message, error) {
+	uri, _ := req.Params["uri"].(string)
+
+	s.mu.Lock()
+	if sess := s.lookupSession(ctx); sess != nil {
+		delete(sess.subscriptions, uri)
+	}
+	s.mu.Unlock()
+
+	return s.createResponse(req.ID, map[string]interface{}{})
+}
+
+// pollSubscriptions checks subscribed resources until the server stops
+func (s *Server) pollSubscriptions() {
+	s.mu.RLock()
+	interval := s.resourcePollInterval
+	s.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkSubscriptions()
+		}
+	}
+}
+
+// checkSubscriptions reads every subscribed resource and notifies its subscriber when
+// the ETag (or content) changed. Failed reads are retried on the next poll.
+func (s *Server) checkSubscriptions() {
+	type pending struct {
+		uri string
+		sub *subscription
+	}
+	var subs []pending
+	s.mu.RLock()
+	for _, sess := range s.sessions {
+		for uri, sub := range sess.subscriptions {
+			subs = append(subs, pending{uri, sub})
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, p := range subs {
+		contents, err := s.readResource(p.sub.ctx, p.uri)
+		if err != nil {
+			continue
+		}
+		version := resourceVersion(contents)
+
+		s.mu.Lock()
+		changed := version != p.sub.version
+		p.sub.version = version
+		s.mu.Unlock()
+
+		if changed {
+			s.SendNotification(p.sub.ctx, "notifications/resources/updated", map[string]interface{}{
+				"uri": p.uri,
+			})
+		}
+	}
+}
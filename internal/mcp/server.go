@@ -3,11 +3,13 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/zmcp/odata-mcp/internal/constants"
 	"github.com/zmcp/odata-mcp/internal/transport"
@@ -15,14 +17,23 @@ import (
 
 // Tool represents an MCP tool
 type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"` // Sent from protocol 2025-06-18
+	Annotations  *ToolAnnotations       `json:"annotations,omitempty"`  // Sent from protocol 2025-03-26
+
+	group string // Set with SetToolGroup; tools/list can be limited to groups
 }
 
 // ToolHandler is a function that handles tool execution
 type ToolHandler func(ctx context.Context, args map[string]interface{}) (interface{}, error)
 
+// ToolMiddleware wraps every tool handler, e.g. to post-process results and errors.
+// Resource reads and completions run through it too, under the names "resources/read"
+// and "completion/complete".
+type ToolMiddleware func(name string, next ToolHandler) ToolHandler
+
 // Request represents an incoming MCP request
 type Request struct {
 	JSONRPC string                 `json:"jsonrpc"`
@@ -35,15 +46,33 @@ type Request struct {
 type Server struct {
 	name            string
 	version         string
-	protocolVersion string // MCP protocol version (can be overridden)
+	protocolVersion string      // MCP protocol version offered to clients that ask for an unsupported one
+	nullIDCompat    atomic.Bool // Answer null request IDs with 0
 	tools           map[string]*Tool
 	toolOrder       []string // Maintains insertion order
+	toolsPageSize   int      // Tools per tools/list response (0 = all)
 	handlers        map[string]ToolHandler
+	middleware      []ToolMiddleware
 	transport       transport.Transport
 	ctx             context.Context
 	cancel          context.CancelFunc
 	mu              sync.RWMutex
-	initialized     bool
+
+	sessions   map[string]*session                // Per-client state, by transport session ID
+	pending    map[string]chan *transport.Message // Server-initiated requests awaiting a client response
+	requestSeq int64                              // Sequence for server-initiated request IDs
+
+	resources            map[string]*registeredResource // By URI
+	resourceOrder        []string                       // Maintains insertion order
+	templates            []*registeredTemplate
+	resourcePollInterval time.Duration // How often subscribed resources are checked
+	pollOnce             sync.Once     // Starts the subscription poller on first subscribe
+
+	prompts        map[string]*Prompt
+	promptOrder    []string // Maintains insertion order
+	promptHandlers map[string]PromptHandler
+
+	completer Completer // Answers completion/complete
 }
 
 // NewServer creates a new MCP server
@@ -59,16 +88,16 @@ func NewServer(name, version string) *Server {
 		tools:           make(map[string]*Tool),
 		toolOrder:       make([]string, 0),
 		handlers:        make(map[string]ToolHandler),
+		sessions:        make(map[string]*session),
+		pending:         make(map[string]chan *transport.Message),
 		ctx:             ctx,
-		cancel:    cancel,
-	}
-}
+		cancel:          cancel,
 
-// SetProtocolVersion sets the MCP protocol version to use
-func (s *Server) SetProtocolVersion(version string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.protocolVersion = version
+		resources:            make(map[string]*registeredResource),
+		resourcePollInterval: DefaultResourcePollInterval,
+		prompts:              make(map[string]*Prompt),
+		promptHandlers:       make(map[string]PromptHandler),
+	}
 }
 
 // AddTool registers a new tool with the server
@@ -85,6 +114,34 @@ func (s *Server) AddTool(tool *Tool, handler ToolHandler) {
 	s.handlers[tool.Name] = handler
 }
 
+// ClientInfo returns the clientInfo (name, version) the client of a message sent in initialize, or nil
+func (s *Server) ClientInfo(ctx context.Context) map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sess := s.lookupSession(ctx); sess != nil {
+		return sess.clientInfo
+	}
+	return nil
+}
+
+// Use adds a middleware around every tool call; the first added runs outermost
+func (s *Server) Use(mw ToolMiddleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middleware = append(s.middleware, mw)
+}
+
+// withMiddleware wraps a handler in the middleware chain under a name
+func (s *Server) withMiddleware(name string, handler ToolHandler) ToolHandler {
+	s.mu.RLock()
+	middleware := s.middleware
+	s.mu.RUnlock()
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](name, handler)
+	}
+	return handler
+}
+
 // RemoveTool removes a tool from the server
 func (s *Server) RemoveTool(name string) {
 	s.mu.Lock()
@@ -140,6 +197,19 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.Message) (*tr
 		return s.createErrorResponse(msg.ID, -32600, "Invalid Request", "JSON-RPC version must be 2.0"), nil
 	}
 
+	// Responses to server-initiated requests (e.g. elicitation) carry no method
+	if msg.Method == "" && len(msg.ID) > 0 && (msg.Result != nil || msg.Error != nil) {
+		s.handleClientResponse(msg)
+		return nil, nil
+	}
+
+	if msg.Method == "" {
+		return s.createErrorResponse(msg.ID, -32600, "Invalid Request", "Missing method"), nil
+	}
+
+	// Notifications carry no ID; they get no response, not even an error
+	notification := len(msg.ID) == 0
+
 	// Convert transport message to internal request
 	req := &Request{
 		JSONRPC: msg.JSONRPC,
@@ -151,6 +221,9 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.Message) (*tr
 	if len(msg.Params) > 0 {
 		var params map[string]interface{}
 		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			if notification {
+				return nil, nil
+			}
 			return s.createErrorResponse(msg.ID, -32700, "Parse error", err.Error()), nil
 		}
 		req.Params = params
@@ -159,24 +232,47 @@ func (s *Server) HandleMessage(ctx context.Context, msg *transport.Message) (*tr
 		req.Params = make(map[string]interface{})
 	}
 
-	// Handle notifications (no response expected)
-	if req.Method == "initialized" {
-		s.handleInitialized(req)
+	if notification {
+		s.handleNotification(ctx, req)
 		return nil, nil
 	}
 
+	// A client must initialize its session before anything but ping
+	if requiresInitialize(req.Method) {
+		s.mu.RLock()
+		initialized := s.lookupSession(ctx) != nil
+		s.mu.RUnlock()
+		if !initialized {
+			return s.createErrorResponse(req.ID, -32600, "Invalid Request", "Server not initialized: initialize must be the first request"), nil
+		}
+	}
+
 	// Handle requests
 	switch req.Method {
 	case "initialize":
-		return s.handleInitializeV2(req)
+		return s.handleInitializeV2(ctx, req)
 	case "tools/list":
-		return s.handleToolsListV2(req)
+		return s.handleToolsListV2(ctx, req)
 	case "tools/call":
 		return s.handleToolsCallV2(ctx, req)
 	case "resources/list":
 		return s.handleResourcesListV2(req)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(req)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, req)
+	case "resources/subscribe":
+		return s.handleResourcesSubscribe(ctx, req)
+	case "resources/unsubscribe":
+		return s.handleResourcesUnsubscribe(ctx, req)
 	case "prompts/list":
 		return s.handlePromptsListV2(req)
+	case "prompts/get":
+		return s.handlePromptsGet(ctx, req)
+	case "logging/setLevel":
+		return s.handleLoggingSetLevel(ctx, req)
+	case "completion/complete":
+		return s.handleCompletionComplete(ctx, req)
 	case "ping":
 		return s.handlePingV2(req)
 	default:
@@ -191,53 +287,20 @@ func (s *Server) Stop() {
 
 // createErrorResponse creates an error response message
 func (s *Server) createErrorResponse(id interface{}, code int, message, data string) *transport.Message {
-	var idBytes json.RawMessage
-
-	// Handle different ID types
-	switch v := id.(type) {
-	case json.RawMessage:
-		// Check if it's null
-		if string(v) == "null" || len(v) == 0 {
-			idBytes = json.RawMessage("0")
-		} else {
-			idBytes = v
-		}
-	case nil:
-		idBytes = json.RawMessage("0")
-	default:
-		idBytes, _ = json.Marshal(id)
-	}
-
+	errorData, _ := json.Marshal(data)
 	return &transport.Message{
 		JSONRPC: "2.0",
-		ID:      idBytes,
+		ID:      s.responseID(id),
 		Error: &transport.Error{
 			Code:    code,
 			Message: message,
-			Data:    json.RawMessage(fmt.Sprintf(`"%s"`, data)),
+			Data:    errorData,
 		},
 	}
 }
 
 // createResponse creates a success response message
 func (s *Server) createResponse(id interface{}, result interface{}) (*transport.Message, error) {
-	var idBytes json.RawMessage
-
-	// Handle different ID types - convert null to 0 for Claude Desktop compatibility
-	switch v := id.(type) {
-	case json.RawMessage:
-		// Check if it's null and convert to 0 for Claude Desktop
-		if string(v) == "null" || len(v) == 0 {
-			idBytes = json.RawMessage("0")
-		} else {
-			idBytes = v
-		}
-	case nil:
-		idBytes = json.RawMessage("0")
-	default:
-		idBytes, _ = json.Marshal(id)
-	}
-
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
 		return nil, err
@@ -245,28 +308,44 @@ func (s *Server) createResponse(id interface{}, result interface{}) (*transport.
 
 	return &transport.Message{
 		JSONRPC: "2.0",
-		ID:      idBytes,
+		ID:      s.responseID(id),
 		Result:  resultBytes,
 	}, nil
 }
 
 // handleInitializeV2 handles the initialize request for transport
-func (s *Server) handleInitializeV2(req *Request) (*transport.Message, error) {
+func (s *Server) handleInitializeV2(ctx context.Context, req *Request) (*transport.Message, error) {
+	// Remember client capabilities (e.g. elicitation) for server-initiated requests
+	s.mu.Lock()
+	sess := s.session(ctx)
+	requested, _ := req.Params["protocolVersion"].(string)
+	sess.protocolVersion = s.negotiateVersion(requested)
+	if caps, ok := req.Params["capabilities"].(map[string]interface{}); ok {
+		sess.clientCapabilities = caps
+	}
+	if info, ok := req.Params["clientInfo"].(map[string]interface{}); ok {
+		sess.clientInfo = info
+	}
+	protocolVersion := sess.protocolVersion
+	s.mu.Unlock()
+
 	// Order fields to match AI Foundry client expectations
 	result := map[string]interface{}{
 		"capabilities": map[string]interface{}{
+			"completions": map[string]interface{}{},
+			"logging":     map[string]interface{}{},
 			"prompts": map[string]interface{}{
 				"listChanged": false,
 			},
 			"resources": map[string]interface{}{
 				"listChanged": false,
-				"subscribe":   false,
+				"subscribe":   true,
 			},
 			"tools": map[string]interface{}{
 				"listChanged": true,
 			},
 		},
-		"protocolVersion": s.protocolVersion, // Use configurable version
+		"protocolVersion": protocolVersion,
 		"serverInfo": map[string]interface{}{
 			"name":    s.name,
 			"version": s.version,
@@ -277,29 +356,51 @@ func (s *Server) handleInitializeV2(req *Request) (*transport.Message, error) {
 }
 
 // handleInitialized handles the initialized notification
-func (s *Server) handleInitialized(req *Request) error {
+func (s *Server) handleInitialized(ctx context.Context, req *Request) error {
 	s.mu.Lock()
-	s.initialized = true
+	if sess := s.lookupSession(ctx); sess != nil {
+		sess.initialized = true
+	}
 	s.mu.Unlock()
 	return nil
 }
 
 // handleToolsListV2 handles the tools/list request for transport
-func (s *Server) handleToolsListV2(req *Request) (*transport.Message, error) {
+func (s *Server) handleToolsListV2(ctx context.Context, req *Request) (*transport.Message, error) {
+	cursor, err := toolsListCursor(req.Params)
+	if err != nil {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", err.Error()), nil
+	}
+
+	version := s.ProtocolVersion(ctx)
 	s.mu.RLock()
-	tools := make([]*Tool, 0, len(s.tools))
+	pageSize := s.toolsPageSize
+	matching := make([]*Tool, 0, len(s.tools))
 	// Use the ordered list to maintain insertion order
 	for _, name := range s.toolOrder {
-		if tool, exists := s.tools[name]; exists {
-			tools = append(tools, tool)
+		if tool, exists := s.tools[name]; exists && cursor.includes(tool) {
+			matching = append(matching, tool)
 		}
 	}
 	s.mu.RUnlock()
 
-	result := map[string]interface{}{
-		"tools": tools,
+	if cursor.Offset > len(matching) {
+		return s.createErrorResponse(req.ID, -32602, "Invalid params", "Cursor is past the end of the tool list"), nil
+	}
+	page := matching[cursor.Offset:]
+
+	result := map[string]interface{}{}
+	if pageSize > 0 && len(page) > pageSize {
+		page = page[:pageSize]
+		result["nextCursor"] = toolsCursor{Offset: cursor.Offset + pageSize, Groups: cursor.Groups}.encode()
 	}
 
+	tools := make([]*Tool, 0, len(page))
+	for _, tool := range page {
+		tools = append(tools, toolForVersion(tool, version))
+	}
+	result["tools"] = tools
+
 	return s.createResponse(req.ID, result)
 }
 
@@ -317,17 +418,20 @@ func (s *Server) handleToolsCallV2(ctx context.Context, req *Request) (*transpor
 
 	s.mu.RLock()
 	handler, exists := s.handlers[name]
+	var outputSchema map[string]interface{}
+	if tool := s.tools[name]; tool != nil {
+		outputSchema = tool.OutputSchema
+	}
 	s.mu.RUnlock()
 
 	if !exists {
 		return s.createErrorResponse(req.ID, -32602, "Invalid params", fmt.Sprintf("Tool not found: %s", name)), nil
 	}
+	handler = s.withMiddleware(name, handler)
 
-	result, err := handler(ctx, params)
+	result, err := handler(s.withProgress(ctx, req), params)
 	if err != nil {
-		// Map OData errors to appropriate MCP error codes and provide detailed context
-		errorCode, errorMessage, errorData := s.categorizeError(err, name)
-		return s.createErrorResponse(req.ID, errorCode, errorMessage, errorData), nil
+		return s.createResponse(req.ID, toolErrorResult(err, name))
 	}
 
 	response := map[string]interface{}{
@@ -338,6 +442,12 @@ func (s *Server) handleToolsCallV2(ctx context.Context, req *Request) (*transpor
 			},
 		},
 	}
+	// Clients that know output schemas also get the result as JSON; the text stays for the rest
+	if outputSchema != nil && supportsVersion(s.ProtocolVersion(ctx), versionStructuredContent) {
+		if content := structuredContent(result); content != nil {
+			response["structuredContent"] = content
+		}
+	}
 
 	return s.createResponse(req.ID, response)
 }
@@ -348,26 +458,9 @@ func (s *Server) handlePingV2(req *Request) (*transport.Message, error) {
 	return s.createResponse(req.ID, result)
 }
 
-// handleResourcesListV2 handles the resources/list request for transport
-func (s *Server) handleResourcesListV2(req *Request) (*transport.Message, error) {
-	// OData MCP bridge doesn't provide resources, only tools
-	result := map[string]interface{}{
-		"resources": []interface{}{},
-	}
-	return s.createResponse(req.ID, result)
-}
-
-// handlePromptsListV2 handles the prompts/list request for transport
-func (s *Server) handlePromptsListV2(req *Request) (*transport.Message, error) {
-	// OData MCP bridge doesn't provide prompts, only tools
-	result := map[string]interface{}{
-		"prompts": []interface{}{},
-	}
-	return s.createResponse(req.ID, result)
-}
-
-// SendNotification sends a notification through the transport
-func (s *Server) SendNotification(method string, params interface{}) error {
+// SendNotification sends a notification to the client of the request handled in ctx.
+// For a context without a client (e.g. context.Background()), it goes to every client.
+func (s *Server) SendNotification(ctx context.Context, method string, params interface{}) error {
 	if s.transport == nil {
 		return fmt.Errorf("transport not set")
 	}
@@ -383,10 +476,56 @@ func (s *Server) SendNotification(method string, params interface{}) error {
 		Params:  paramsBytes,
 	}
 
+	return s.writeMessage(ctx, msg)
+}
+
+// writeMessage sends a message to the client of the request handled in ctx, if the
+// transport tells clients apart, and otherwise to the transport's clients
+func (s *Server) writeMessage(ctx context.Context, msg *transport.Message) error {
+	if writer, ok := s.transport.(transport.ContextWriter); ok {
+		return writer.WriteMessageFor(ctx, msg)
+	}
 	return s.transport.WriteMessage(msg)
 }
 
-// categorizeError maps OData errors to appropriate MCP error codes and enhances error messages
+// DetailedError is an error with machine-readable details, e.g. an error response of the
+// OData service. A tool call failing with one returns the details next to the message.
+type DetailedError interface {
+	error
+	ErrorDetails() map[string]interface{}
+}
+
+// statusError is an error that carries the HTTP status of a failed service request
+type statusError interface {
+	HTTPStatus() int
+}
+
+// toolErrorResult turns a failed tool call into an isError result, so the model sees what
+// went wrong and can correct the call. JSON-RPC errors are kept for protocol faults.
+func toolErrorResult(err error, toolName string) map[string]interface{} {
+	content := []map[string]interface{}{
+		{
+			"type": "text",
+			"text": fmt.Sprintf("OData MCP tool '%s' failed: %s", toolName, err.Error()),
+		},
+	}
+	var detailed DetailedError
+	if errors.As(err, &detailed) && detailed.ErrorDetails() != nil {
+		if details, err := json.Marshal(detailed.ErrorDetails()); err == nil {
+			content = append(content, map[string]interface{}{
+				"type": "text",
+				"text": string(details),
+			})
+		}
+	}
+	return map[string]interface{}{
+		"content": content,
+		"isError": true,
+	}
+}
+
+// categorizeError maps errors of requests other than tool calls to MCP error codes: service
+// errors the client can fix (HTTP 400, 404, 422) are invalid params, the rest internal errors
 func (s *Server) categorizeError(err error, toolName string) (int, string, string) {
 	errStr := err.Error()
 
@@ -395,57 +534,24 @@ func (s *Server) categorizeError(err error, toolName string) (int, string, strin
 	fullErrorMessage := fmt.Sprintf("OData MCP tool '%s' failed: %s", toolName, errStr)
 
 	// Create structured data for programmatic use (though most clients ignore this)
-	errorData := fmt.Sprintf("{\"tool\":\"%s\",\"original_error\":\"%s\"}", toolName, errStr)
-
-	// Check for specific OData error patterns and map to appropriate MCP codes
-	switch {
-	case strings.Contains(errStr, "HTTP 400") || strings.Contains(errStr, "Bad Request"):
-		return -32602, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 401") || strings.Contains(errStr, "Unauthorized"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 403") || strings.Contains(errStr, "Forbidden"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 404") || strings.Contains(errStr, "Not Found"):
-		return -32602, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 409") || strings.Contains(errStr, "Conflict"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 422") || strings.Contains(errStr, "Unprocessable"):
-		return -32602, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 429") || strings.Contains(errStr, "Too Many Requests"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 500") || strings.Contains(errStr, "Internal Server Error"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 502") || strings.Contains(errStr, "Bad Gateway"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "HTTP 503") || strings.Contains(errStr, "Service Unavailable"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "CSRF token"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline exceeded"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "connection refused") || strings.Contains(errStr, "network"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "invalid metadata") || strings.Contains(errStr, "metadata"):
-		return -32603, fullErrorMessage, errorData
-
-	case strings.Contains(errStr, "invalid entity") || strings.Contains(errStr, "entity not found"):
-		return -32602, fullErrorMessage, errorData
+	data := map[string]interface{}{
+		"tool":           toolName,
+		"original_error": errStr,
+	}
+	var detailed DetailedError
+	if errors.As(err, &detailed) {
+		for key, value := range detailed.ErrorDetails() {
+			data[key] = value
+		}
+	}
+	errorData, _ := json.Marshal(data)
 
-	default:
-		// Generic internal error with full context
-		return -32603, fullErrorMessage, errorData
+	var status statusError
+	if errors.As(err, &status) {
+		switch status.HTTPStatus() {
+		case 400, 404, 422:
+			return -32602, fullErrorMessage, string(errorData)
+		}
 	}
+	return -32603, fullErrorMessage, string(errorData)
 }
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// session is the state one client negotiated in initialize. Transports that serve several
+// clients tell them apart by the session ID in the message context; single-client
+// transports all use the "" session.
+type session struct {
+	initialized        bool
+	protocolVersion    string
+	clientCapabilities map[string]interface{}   // Capabilities declared by the client in initialize
+	clientInfo         map[string]interface{}   // Client name and version sent in initialize
+	subscriptions      map[string]*subscription // Subscribed resources, by URI
+	logLevel           *slog.Level              // Set by logging/setLevel; nil sends no log records
+}
+
+// session returns the session of a message, creating it on first use. Callers hold s.mu.
+func (s *Server) session(ctx context.Context) *session {
+	id := transport.SessionFromContext(ctx)
+	sess, ok := s.sessions[id]
+	if !ok {
+		sess = &session{protocolVersion: s.protocolVersion}
+		s.sessions[id] = sess
+	}
+	return sess
+}
+
+// lookupSession returns the session of a message, or nil before initialize. Callers hold s.mu.
+func (s *Server) lookupSession(ctx context.Context) *session {
+	return s.sessions[transport.SessionFromContext(ctx)]
+}
+
+// CloseSession forgets the state of a transport session that was terminated or expired
+func (s *Server) CloseSession(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// Initialized reports whether the client of a message sent the initialized notification
+func (s *Server) Initialized(ctx context.Context) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess := s.lookupSession(ctx)
+	return sess != nil && sess.initialized
+}
+
+// ProtocolVersion returns the protocol version agreed with the client of a message
+func (s *Server) ProtocolVersion(ctx context.Context) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sess := s.lookupSession(ctx); sess != nil {
+		return sess.protocolVersion
+	}
+	return s.protocolVersion
+}
@@ -62,6 +62,9 @@ type Property struct {
 	MaxLength string   `xml:"MaxLength,attr"`
 	Precision string   `xml:"Precision,attr"`
 	Scale     string   `xml:"Scale,attr"`
+	// SAP analytical annotation; matched by namespace URI since encoding/xml
+	// resolves the "sap:" prefix before comparing attribute names
+	AggregationRole string `xml:"http://www.sap.com/Protocols/SAPData aggregation-role,attr"`
 }
 
 // NavigationProperty represents a navigation property
@@ -188,6 +191,8 @@ func parseEntityType(et EntityType) *models.EntityType {
 			Type:     prop.Type,
 			Nullable: prop.Nullable != "false", // Default to true if not specified
 			IsKey:    contains(entityType.KeyProperties, prop.Name),
+
+			AggregationRole: prop.AggregationRole,
 		}
 		entityType.Properties = append(entityType.Properties, property)
 	}
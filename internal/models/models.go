@@ -9,6 +9,8 @@ type EntityProperty struct {
 	Nullable    bool    `json:"nullable"`
 	IsKey       bool    `json:"is_key"`
 	Description *string `json:"description,omitempty"`
+	// SAP analytical annotation (v2 only): "dimension" or "measure"
+	AggregationRole string `json:"aggregation_role,omitempty"`
 }
 
 // EntityType represents an OData entity type definition
@@ -113,6 +115,7 @@ type ODataResponse struct {
 	Value    interface{}            `json:"value,omitempty"`
 	Error    *ODataError            `json:"error,omitempty"`
 	Metadata map[string]interface{} `json:"@odata.metadata,omitempty"`
+	ETag     string                 `json:"-"` // ETag response header, if the service sent one
 
 	// Alternative format for Python-style responses
 	Results    interface{}     `json:"results,omitempty"`
@@ -151,21 +154,30 @@ type ToolParameter struct {
 
 // TraceInfo represents comprehensive information for trace mode
 type TraceInfo struct {
-	ServiceURL      string          `json:"service_url"`
-	MCPName         string          `json:"mcp_name"`
-	ToolNaming      string          `json:"tool_naming"`
-	ToolPrefix      string          `json:"tool_prefix,omitempty"`
-	ToolPostfix     string          `json:"tool_postfix,omitempty"`
-	ToolShrink      bool            `json:"tool_shrink"`
-	SortTools       bool            `json:"sort_tools"`
-	EntityFilter    []string        `json:"entity_filter,omitempty"`
-	FunctionFilter  []string        `json:"function_filter,omitempty"`
-	OperationFilter string          `json:"operation_filter,omitempty"`
-	Authentication  string          `json:"authentication"`
-	ReadOnlyMode    string          `json:"read_only_mode,omitempty"`
-	MetadataSummary MetadataSummary `json:"metadata_summary"`
-	RegisteredTools []ToolInfo      `json:"registered_tools"`
-	TotalTools      int             `json:"total_tools"`
+	ServiceURL      string           `json:"service_url"`
+	MCPName         string           `json:"mcp_name"`
+	ToolNaming      string           `json:"tool_naming"`
+	ToolPrefix      string           `json:"tool_prefix,omitempty"`
+	ToolPostfix     string           `json:"tool_postfix,omitempty"`
+	ToolShrink      bool             `json:"tool_shrink"`
+	SortTools       bool             `json:"sort_tools"`
+	EntityFilter    []string         `json:"entity_filter,omitempty"`
+	FunctionFilter  []string         `json:"function_filter,omitempty"`
+	OperationFilter string           `json:"operation_filter,omitempty"`
+	Authentication  string           `json:"authentication"`
+	ReadOnlyMode    string           `json:"read_only_mode,omitempty"`
+	MetadataSummary MetadataSummary  `json:"metadata_summary"`
+	AccessPolicy    *PolicyTraceInfo `json:"access_policy,omitempty"`
+	RegisteredTools []ToolInfo       `json:"registered_tools"`
+	TotalTools      int              `json:"total_tools"`
+}
+
+// PolicyTraceInfo shows the effective access policy per entity set and function
+type PolicyTraceInfo struct {
+	File         string                 `json:"file"`
+	DenyUnlisted bool                   `json:"deny_unlisted"`
+	EntitySets   map[string]interface{} `json:"entity_sets"`
+	Functions    map[string]interface{} `json:"functions,omitempty"`
 }
 
 // MetadataSummary represents a summary of parsed metadata
@@ -0,0 +1,333 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package policy loads the declarative access policy that restricts which entity sets,
+// operations, properties and function imports the bridge exposes.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+)
+
+// Property usages a rule can restrict
+const (
+	UsageSelect = "select" // Returned by reads ($select, $expand, aggregate columns)
+	UsageFilter = "filter" // Referenced in $filter, $orderby or aggregate groupby
+	UsageWrite  = "write"  // Sent in create and update payloads
+)
+
+// Operation groups accepted in a rule's operations list
+const (
+	OperationsRead  = "read"  // filter, count, search, get, aggregate, export
+	OperationsWrite = "write" // create, update, delete
+)
+
+var operationGroups = map[string][]string{
+	OperationsRead:  {constants.OpFilter, constants.OpCount, constants.OpSearch, constants.OpGet, constants.OpAggregate, constants.OpExport},
+	OperationsWrite: {constants.OpCreate, constants.OpUpdate, constants.OpDelete},
+}
+
+// Policy is the parsed access policy file
+type Policy struct {
+	// DenyUnlisted hides entity sets and functions that no rule matches
+	DenyUnlisted bool                      `json:"deny_unlisted,omitempty" yaml:"deny_unlisted"`
+	EntitySets   map[string]*EntitySetRule `json:"entity_sets,omitempty" yaml:"entity_sets"`
+	Functions    map[string]*FunctionRule  `json:"functions,omitempty" yaml:"functions"`
+
+	path string
+}
+
+// EntitySetRule restricts one entity set, or every set matching a wildcard pattern
+type EntitySetRule struct {
+	Deny       bool         `json:"deny,omitempty" yaml:"deny"`             // Hide the entity set entirely
+	Operations []string     `json:"operations,omitempty" yaml:"operations"` // Allowed operations (empty = all)
+	Properties PropertyRule `json:"properties,omitempty" yaml:"properties"` // Applies to select, filter and write
+	Select     PropertyRule `json:"select,omitempty" yaml:"select"`
+	Filter     PropertyRule `json:"filter,omitempty" yaml:"filter"`
+	Write      PropertyRule `json:"write,omitempty" yaml:"write"`
+	Filters    []string     `json:"filters,omitempty" yaml:"filters"` // Mandatory clauses ANDed to every query
+	MaxTop     int          `json:"max_top,omitempty" yaml:"max_top"` // Upper bound for $top (0 = unlimited)
+
+	Pattern string `json:"-" yaml:"-"` // Key the rule was matched by
+}
+
+// PropertyRule is an allow list and a deny list of property names
+type PropertyRule struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow"` // Only these properties (empty = all)
+	Deny  []string `json:"deny,omitempty" yaml:"deny"`   // Never these properties
+}
+
+// FunctionRule allows or denies a function import
+type FunctionRule struct {
+	Deny bool `json:"deny,omitempty" yaml:"deny"`
+
+	Pattern string `json:"-" yaml:"-"`
+}
+
+// Load reads a policy from a YAML or JSON file. Unknown fields are rejected so a
+// misspelled restriction cannot silently be ignored.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var p Policy
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&p)
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	p.path = path
+	return &p, nil
+}
+
+// Path returns the file the policy was loaded from
+func (p *Policy) Path() string {
+	return p.path
+}
+
+// validate checks operation names and limits
+func (p *Policy) validate() error {
+	for pattern, rule := range p.EntitySets {
+		if rule == nil {
+			return fmt.Errorf("entity set %s: empty rule", pattern)
+		}
+		for _, op := range rule.Operations {
+			if !isKnownOperation(op) {
+				return fmt.Errorf("entity set %s: unknown operation %q (valid: read, write, %s)", pattern, op, strings.Join(allOperations(), ", "))
+			}
+		}
+		if rule.MaxTop < 0 {
+			return fmt.Errorf("entity set %s: max_top must not be negative", pattern)
+		}
+		for _, clause := range rule.Filters {
+			if strings.TrimSpace(clause) == "" {
+				return fmt.Errorf("entity set %s: empty mandatory filter", pattern)
+			}
+		}
+		rule.Pattern = pattern
+	}
+	for pattern, rule := range p.Functions {
+		if rule == nil {
+			p.Functions[pattern] = &FunctionRule{}
+			rule = p.Functions[pattern]
+		}
+		rule.Pattern = pattern
+	}
+	return nil
+}
+
+// EntitySet returns the rule for an entity set: an exact match first, then the longest
+// matching wildcard pattern. It returns nil when no rule matches (or p is nil).
+func (p *Policy) EntitySet(name string) *EntitySetRule {
+	if p == nil {
+		return nil
+	}
+	if rule, ok := p.EntitySets[name]; ok {
+		return rule
+	}
+	var best *EntitySetRule
+	for pattern, rule := range p.EntitySets {
+		if matchesPattern(name, pattern) && (best == nil || len(pattern) > len(best.Pattern)) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// Function returns the rule for a function import, matched like EntitySet
+func (p *Policy) Function(name string) *FunctionRule {
+	if p == nil {
+		return nil
+	}
+	if rule, ok := p.Functions[name]; ok {
+		return rule
+	}
+	var best *FunctionRule
+	for pattern, rule := range p.Functions {
+		if matchesPattern(name, pattern) && (best == nil || len(pattern) > len(best.Pattern)) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// AllowsEntitySet checks whether an entity set is exposed at all
+func (p *Policy) AllowsEntitySet(name string) bool {
+	if p == nil {
+		return true
+	}
+	rule := p.EntitySet(name)
+	if rule == nil {
+		return !p.DenyUnlisted
+	}
+	return !rule.Deny
+}
+
+// AllowsFunction checks whether a function import is exposed
+func (p *Policy) AllowsFunction(name string) bool {
+	if p == nil {
+		return true
+	}
+	rule := p.Function(name)
+	if rule == nil {
+		return !p.DenyUnlisted
+	}
+	return !rule.Deny
+}
+
+// AllowsOperation checks an operation (constants.OpFilter, OpCreate, ...) against the rule
+func (r *EntitySetRule) AllowsOperation(operation string) bool {
+	if r == nil || len(r.Operations) == 0 {
+		return true
+	}
+	for _, op := range r.Operations {
+		op = strings.ToLower(op)
+		if op == operation {
+			return true
+		}
+		for _, member := range operationGroups[op] {
+			if member == operation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowsProperty checks a property for a usage (UsageSelect, UsageFilter or UsageWrite)
+func (r *EntitySetRule) AllowsProperty(usage, property string) bool {
+	if r == nil {
+		return true
+	}
+	if !r.Properties.allows(property) {
+		return false
+	}
+	switch usage {
+	case UsageSelect:
+		return r.Select.allows(property)
+	case UsageFilter:
+		return r.Filter.allows(property)
+	case UsageWrite:
+		return r.Write.allows(property)
+	}
+	return true
+}
+
+// RestrictsProperties reports whether some properties are hidden for a usage
+func (r *EntitySetRule) RestrictsProperties(usage string) bool {
+	if r == nil {
+		return false
+	}
+	if !r.Properties.empty() {
+		return true
+	}
+	switch usage {
+	case UsageSelect:
+		return !r.Select.empty()
+	case UsageFilter:
+		return !r.Filter.empty()
+	case UsageWrite:
+		return !r.Write.empty()
+	}
+	return false
+}
+
+// MandatoryFilter returns the mandatory clauses as one expression, or "" if there are none
+func (r *EntitySetRule) MandatoryFilter() string {
+	if r == nil || len(r.Filters) == 0 {
+		return ""
+	}
+	if len(r.Filters) == 1 {
+		return strings.TrimSpace(r.Filters[0])
+	}
+	parts := make([]string, len(r.Filters))
+	for i, clause := range r.Filters {
+		parts[i] = "(" + strings.TrimSpace(clause) + ")"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// NamedProperties returns every property the rule names, for checking against metadata
+func (r *EntitySetRule) NamedProperties() []string {
+	var names []string
+	for _, pr := range []PropertyRule{r.Properties, r.Select, r.Filter, r.Write} {
+		names = append(names, pr.Allow...)
+		names = append(names, pr.Deny...)
+	}
+	return names
+}
+
+func (pr PropertyRule) allows(property string) bool {
+	for _, name := range pr.Deny {
+		if name == property {
+			return false
+		}
+	}
+	if len(pr.Allow) == 0 {
+		return true
+	}
+	for _, name := range pr.Allow {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+func (pr PropertyRule) empty() bool {
+	return len(pr.Allow) == 0 && len(pr.Deny) == 0
+}
+
+// matchesPattern supports the same prefix* and *suffix wildcards as --entities and --functions
+func matchesPattern(name, pattern string) bool {
+	if pattern == name || pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))
+	}
+	if strings.HasPrefix(pattern, "*") {
+		return strings.HasSuffix(name, strings.TrimPrefix(pattern, "*"))
+	}
+	return false
+}
+
+func allOperations() []string {
+	return append(append([]string{}, operationGroups[OperationsRead]...), operationGroups[OperationsWrite]...)
+}
+
+func isKnownOperation(op string) bool {
+	op = strings.ToLower(op)
+	if _, ok := operationGroups[op]; ok {
+		return true
+	}
+	for _, known := range allOperations() {
+		if op == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/constants"
+)
+
+func writePolicy(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlPolicy := `
+deny_unlisted: true
+entity_sets:
+  Products:
+    operations: [read, update]
+    select:
+      deny: [Price]
+    filters: ["Discontinued eq false", "Price lt 100"]
+    max_top: 50
+  "Sales*":
+    operations: [filter]
+  Secret*:
+    deny: true
+functions:
+  "Get*": {}
+  GetSecrets:
+    deny: true
+`
+	jsonPolicy := `{
+  "deny_unlisted": true,
+  "entity_sets": {
+    "Products": {"operations": ["read", "update"], "select": {"deny": ["Price"]}, "filters": ["Discontinued eq false", "Price lt 100"], "max_top": 50},
+    "Sales*": {"operations": ["filter"]},
+    "Secret*": {"deny": true}
+  },
+  "functions": {"Get*": {}, "GetSecrets": {"deny": true}}
+}`
+
+	for name, content := range map[string]string{"policy.yaml": yamlPolicy, "policy.json": jsonPolicy} {
+		t.Run(name, func(t *testing.T) {
+			p, err := Load(writePolicy(t, name, content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			products := p.EntitySet("Products")
+			if !products.AllowsOperation(constants.OpGet) || !products.AllowsOperation(constants.OpUpdate) || products.AllowsOperation(constants.OpDelete) {
+				t.Errorf("unexpected operations for Products: %v", products.Operations)
+			}
+			if products.AllowsProperty(UsageSelect, "Price") || !products.AllowsProperty(UsageFilter, "Price") {
+				t.Error("Price must be hidden from reads only")
+			}
+			if got := products.MandatoryFilter(); got != "(Discontinued eq false) and (Price lt 100)" {
+				t.Errorf("MandatoryFilter() = %q", got)
+			}
+			if products.MaxTop != 50 {
+				t.Errorf("MaxTop = %d", products.MaxTop)
+			}
+
+			if !p.AllowsEntitySet("SalesOrders") || p.EntitySet("SalesOrders").AllowsOperation(constants.OpCount) {
+				t.Error("SalesOrders should match Sales* and allow filter only")
+			}
+			if p.AllowsEntitySet("SecretKeys") || p.AllowsEntitySet("Customers") {
+				t.Error("denied and unlisted entity sets must be hidden")
+			}
+			if !p.AllowsFunction("GetProducts") || p.AllowsFunction("GetSecrets") || p.AllowsFunction("ResetAll") {
+				t.Error("unexpected function decisions")
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"unknown yaml field", "p.yaml", "entity_sets:\n  Products:\n    max_rows: 5\n", "max_rows"},
+		{"unknown json field", "p.json", `{"entity_sets":{"Products":{"selct":{}}}}`, "selct"},
+		{"unknown operation", "p.yaml", "entity_sets:\n  Products:\n    operations: [drop]\n", `unknown operation "drop"`},
+		{"negative max_top", "p.yaml", "entity_sets:\n  Products:\n    max_top: -1\n", "max_top"},
+		{"empty filter", "p.yaml", "entity_sets:\n  Products:\n    filters: [\" \"]\n", "empty mandatory filter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePolicy(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleMatching(t *testing.T) {
+	p := &Policy{
+		EntitySets: map[string]*EntitySetRule{
+			"*":           {MaxTop: 100},
+			"Sales*":      {MaxTop: 10},
+			"SalesOrder*": {MaxTop: 5},
+			"SalesOrders": {MaxTop: 1},
+		},
+	}
+	if err := p.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	tests := map[string]int{
+		"SalesOrders":     1, // exact match wins
+		"SalesOrderItems": 5, // longest pattern wins
+		"SalesQuotes":     10,
+		"Customers":       100,
+	}
+	for name, want := range tests {
+		if got := p.EntitySet(name).MaxTop; got != want {
+			t.Errorf("EntitySet(%s).MaxTop = %d, want %d", name, got, want)
+		}
+	}
+
+	// A nil policy or rule allows everything
+	var none *Policy
+	if !none.AllowsEntitySet("Anything") || !none.AllowsFunction("Anything") {
+		t.Error("nil policy must allow everything")
+	}
+	if rule := none.EntitySet("Anything"); !rule.AllowsOperation(constants.OpDelete) || !rule.AllowsProperty(UsageWrite, "Price") || rule.MandatoryFilter() != "" {
+		t.Error("nil rule must allow everything")
+	}
+}
+
+func TestPropertyRules(t *testing.T) {
+	rule := &EntitySetRule{
+		Properties: PropertyRule{Deny: []string{"Salary"}},
+		Write:      PropertyRule{Allow: []string{"Name", "Email"}},
+	}
+
+	if rule.AllowsProperty(UsageSelect, "Salary") || rule.AllowsProperty(UsageFilter, "Salary") {
+		t.Error("properties.deny applies to every usage")
+	}
+	if !rule.AllowsProperty(UsageWrite, "Email") || rule.AllowsProperty(UsageWrite, "ManagerID") {
+		t.Error("write.allow restricts writes to the listed properties")
+	}
+	if !rule.AllowsProperty(UsageSelect, "ManagerID") {
+		t.Error("write.allow must not restrict reads")
+	}
+	if !rule.RestrictsProperties(UsageSelect) || (&EntitySetRule{Write: rule.Write}).RestrictsProperties(UsageSelect) {
+		t.Error("unexpected RestrictsProperties result")
+	}
+}
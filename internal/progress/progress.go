@@ -0,0 +1,41 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package progress lets long-running work report how far it got through the request
+// context. The MCP server installs a reporter for tool calls that carry a progressToken
+// and sends each report to the client as notifications/progress.
+package progress
+
+import "context"
+
+// Reporter receives progress reports. total is 0 when it is not known; message may be
+// empty.
+type Reporter func(progress, total float64, message string)
+
+type reporterKey struct{}
+
+// WithReporter returns a context whose progress reports go to r
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// Report reports progress of the request handled in ctx. It does nothing when the client
+// did not ask for progress.
+func Report(ctx context.Context, progress, total float64, message string) {
+	if ctx == nil {
+		return
+	}
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok {
+		r(progress, total, message)
+	}
+}
+
+// Enabled reports whether progress reports of the request handled in ctx reach a client,
+// so callers can skip building messages nobody reads
+func Enabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctx.Value(reporterKey{}).(Reporter)
+	return ok
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+// Package redact masks or hashes personal data in OData responses before they reach the
+// model: whole properties selected by name glob or Edm type, and values matching regexes.
+package redact
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zmcp/odata-mcp/internal/debug"
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+// Replacement modes
+const (
+	ModeMask = "mask" // "***" for whole properties, last 4 characters kept for pattern matches
+	ModeHash = "hash" // Keyed SHA-256 digest, stable for the lifetime of the process
+)
+
+// maskShowLast is how many trailing characters a masked pattern match keeps
+const maskShowLast = 4
+
+// BuiltinPatterns are value patterns that can be referred to by name
+var BuiltinPatterns = map[string]string{
+	"email": `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	"iban":  `\b[A-Z]{2}[0-9]{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`,
+}
+
+var (
+	// comparisonPattern finds "<property> <op> <literal>" in filter expressions quoted by error messages
+	comparisonPattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)(\s+(?:eq|ne|gt|ge|lt|le)\s+)('(?:[^']|'')*'|[^\s)&,]+)`)
+	// jsonFieldPattern finds "<property>": <value> in payloads quoted by error messages
+	jsonFieldPattern = regexp.MustCompile(`"([A-Za-z_][A-Za-z0-9_]*)"(\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\s]+)`)
+)
+
+// minSecretLength keeps short argument values (flags, small numbers) from being scrubbed out
+// of unrelated parts of error messages
+const minSecretLength = 3
+
+// Redactor applies a set of redaction rules
+type Redactor struct {
+	properties []string         // Lower-cased name globs
+	types      map[string]bool  // Edm types whose properties are redacted
+	typed      map[string]bool  // Property names resolved from types
+	patterns   []*regexp.Regexp // Value patterns replaced inside strings
+	mode       string
+	key        []byte
+}
+
+// New compiles redaction rules. Patterns are regexes or names from BuiltinPatterns.
+func New(properties, types, patterns []string, mode string) (*Redactor, error) {
+	r := &Redactor{
+		types: make(map[string]bool),
+		typed: make(map[string]bool),
+		mode:  strings.ToLower(strings.TrimSpace(mode)),
+	}
+	if r.mode == "" {
+		r.mode = ModeMask
+	}
+	if r.mode != ModeMask && r.mode != ModeHash {
+		return nil, fmt.Errorf("unknown redaction mode '%s' (valid: mask, hash)", mode)
+	}
+
+	for _, glob := range properties {
+		glob = strings.ToLower(strings.TrimSpace(glob))
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid property pattern '%s': %w", glob, err)
+		}
+		r.properties = append(r.properties, glob)
+	}
+	for _, t := range types {
+		if t = strings.TrimSpace(t); t != "" {
+			if !strings.Contains(t, ".") {
+				t = "Edm." + t
+			}
+			r.types[t] = true
+		}
+	}
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if builtin, ok := BuiltinPatterns[strings.ToLower(p)]; ok {
+			p = builtin
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	r.key = make([]byte, 32)
+	if _, err := rand.Read(r.key); err != nil {
+		return nil, fmt.Errorf("failed to create hash key: %w", err)
+	}
+	return r, nil
+}
+
+// Empty reports whether the rules can never redact anything
+func (r *Redactor) Empty() bool {
+	return r == nil || (len(r.properties) == 0 && len(r.types) == 0 && len(r.patterns) == 0)
+}
+
+// Mode returns the replacement mode
+func (r *Redactor) Mode() string {
+	return r.mode
+}
+
+// ResolveTypes marks every property of the given Edm types as redacted, across all
+// entity types, so responses can be redacted by property name alone
+func (r *Redactor) ResolveTypes(metadata *models.ODataMetadata) {
+	if r == nil || metadata == nil || len(r.types) == 0 {
+		return
+	}
+	for _, et := range metadata.EntityTypes {
+		for _, prop := range et.Properties {
+			if r.types[prop.Type] {
+				r.typed[prop.Name] = true
+			}
+		}
+	}
+}
+
+// IsRedacted checks whether a property's whole value is redacted
+func (r *Redactor) IsRedacted(property string) bool {
+	if r == nil {
+		return false
+	}
+	if r.typed[property] {
+		return true
+	}
+	name := strings.ToLower(property)
+	for _, glob := range r.properties {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Value replaces a whole value; nil stays nil so nullability is still visible
+func (r *Redactor) Value(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if r.mode == ModeHash {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case map[string]interface{}, []interface{}:
+			data, _ := json.Marshal(v)
+			s = string(data)
+		default:
+			s = fmt.Sprint(v)
+		}
+		return debug.HashValue(s, r.key)
+	}
+	return debug.MaskPassword("x")
+}
+
+// Text replaces every pattern match inside a string
+func (r *Redactor) Text(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			if r.mode == ModeHash {
+				return debug.HashValue(match, r.key)
+			}
+			return debug.MaskValue(match, maskShowLast)
+		})
+	}
+	return s
+}
+
+// Apply redacts a decoded JSON value in place and returns it with the sorted names of
+// the fields that were changed
+func (r *Redactor) Apply(data interface{}) (interface{}, []string) {
+	if r.Empty() {
+		return data, nil
+	}
+	fields := make(map[string]bool)
+	data = r.apply(data, "", fields)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return data, names
+}
+
+func (r *Redactor) apply(data interface{}, field string, fields map[string]bool) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "__metadata" || key == "__deferred" {
+				continue
+			}
+			if value != nil && r.IsRedacted(key) {
+				v[key] = r.Value(value)
+				fields[key] = true
+				continue
+			}
+			v[key] = r.apply(value, key, fields)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.apply(item, field, fields)
+		}
+		return v
+	case string:
+		if redacted := r.Text(v); redacted != v {
+			if field != "" {
+				fields[field] = true
+			}
+			return redacted
+		}
+	}
+	return data
+}
+
+// Secrets collects the values of redacted properties in tool arguments, so they can be
+// removed from error messages that quote them back
+func (r *Redactor) Secrets(args map[string]interface{}) []string {
+	if r.Empty() {
+		return nil
+	}
+	var secrets []string
+	var walk func(key string, value interface{}, redacted bool)
+	walk = func(key string, value interface{}, redacted bool) {
+		redacted = redacted || r.IsRedacted(key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, item := range v {
+				walk(k, item, redacted)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(key, item, redacted)
+			}
+		case nil:
+		default:
+			if s := fmt.Sprint(v); redacted && len(s) >= minSecretLength {
+				secrets = append(secrets, s)
+			}
+		}
+	}
+	for key, value := range args {
+		walk(key, value, false)
+	}
+	// Longest first, so a value containing another is replaced whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// Message removes redacted values from an error message: the given argument values,
+// literals compared with redacted properties, redacted JSON fields and pattern matches
+func (r *Redactor) Message(msg string, secrets []string) string {
+	if r.Empty() {
+		return msg
+	}
+	for _, secret := range secrets {
+		msg = strings.ReplaceAll(msg, secret, debug.MaskPassword(secret))
+	}
+	for _, re := range []*regexp.Regexp{comparisonPattern, jsonFieldPattern} {
+		msg = re.ReplaceAllStringFunc(msg, func(match string) string {
+			parts := re.FindStringSubmatch(match)
+			if !r.IsRedacted(parts[1]) {
+				return match
+			}
+			if re == jsonFieldPattern {
+				return `"` + parts[1] + `"` + parts[2] + `"***"`
+			}
+			return parts[1] + parts[2] + "***"
+		})
+	}
+	return r.Text(msg)
+}
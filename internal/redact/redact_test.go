@@ -0,0 +1,117 @@
+// Copyright (c) 2024 OData MCP Contributors
+// SPDX-License-Identifier: MIT
+
+package redact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zmcp/odata-mcp/internal/models"
+)
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	if _, err := New(nil, nil, nil, "scramble"); err == nil {
+		t.Error("expected unknown mode to be rejected")
+	}
+	if _, err := New([]string{"[Salary"}, nil, nil, ""); err == nil {
+		t.Error("expected invalid glob to be rejected")
+	}
+	if _, err := New(nil, nil, []string{"(unclosed"}, ""); err == nil {
+		t.Error("expected invalid regex to be rejected")
+	}
+	r, err := New([]string{"", " "}, []string{""}, nil, "")
+	if err != nil || !r.Empty() || r.Mode() != ModeMask {
+		t.Errorf("blank rules should give an empty mask redactor, got %v, %v", r, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	r, err := New([]string{"*salary*", "IBAN"}, []string{"Binary"}, []string{"email"}, ModeMask)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.ResolveTypes(&models.ODataMetadata{
+		EntityTypes: map[string]*models.EntityType{
+			"Employee": {Properties: []*models.EntityProperty{
+				{Name: "Photo", Type: "Edm.Binary"},
+				{Name: "Name", Type: "Edm.String"},
+			}},
+		},
+	})
+
+	data := map[string]interface{}{
+		"results": []interface{}{
+			map[string]interface{}{
+				"__metadata":   map[string]interface{}{"uri": "Employees(1)"},
+				"Name":         "Ann",
+				"BaseSalary":   "85000.00",
+				"Iban":         "DE89370400440532013000",
+				"Photo":        "iVBORw0KGgo=",
+				"Note":         "contact ann.lee@example.com",
+				"BonusSalary":  nil,
+				"Organization": map[string]interface{}{"Name": "Sales"},
+			},
+		},
+	}
+
+	out, fields := r.Apply(data)
+	row := out.(map[string]interface{})["results"].([]interface{})[0].(map[string]interface{})
+	if row["BaseSalary"] != "***" || row["Iban"] != "***" || row["Photo"] != "***" {
+		t.Errorf("whole-property rules not applied: %v", row)
+	}
+	if row["BonusSalary"] != nil {
+		t.Error("null values should stay null")
+	}
+	if note := row["Note"].(string); strings.Contains(note, "ann.lee") || !strings.HasSuffix(note, ".com") {
+		t.Errorf("pattern not masked: %q", note)
+	}
+	if row["Name"] != "Ann" || row["__metadata"] == nil {
+		t.Errorf("unrelated fields changed: %v", row)
+	}
+	if want := []string{"BaseSalary", "Iban", "Note", "Photo"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+}
+
+func TestHashMode(t *testing.T) {
+	r, err := New([]string{"Salary"}, nil, []string{"iban"}, ModeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a := r.Value("85000")
+	if a == "85000" || a != r.Value("85000") || a == r.Value("90000") {
+		t.Errorf("hashes must be stable and distinct: %v", a)
+	}
+	if text := r.Text("pay to DE89 3704 0044 0532 0130 00"); strings.Contains(text, "3704") || !strings.Contains(text, "sha256:") {
+		t.Errorf("Text() = %q", text)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	r, err := New([]string{"Salary"}, nil, []string{"email"}, ModeMask)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	args := map[string]interface{}{
+		"entity_set": "Employees",
+		"data":       map[string]interface{}{"Salary": float64(123456), "Name": "Ann"},
+		"top":        float64(10),
+	}
+	secrets := r.Secrets(args)
+	if !reflect.DeepEqual(secrets, []string{"123456"}) {
+		t.Fatalf("Secrets() = %v", secrets)
+	}
+
+	msg := r.Message(`HTTP 400: value 123456 too large for "Salary": 123456; filter Salary gt 90000 and Name eq 'Ann'; owner ann@example.com`, secrets)
+	for _, leaked := range []string{"123456", "90000", "ann@example"} {
+		if strings.Contains(msg, leaked) {
+			t.Errorf("message still contains %q: %s", leaked, msg)
+		}
+	}
+	if !strings.Contains(msg, "HTTP 400") || !strings.Contains(msg, "Name eq 'Ann'") {
+		t.Errorf("unrelated text changed: %s", msg)
+	}
+}
@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// JSON-RPC error codes the transports answer with themselves
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeInternalError  = -32603
+)
+
+// ErrEmptyBatch is returned by DecodeMessages for an empty batch array
+var ErrEmptyBatch = errors.New("empty batch")
+
+// DecodeMessages decodes one message or a JSON-RPC batch (an array of messages). Elements
+// of a batch that are not message objects decode as empty messages, which the server
+// answers with Invalid Request.
+func DecodeMessages(data []byte) (msgs []*Message, batch bool, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, false, err
+		}
+		return []*Message{&msg}, false, nil
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, true, err
+	}
+	if len(elements) == 0 {
+		return nil, true, ErrEmptyBatch
+	}
+	for _, element := range elements {
+		msg := &Message{}
+		if json.Unmarshal(element, msg) != nil {
+			msg = &Message{}
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, true, nil
+}
+
+// ErrorResponse creates an error response to a message; a missing ID is sent as null
+func ErrorResponse(id json.RawMessage, code int, message string) *Message {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Message{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &Error{Code: code, Message: message},
+	}
+}
+
+// DecodeErrorResponse answers a body DecodeMessages could not decode
+func DecodeErrorResponse(err error) *Message {
+	if errors.Is(err, ErrEmptyBatch) {
+		return ErrorResponse(nil, CodeInvalidRequest, "Invalid Request: empty batch")
+	}
+	return ErrorResponse(nil, CodeParseError, "Parse error: "+err.Error())
+}
+
+// Handle passes a message to the handler, turning a handler error into an error response
+func Handle(ctx context.Context, handler Handler, msg *Message) *Message {
+	response, err := handler(ctx, msg)
+	if err != nil {
+		return ErrorResponse(msg.ID, CodeInternalError, err.Error())
+	}
+	return response
+}
+
+// HandleBatch handles the messages of a batch concurrently and returns their responses in
+// batch order, leaving out notifications. initialize cannot be batched: it has to finish
+// before any other request of the session.
+func HandleBatch(ctx context.Context, handler Handler, msgs []*Message) []*Message {
+	responses := make([]*Message, len(msgs))
+	var wg sync.WaitGroup
+	for i, msg := range msgs {
+		if msg.Method == "initialize" {
+			if len(msg.ID) > 0 {
+				responses[i] = ErrorResponse(msg.ID, CodeInvalidRequest, "Invalid Request: initialize cannot be part of a batch")
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, msg *Message) {
+			defer wg.Done()
+			responses[i] = Handle(ctx, handler, msg)
+		}(i, msg)
+	}
+	wg.Wait()
+
+	answered := responses[:0]
+	for _, response := range responses {
+		if response != nil {
+			answered = append(answered, response)
+		}
+	}
+	return answered
+}
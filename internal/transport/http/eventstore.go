@@ -0,0 +1,243 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEventRetention is how long SSE events are kept for Last-Event-ID replay
+	DefaultEventRetention = 5 * time.Minute
+	// maxSessionEvents bounds the events kept per session
+	maxSessionEvents = 1000
+)
+
+// storedEvent is one SSE event kept for replay
+type storedEvent struct {
+	ID     string          `json:"id"`
+	Stream string          `json:"stream"`
+	Seq    int64           `json:"seq"`
+	Event  string          `json:"event"`
+	Data   json.RawMessage `json:"data"`
+	Time   time.Time       `json:"ts"`
+}
+
+// eventStore keeps the recent SSE events of each session, so a client that lost its
+// connection can resume a stream with Last-Event-ID
+type eventStore interface {
+	Append(session string, ev storedEvent) error
+	// After returns the events of a stream with a sequence number above seq
+	After(session, stream string, seq int64) ([]storedEvent, error)
+	Drop(session string) error
+}
+
+// eventID formats the ID of an event; it names the stream so a resume finds it
+func eventID(stream string, seq int64) string {
+	return fmt.Sprintf("%s-%d", stream, seq)
+}
+
+// parseEventID splits an event ID into stream and sequence number
+func parseEventID(id string) (string, int64, bool) {
+	i := strings.LastIndex(id, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseInt(id[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return id[:i], seq, true
+}
+
+// keepEvent reports whether an event is still within the retention window
+func keepEvent(ev storedEvent, retention time.Duration, now time.Time) bool {
+	return now.Sub(ev.Time) <= retention
+}
+
+// memoryEventStore keeps events in memory
+type memoryEventStore struct {
+	retention time.Duration
+	mu        sync.Mutex
+	events    map[string][]storedEvent // By session, oldest first
+}
+
+func newMemoryEventStore(retention time.Duration) *memoryEventStore {
+	return &memoryEventStore{retention: retention, events: make(map[string][]storedEvent)}
+}
+
+func (s *memoryEventStore) Append(session string, ev storedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := append(s.events[session], ev)
+
+	// Drop events beyond the count limit and outside the retention window
+	start := 0
+	if len(events) > maxSessionEvents {
+		start = len(events) - maxSessionEvents
+	}
+	for start < len(events) && !keepEvent(events[start], s.retention, ev.Time) {
+		start++
+	}
+	s.events[session] = append([]storedEvent(nil), events[start:]...)
+	return nil
+}
+
+func (s *memoryEventStore) After(session, stream string, seq int64) ([]storedEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var result []storedEvent
+	for _, ev := range s.events[session] {
+		if ev.Stream == stream && ev.Seq > seq && keepEvent(ev, s.retention, now) {
+			result = append(result, ev)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryEventStore) Drop(session string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, session)
+	return nil
+}
+
+// fileEventStore keeps the events of each session in a JSON lines file, so large
+// results do not stay in memory
+type fileEventStore struct {
+	dir       string
+	retention time.Duration
+	mu        sync.Mutex
+	lines     map[string]int // Lines written per session since the file was last compacted
+}
+
+// newFileEventStore uses dir for event files. Sessions do not survive a restart, so event
+// files left by an earlier process are removed.
+func newFileEventStore(dir string, retention time.Duration) (*fileEventStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create event store directory: %w", err)
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, "*.events.jsonl"))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+	return &fileEventStore{dir: dir, retention: retention, lines: make(map[string]int)}, nil
+}
+
+func (s *fileEventStore) path(session string) string {
+	// Session IDs are hex, so they are safe file names
+	return filepath.Join(s.dir, session+".events.jsonl")
+}
+
+func (s *fileEventStore) Append(session string, ev storedEvent) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path(session), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open event file: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	s.lines[session]++
+	if s.lines[session] > 2*maxSessionEvents {
+		return s.compact(session, ev.Time)
+	}
+	return nil
+}
+
+// compact rewrites a session's file with only the events still kept. Callers hold s.mu.
+func (s *fileEventStore) compact(session string, now time.Time) error {
+	events, err := s.read(session)
+	if err != nil {
+		return err
+	}
+	if len(events) > maxSessionEvents {
+		events = events[len(events)-maxSessionEvents:]
+	}
+
+	var buf bytes.Buffer
+	kept := 0
+	for _, ev := range events {
+		if keepEvent(ev, s.retention, now) {
+			line, _ := json.Marshal(ev)
+			buf.Write(append(line, '\n'))
+			kept++
+		}
+	}
+	tmp := s.path(session) + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to compact event file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(session)); err != nil {
+		return fmt.Errorf("failed to compact event file: %w", err)
+	}
+	s.lines[session] = kept
+	return nil
+}
+
+// read returns all events of a session's file. Callers hold s.mu.
+func (s *fileEventStore) read(session string) ([]storedEvent, error) {
+	f, err := os.Open(s.path(session))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	defer f.Close()
+
+	var events []storedEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var ev storedEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+	return events, scanner.Err()
+}
+
+func (s *fileEventStore) After(session, stream string, seq int64) ([]storedEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events, err := s.read(session)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var result []storedEvent
+	for _, ev := range events {
+		if ev.Stream == stream && ev.Seq > seq && keepEvent(ev, s.retention, now) {
+			result = append(result, ev)
+		}
+	}
+	return result, nil
+}
+
+func (s *fileEventStore) Drop(session string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lines, session)
+	if err := os.Remove(s.path(session)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
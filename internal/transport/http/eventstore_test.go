@@ -0,0 +1,76 @@
+package http
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEventID(t *testing.T) {
+	tests := []struct {
+		id     string
+		stream string
+		seq    int64
+		ok     bool
+	}{
+		{"stream-123-4", "stream-123", 4, true},
+		{eventID("stream-9", 17), "stream-9", 17, true},
+		{"stream", "", 0, false},
+		{"-4", "", 0, false},
+		{"stream-x", "", 0, false},
+	}
+	for _, tt := range tests {
+		stream, seq, ok := parseEventID(tt.id)
+		if stream != tt.stream || seq != tt.seq || ok != tt.ok {
+			t.Errorf("parseEventID(%q) = %q, %d, %v", tt.id, stream, seq, ok)
+		}
+	}
+}
+
+func TestEventStores(t *testing.T) {
+	file, err := newFileEventStore(t.TempDir(), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]eventStore{
+		"memory": newMemoryEventStore(time.Minute),
+		"file":   file,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			add := func(session, stream string, seq int64, at time.Time) {
+				ev := storedEvent{ID: eventID(stream, seq), Stream: stream, Seq: seq, Event: "message", Data: []byte(`{}`), Time: at}
+				if err := store.Append(session, ev); err != nil {
+					t.Fatal(err)
+				}
+			}
+			add("a", "s1", 1, now.Add(-2*time.Minute)) // Outside the retention window
+			add("a", "s1", 2, now)
+			add("a", "s1", 3, now)
+			add("a", "s2", 1, now)
+			add("b", "s1", 4, now)
+
+			events, err := store.After("a", "s1", 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 2 || events[0].Seq != 2 || events[1].Seq != 3 {
+				t.Errorf("After(a, s1, 1) = %+v", events)
+			}
+			if events, _ := store.After("a", "s1", 0); len(events) != 2 {
+				t.Errorf("expired event replayed: %+v", events)
+			}
+
+			if err := store.Drop("a"); err != nil {
+				t.Fatal(err)
+			}
+			if events, _ := store.After("a", "s1", 0); len(events) != 0 {
+				t.Errorf("dropped session still has events: %+v", events)
+			}
+			if events, _ := store.After("b", "s1", 0); len(events) != 1 {
+				t.Errorf("other session lost its events: %+v", events)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+)
+
+// SessionHeader carries the session ID of the streamable HTTP transport
+const SessionHeader = "Mcp-Session-Id"
+
+const (
+	// DefaultSessionTimeout is how long a session without requests is kept
+	DefaultSessionTimeout = 30 * time.Minute
+	// maxSessions bounds the sessions kept in memory
+	maxSessions = 1000
+)
+
+// httpSession is one client of the streamable HTTP transport, from initialize until
+// it is deleted or expires
+type httpSession struct {
+	id       string
+	owner    string // Authenticated caller that created the session ("" without authentication)
+	lastSeen time.Time
+}
+
+// SetSessionTimeout sets how long idle sessions are kept
+func (t *StreamableHTTPTransport) SetSessionTimeout(d time.Duration) {
+	if d > 0 {
+		t.sessionTimeout = d
+	}
+}
+
+// OnSessionClosed registers a function called when a session is deleted or expires
+func (t *StreamableHTTPTransport) OnSessionClosed(fn func(id string)) {
+	t.onSessionClosed = fn
+}
+
+// sessionOwner identifies the caller of a request, so sessions cannot be used by others
+func sessionOwner(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return p.Method + ":" + p.Subject
+	}
+	return ""
+}
+
+// createSession starts a session for an initialize request, or returns nil when the
+// session table is full
+func (t *StreamableHTTPTransport) createSession(r *http.Request) *httpSession {
+	t.expireSessions(time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.sessions) >= maxSessions {
+		return nil
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	sess := &httpSession{id: hex.EncodeToString(buf), owner: sessionOwner(r), lastSeen: time.Now()}
+	t.sessions[sess.id] = sess
+	return sess
+}
+
+// requireSession returns the session named by the request's Mcp-Session-Id header, or
+// answers 400 without the header and 404 for unknown or expired sessions
+func (t *StreamableHTTPTransport) requireSession(w http.ResponseWriter, r *http.Request) (*httpSession, bool) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, "Missing "+SessionHeader+" header; send initialize first", http.StatusBadRequest)
+		return nil, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sess, ok := t.sessions[id]
+	if !ok || sess.owner != sessionOwner(r) {
+		http.Error(w, "Session not found or expired; send initialize to start a new session", http.StatusNotFound)
+		return nil, false
+	}
+	sess.lastSeen = time.Now()
+	return sess, true
+}
+
+// handleDeleteSession terminates the session named by the Mcp-Session-Id header
+func (t *StreamableHTTPTransport) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := t.requireSession(w, r)
+	if !ok {
+		return
+	}
+	t.closeSession(sess.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// closeSession forgets a session, ends its open streams and drops its stored events
+func (t *StreamableHTTPTransport) closeSession(id string) {
+	t.mu.Lock()
+	_, existed := t.sessions[id]
+	delete(t.sessions, id)
+	for streamID, stream := range t.activeStreams {
+		if stream.session == id {
+			stream.closeOnce.Do(func() { close(stream.done) })
+			delete(t.activeStreams, streamID)
+		}
+	}
+	t.mu.Unlock()
+	t.events.Drop(id)
+
+	if existed && t.onSessionClosed != nil {
+		t.onSessionClosed(id)
+	}
+}
+
+// expireSessions closes sessions idle for longer than the session timeout. Sessions with
+// an open stream are in use and are kept.
+func (t *StreamableHTTPTransport) expireSessions(now time.Time) {
+	t.mu.RLock()
+	var expired []string
+	for id, sess := range t.sessions {
+		if now.Sub(sess.lastSeen) > t.sessionTimeout && !t.hasStreamLocked(id) {
+			expired = append(expired, id)
+		}
+	}
+	t.mu.RUnlock()
+
+	for _, id := range expired {
+		t.closeSession(id)
+	}
+}
+
+// hasStreamLocked reports whether a session has an open stream. Callers hold t.mu.
+func (t *StreamableHTTPTransport) hasStreamLocked(id string) bool {
+	for _, stream := range t.activeStreams {
+		if stream.session == id {
+			return true
+		}
+	}
+	return false
+}
@@ -4,16 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/logging"
 	"github.com/zmcp/odata-mcp/internal/transport"
 )
 
+// Transports log locally only: a log notification about a dropped message would be sent
+// through the transport that dropped it
+var logger = logging.LocalLogger("transport")
+
 // SSETransport implements the Transport interface for Server-Sent Events
 type SSETransport struct {
 	addr            string
@@ -22,16 +28,17 @@ type SSETransport struct {
 	clients         map[string]*sseClient
 	mu              sync.RWMutex
 	messages        chan *clientMessage
-	droppedMessages int64 // Atomic counter for dropped messages
-	verbose         bool  // Enable verbose logging for dropped messages
+	droppedMessages int64               // Atomic counter for dropped messages
+	auth            *auth.Authenticator // Authenticates /sse and /rpc callers (nil = open)
 }
 
 type sseClient struct {
-	id      string
-	events  chan []byte
-	done    chan struct{}
-	writer  http.ResponseWriter
-	flusher http.Flusher
+	id        string
+	principal *auth.Principal // Authenticated caller, passed on to handled messages
+	events    chan []byte
+	done      chan struct{}
+	writer    http.ResponseWriter
+	flusher   http.Flusher
 }
 
 type clientMessage struct {
@@ -49,9 +56,17 @@ func NewSSE(addr string, handler transport.Handler) *SSETransport {
 	}
 }
 
-// SetVerbose enables verbose logging for dropped messages
-func (t *SSETransport) SetVerbose(verbose bool) {
-	t.verbose = verbose
+// SetAuthenticator requires callers to authenticate
+func (t *SSETransport) SetAuthenticator(a *auth.Authenticator) {
+	t.auth = a
+}
+
+// protect wraps an endpoint with authentication, if configured
+func (t *SSETransport) protect(h http.HandlerFunc) http.Handler {
+	if t.auth == nil {
+		return h
+	}
+	return t.auth.Middleware(h)
 }
 
 // GetDroppedMessageCount returns the number of dropped messages
@@ -64,10 +79,15 @@ func (t *SSETransport) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 
 	// SSE endpoint for bidirectional communication
-	mux.HandleFunc("/sse", t.handleSSE)
+	mux.Handle("/sse", t.protect(t.handleSSE))
 
 	// Regular HTTP endpoint for request-response
-	mux.HandleFunc("/rpc", t.handleRPC)
+	mux.Handle("/rpc", t.protect(t.handleRPC))
+
+	// OAuth protected resource metadata (RFC 9728)
+	if t.auth != nil && t.auth.OAuthEnabled() {
+		mux.HandleFunc(auth.MetadataPath, t.auth.ServeMetadata)
+	}
 
 	// Health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -86,7 +106,7 @@ func (t *SSETransport) Start(ctx context.Context) error {
 	// Start server
 	go func() {
 		if err := t.server.ListenAndServe(); err != http.ErrServerClosed {
-			fmt.Printf("HTTP server error: %v\n", err)
+			logger.ErrorContext(ctx, "HTTP server error", "error", err)
 		}
 	}()
 
@@ -117,11 +137,12 @@ func (t *SSETransport) handleSSE(w http.ResponseWriter, r *http.Request) {
 
 	// Create client
 	client := &sseClient{
-		id:      fmt.Sprintf("client-%d", time.Now().UnixNano()),
-		events:  make(chan []byte, 10),
-		done:    make(chan struct{}),
-		writer:  w,
-		flusher: flusher,
+		id:        fmt.Sprintf("client-%d", time.Now().UnixNano()),
+		principal: auth.PrincipalFromContext(r.Context()),
+		events:    make(chan []byte, 10),
+		done:      make(chan struct{}),
+		writer:    w,
+		flusher:   flusher,
 	}
 
 	// Register client
@@ -173,24 +194,33 @@ func (t *SSETransport) handleRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var msg transport.Message
-	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	msgs, batch, err := transport.DecodeMessages(body)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, transport.DecodeErrorResponse(err))
+		return
+	}
 
-	// Process the message
+	// Process the message, or each message of a batch
 	ctx := r.Context()
-	response, err := t.handler(ctx, &msg)
-	if err != nil {
-		response = &transport.Message{
-			JSONRPC: "2.0",
-			ID:      msg.ID,
-			Error: &transport.Error{
-				Code:    -32603,
-				Message: err.Error(),
-			},
+	if batch {
+		if responses := transport.HandleBatch(ctx, t.handler, msgs); len(responses) > 0 {
+			writeJSON(w, r, http.StatusOK, responses)
+			return
 		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	response := transport.Handle(ctx, t.handler, msgs[0])
+
+	// Notifications and responses to server-initiated requests have no reply
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -205,7 +235,15 @@ func (t *SSETransport) processMessages(ctx context.Context) {
 			return
 		case cm := <-t.messages:
 			if cm.message.Method != "" && t.handler != nil {
-				response, err := t.handler(ctx, cm.message)
+				// Messages run on the server context; carry over who sent them
+				t.mu.RLock()
+				sender, known := t.clients[cm.clientID]
+				t.mu.RUnlock()
+				msgCtx := context.WithValue(ctx, sseClientKey{}, cm.clientID)
+				if known && sender.principal != nil {
+					msgCtx = auth.WithPrincipal(msgCtx, sender.principal)
+				}
+				response, err := t.handler(msgCtx, cm.message)
 				if err != nil {
 					response = &transport.Message{
 						JSONRPC: "2.0",
@@ -229,10 +267,7 @@ func (t *SSETransport) processMessages(ctx context.Context) {
 					default:
 						// Client buffer full - log and count dropped message
 						dropped := atomic.AddInt64(&t.droppedMessages, 1)
-						if t.verbose {
-							fmt.Fprintf(os.Stderr, "[SSE] Dropped response for client %s: buffer full (total dropped: %d)\n",
-								cm.clientID, dropped)
-						}
+						logger.Warn("Dropped response: buffer full", "client", cm.clientID, "total_dropped", dropped)
 					}
 				}
 			}
@@ -253,10 +288,7 @@ func (t *SSETransport) sendEvent(client *sseClient, eventType string, data inter
 		default:
 			// Buffer full - log and count dropped event
 			dropped := atomic.AddInt64(&t.droppedMessages, 1)
-			if t.verbose {
-				fmt.Fprintf(os.Stderr, "[SSE] Dropped %s event for client %s: buffer full (total dropped: %d)\n",
-					eventType, client.id, dropped)
-			}
+			logger.Warn("Dropped event: buffer full", "event", eventType, "client", client.id, "total_dropped", dropped)
 		}
 	}
 }
@@ -277,16 +309,65 @@ func (t *SSETransport) BroadcastMessage(msg *transport.Message) error {
 		default:
 			// Client buffer full - log and count dropped broadcast
 			dropped := atomic.AddInt64(&t.droppedMessages, 1)
-			if t.verbose {
-				fmt.Fprintf(os.Stderr, "[SSE] Dropped broadcast for client %s: buffer full (total dropped: %d)\n",
-					clientID, dropped)
-			}
+			logger.Warn("Dropped broadcast: buffer full", "client", clientID, "total_dropped", dropped)
 		}
 	}
 
 	return nil
 }
 
+// CanSendRequests reports whether a connected SSE client can receive server-initiated requests
+func (t *SSETransport) CanSendRequests() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.clients) > 0
+}
+
+type sseClientKey struct{}
+
+// WriteMessageFor sends a message to the SSE client whose request is handled in ctx, and
+// to every client for other contexts
+func (t *SSETransport) WriteMessageFor(ctx context.Context, msg *transport.Message) error {
+	clientID, ok := ctx.Value(sseClientKey{}).(string)
+	if !ok {
+		return t.BroadcastMessage(msg)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	client, exists := t.clients[clientID]
+	if !exists {
+		return fmt.Errorf("SSE client %s disconnected", clientID)
+	}
+	select {
+	case client.events <- data:
+	default:
+		// Client buffer full - log and count dropped message
+		dropped := atomic.AddInt64(&t.droppedMessages, 1)
+		logger.Warn("Dropped message: buffer full", "client", clientID, "total_dropped", dropped)
+	}
+	return nil
+}
+
+// CanSendRequestsFor reports whether the SSE client whose request is handled in ctx is
+// still connected
+func (t *SSETransport) CanSendRequestsFor(ctx context.Context) bool {
+	clientID, ok := ctx.Value(sseClientKey{}).(string)
+	if !ok {
+		return t.CanSendRequests()
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, exists := t.clients[clientID]
+	return exists
+}
+
 // ReadMessage is not used for HTTP/SSE transport
 func (t *SSETransport) ReadMessage() (*transport.Message, error) {
 	return nil, fmt.Errorf("ReadMessage not implemented for HTTP/SSE transport")
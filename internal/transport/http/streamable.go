@@ -3,6 +3,8 @@ package http
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/constants"
 	"github.com/zmcp/odata-mcp/internal/transport"
 )
 
@@ -23,18 +27,36 @@ type StreamableHTTPTransport struct {
 	mu             sync.RWMutex
 	activeStreams  map[string]*streamContext
 	enableSecurity bool
+	auth           *auth.Authenticator // Authenticates /mcp and /sse callers (nil = open)
+
+	sessions        map[string]*httpSession // By Mcp-Session-Id
+	sessionTimeout  time.Duration           // Idle time after which sessions expire
+	onSessionClosed func(id string)         // Called when a session is deleted or expires
+
+	events eventStore // Recent SSE events for Last-Event-ID replay
 }
 
 type streamContext struct {
 	id        string
-	writer    http.ResponseWriter
+	session   string              // Mcp-Session-Id of the client the stream belongs to
+	writer    http.ResponseWriter // Connection of the client; nil while it is disconnected
 	flusher   http.Flusher
+	seq       int64 // Sequence number of the last event sent on the stream
+	get       bool  // Standalone GET stream for messages outside of a request
 	done      chan struct{}
-	closeOnce sync.Once // Ensures done channel is closed exactly once
-	writeMu   sync.Mutex // Guards writes to ResponseWriter (not goroutine-safe)
+	closeOnce sync.Once  // Ensures done channel is closed exactly once
+	writeMu   sync.Mutex // Guards writer, seq and writes to ResponseWriter (not goroutine-safe)
 	lastSeen  time.Time
 }
 
+// newStreamID returns a random stream ID. Event IDs name their stream and may outlive the
+// process in a file event store, so IDs must not repeat across streams or restarts.
+func newStreamID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return "stream-" + hex.EncodeToString(buf)
+}
+
 // NewStreamableHTTP creates a new Streamable HTTP transport
 func NewStreamableHTTP(addr string, handler transport.Handler, enableSecurity bool) *StreamableHTTPTransport {
 	return &StreamableHTTPTransport{
@@ -42,7 +64,41 @@ func NewStreamableHTTP(addr string, handler transport.Handler, enableSecurity bo
 		handler:        handler,
 		activeStreams:  make(map[string]*streamContext),
 		enableSecurity: enableSecurity,
+		sessions:       make(map[string]*httpSession),
+		sessionTimeout: DefaultSessionTimeout,
+		events:         newMemoryEventStore(DefaultEventRetention),
+	}
+}
+
+// SetEventStore sets how long SSE events are kept for Last-Event-ID replay and where.
+// Events are kept in memory when dir is empty, otherwise in files below dir.
+func (t *StreamableHTTPTransport) SetEventStore(dir string, retention time.Duration) error {
+	if retention <= 0 {
+		retention = DefaultEventRetention
+	}
+	if dir == "" {
+		t.events = newMemoryEventStore(retention)
+		return nil
+	}
+	store, err := newFileEventStore(dir, retention)
+	if err != nil {
+		return err
+	}
+	t.events = store
+	return nil
+}
+
+// SetAuthenticator requires callers to authenticate; remote connections are then allowed
+func (t *StreamableHTTPTransport) SetAuthenticator(a *auth.Authenticator) {
+	t.auth = a
+}
+
+// protect wraps an endpoint with authentication, if configured
+func (t *StreamableHTTPTransport) protect(h http.HandlerFunc) http.Handler {
+	if t.auth == nil {
+		return h
 	}
+	return t.auth.Middleware(h)
 }
 
 // Start initializes the HTTP server and begins listening
@@ -50,7 +106,13 @@ func (t *StreamableHTTPTransport) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 
 	// Main MCP endpoint - handles both regular POST and SSE upgrades
-	mux.HandleFunc("/mcp", t.handleMCP)
+	mux.Handle("/mcp", t.protect(t.handleMCP))
+
+	// OAuth protected resource metadata, at the root and for the /mcp path (RFC 9728)
+	if t.auth != nil && t.auth.OAuthEnabled() {
+		mux.HandleFunc(auth.MetadataPath, t.auth.ServeMetadata)
+		mux.HandleFunc(auth.MetadataPath+"/mcp", t.auth.ServeMetadata)
+	}
 
 	// Health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -59,12 +121,12 @@ func (t *StreamableHTTPTransport) Start(ctx context.Context) error {
 		json.NewEncoder(w).Encode(map[string]string{
 			"status":    "ok",
 			"transport": "streamable-http",
-			"protocol":  "2024-11-05",
+			"protocol":  constants.MCPProtocolVersion,
 		})
 	})
 
 	// Legacy SSE endpoint for backward compatibility
-	mux.HandleFunc("/sse", t.handleLegacySSE)
+	mux.Handle("/sse", t.protect(t.handleLegacySSE))
 
 	t.server = &http.Server{
 		Addr:    t.addr,
@@ -77,7 +139,7 @@ func (t *StreamableHTTPTransport) Start(ctx context.Context) error {
 	// Start server
 	go func() {
 		if err := t.server.ListenAndServe(); err != http.ErrServerClosed {
-			fmt.Printf("HTTP server error: %v\n", err)
+			logger.ErrorContext(ctx, "HTTP server error", "error", err)
 		}
 	}()
 
@@ -88,8 +150,8 @@ func (t *StreamableHTTPTransport) Start(ctx context.Context) error {
 // addSecurityHeaders adds security headers to all responses
 func (t *StreamableHTTPTransport) addSecurityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Security check for non-localhost connections
-		if !t.enableSecurity && !isLocalhost(r.RemoteAddr) && !isLocalhost(r.Host) {
+		// Security check for non-localhost connections; authenticated transports accept them
+		if !t.enableSecurity && t.auth == nil && !isLocalhost(r.RemoteAddr) && !isLocalhost(r.Host) {
 			http.Error(w, "Remote connections not allowed without --i-am-security-expert-i-know-what-i-am-doing flag", http.StatusForbidden)
 			return
 		}
@@ -101,8 +163,9 @@ func (t *StreamableHTTPTransport) addSecurityHeaders(next http.Handler) http.Han
 		// CORS headers for local development
 		if isLocalhost(r.Host) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Last-Event-ID")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Last-Event-ID, Authorization, X-API-Key, "+SessionHeader)
+			w.Header().Set("Access-Control-Expose-Headers", "WWW-Authenticate, "+SessionHeader)
 		}
 
 		if r.Method == "OPTIONS" {
@@ -116,68 +179,165 @@ func (t *StreamableHTTPTransport) addSecurityHeaders(next http.Handler) http.Han
 
 // handleMCP handles the main MCP endpoint with automatic SSE upgrade
 func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		t.handleDeleteSession(w, r)
+		return
+	case http.MethodGet:
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			http.Error(w, "GET requires Accept: text/event-stream", http.StatusNotAcceptable)
+			return
+		}
+		sess, ok := t.requireSession(w, r)
+		if !ok {
+			return
+		}
+		// A client that lost a stream resumes it with the ID of the last event it received
+		if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+			t.resumeStream(w, r, sess, lastEventID)
+		} else {
+			t.openStream(w, r, sess)
+		}
+		return
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Check if client wants SSE streaming
 	acceptSSE := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
-	lastEventID := r.Header.Get("Last-Event-ID")
 
-	// Parse the incoming message
-	var msg transport.Message
-	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+	// Parse the incoming message or batch
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
+	msgs, batch, err := transport.DecodeMessages(body)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, transport.DecodeErrorResponse(err))
+		return
+	}
+	if batch {
+		t.handleBatch(w, r, msgs)
+		return
+	}
+	msg := *msgs[0]
+
+	// initialize starts a session; every other message must name its session
+	var sess *httpSession
+	if msg.Method == "initialize" {
+		if sess = t.createSession(r); sess == nil {
+			http.Error(w, "Too many sessions", http.StatusServiceUnavailable)
+			return
+		}
+	} else {
+		var ok bool
+		if sess, ok = t.requireSession(w, r); !ok {
+			return
+		}
+	}
+
+	ctx := transport.WithSession(r.Context(), sess.id)
+
+	// Long-running requests answer on a stream that is opened before they run, so messages
+	// the server sends meanwhile reach the client and a lost connection can be resumed
+	if acceptSSE && streamsResponse(msg.Method) {
+		// The request outlives a dropped connection; its result waits in the event store
+		detached := context.WithoutCancel(ctx)
+		t.upgradeToSSE(w, r, sess.id, func(streamID string) *transport.Message {
+			return t.process(withRequestStream(detached, streamID), &msg)
+		})
+		return
+	}
 
 	// Process the message
-	ctx := r.Context()
-	response, err := t.handler(ctx, &msg)
-	if err != nil {
-		response = &transport.Message{
-			JSONRPC: "2.0",
-			ID:      msg.ID,
-			Error: &transport.Error{
-				Code:    -32603,
-				Message: err.Error(),
-			},
+	response := t.process(ctx, &msg)
+	if msg.Method == "initialize" {
+		if response == nil || response.Error != nil {
+			t.closeSession(sess.id)
+		} else {
+			w.Header().Set(SessionHeader, sess.id)
 		}
 	}
 
-	// Check if this is a method that might benefit from streaming
+	// Notifications and responses to server-initiated requests have no reply
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Check if this is a response that might benefit from streaming
 	needsStreaming := t.shouldUpgradeToStream(&msg, response)
 
 	if acceptSSE && needsStreaming {
 		// Upgrade to SSE for streaming responses
-		t.upgradeToSSE(w, r, response, lastEventID)
+		t.upgradeToSSE(w, r, sess.id, func(string) *transport.Message { return response })
 	} else {
 		// Regular JSON response
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
-			fmt.Printf("Error encoding response: %v\n", err)
+			logger.ErrorContext(ctx, "Error encoding response", "error", err)
 		}
 	}
 }
 
-// shouldUpgradeToStream determines if a request should be upgraded to SSE
-func (t *StreamableHTTPTransport) shouldUpgradeToStream(request, response *transport.Message) bool {
-	// Check if the response indicates streaming would be beneficial
-	// This could be based on response size, method type, or explicit flags
-	
-	// For now, check if it's a method that typically streams
+// handleBatch answers a JSON-RPC batch with a JSON array of the responses. A batch
+// belongs to an existing session, since initialize cannot be batched.
+func (t *StreamableHTTPTransport) handleBatch(w http.ResponseWriter, r *http.Request, msgs []*transport.Message) {
+	sess, ok := t.requireSession(w, r)
+	if !ok {
+		return
+	}
+	ctx := transport.WithSession(r.Context(), sess.id)
+
+	responses := transport.HandleBatch(ctx, t.handler, msgs)
+	if len(responses) == 0 {
+		// Only notifications and responses
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, responses)
+}
+
+// writeJSON writes a JSON response body
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.ErrorContext(r.Context(), "Error encoding response", "error", err)
+	}
+}
+
+// process passes a message to the handler, turning handler errors into error responses
+func (t *StreamableHTTPTransport) process(ctx context.Context, msg *transport.Message) *transport.Message {
+	return transport.Handle(ctx, t.handler, msg)
+}
+
+// streamsResponse reports whether a method typically runs long enough to answer on a stream
+func streamsResponse(method string) bool {
 	streamingMethods := []string{
 		"tools/call",
 		"resources/read",
 		"prompts/get",
 	}
 
-	for _, method := range streamingMethods {
-		if strings.Contains(request.Method, method) {
+	for _, m := range streamingMethods {
+		if strings.Contains(method, m) {
 			return true
 		}
 	}
+	return false
+}
+
+// shouldUpgradeToStream determines if a request should be upgraded to SSE
+func (t *StreamableHTTPTransport) shouldUpgradeToStream(request, response *transport.Message) bool {
+	// Check if the response indicates streaming would be beneficial
+	// This could be based on response size, method type, or explicit flags
+	if streamsResponse(request.Method) {
+		return true
+	}
 
 	// Check if response has pagination or continuation indicators
 	if response != nil && response.Result != nil {
@@ -195,26 +355,40 @@ func (t *StreamableHTTPTransport) shouldUpgradeToStream(request, response *trans
 	return false
 }
 
-// upgradeToSSE upgrades the connection to Server-Sent Events
-func (t *StreamableHTTPTransport) upgradeToSSE(w http.ResponseWriter, r *http.Request, initialResponse *transport.Message, lastEventID string) {
-	// Ensure we can flush
+// sseHeaders starts a Server-Sent Events response; it reports false when the
+// connection cannot stream
+func sseHeaders(w http.ResponseWriter) (http.Flusher, bool) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		// Fall back to regular response
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(initialResponse)
-		return
+		return nil, false
 	}
-
-	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no") // Disable Nginx buffering
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return flusher, true
+}
+
+// upgradeToSSE answers a request on a new SSE stream that ends with the response.
+// respond runs after the stream is open and gets its ID, so messages about the request can
+// be sent on it; if the client disconnects meanwhile, the stream's events stay in the
+// event store for a resume with Last-Event-ID.
+func (t *StreamableHTTPTransport) upgradeToSSE(w http.ResponseWriter, r *http.Request, sessionID string, respond func(streamID string) *transport.Message) {
+	// Ensure we can flush
+	flusher, ok := sseHeaders(w)
+	if !ok {
+		// Fall back to regular response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respond(""))
+		return
+	}
 
 	// Create stream context
 	stream := &streamContext{
-		id:       fmt.Sprintf("stream-%d", time.Now().UnixNano()),
+		id:       newStreamID(),
+		session:  sessionID,
 		writer:   w,
 		flusher:  flusher,
 		done:     make(chan struct{}),
@@ -226,27 +400,73 @@ func (t *StreamableHTTPTransport) upgradeToSSE(w http.ResponseWriter, r *http.Re
 	t.activeStreams[stream.id] = stream
 	t.mu.Unlock()
 
-	defer func() {
-		t.mu.Lock()
-		delete(t.activeStreams, stream.id)
-		t.mu.Unlock()
-		stream.closeOnce.Do(func() { close(stream.done) })
+	// An event ID without data lets the client resume even before the first message
+	t.sendEvent(stream, "", nil)
+
+	go func() {
+		defer t.finishStream(stream)
+		if response := respond(stream.id); response != nil {
+			t.sendSSEMessage(stream, "message", response)
+		}
 	}()
 
-	// Send initial response as first event
-	if initialResponse != nil {
-		t.sendSSEMessage(stream, "message", initialResponse)
+	t.serveStream(stream, w, r)
+}
+
+// openStream serves the standalone GET stream of a session, which carries the server's
+// notifications and requests outside of a request. A new GET stream replaces the
+// session's previous one; a disconnected one is kept for resuming until cleanupStreams
+// removes it.
+func (t *StreamableHTTPTransport) openStream(w http.ResponseWriter, r *http.Request, sess *httpSession) {
+	flusher, ok := sseHeaders(w)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
 	}
 
-	// Handle resume from last event if provided
-	if lastEventID != "" {
-		// In a real implementation, you'd replay missed events here
-		t.sendSSEMessage(stream, "resume", map[string]string{
-			"last_event_id": lastEventID,
-			"status":        "resumed",
-		})
+	stream := &streamContext{
+		id:       newStreamID(),
+		session:  sess.id,
+		writer:   w,
+		flusher:  flusher,
+		get:      true,
+		done:     make(chan struct{}),
+		lastSeen: time.Now(),
 	}
 
+	t.mu.Lock()
+	for id, old := range t.activeStreams {
+		if old.session == sess.id && old.get {
+			old.closeOnce.Do(func() { close(old.done) })
+			delete(t.activeStreams, id)
+		}
+	}
+	t.activeStreams[stream.id] = stream
+	t.mu.Unlock()
+
+	t.sendEvent(stream, "", nil)
+	t.serveStream(stream, w, r)
+}
+
+// finishStream deregisters a stream that sent its response
+func (t *StreamableHTTPTransport) finishStream(stream *streamContext) {
+	t.mu.Lock()
+	delete(t.activeStreams, stream.id)
+	t.mu.Unlock()
+	stream.closeOnce.Do(func() { close(stream.done) })
+}
+
+// serveStream keeps a client connection to a stream alive until the stream is done or the
+// client disconnects. The stream is detached from the connection when it ends.
+func (t *StreamableHTTPTransport) serveStream(stream *streamContext, w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		stream.writeMu.Lock()
+		if stream.writer == w {
+			stream.writer, stream.flusher = nil, nil
+		}
+		stream.writeMu.Unlock()
+	}()
+
 	// Keep connection alive with periodic pings
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -254,12 +474,14 @@ func (t *StreamableHTTPTransport) upgradeToSSE(w http.ResponseWriter, r *http.Re
 	for {
 		select {
 		case <-ticker.C:
-			// Send ping to keep connection alive (guard writes)
+			// Send ping to keep connection alive (guard writes); a resumed stream pings on its new connection
 			stream.writeMu.Lock()
-			_, err := fmt.Fprintf(w, ":ping\n\n")
-			if err == nil {
-				flusher.Flush()
-				stream.lastSeen = time.Now()
+			var err error
+			if stream.writer == w {
+				if _, err = fmt.Fprintf(w, ":ping\n\n"); err == nil {
+					stream.flusher.Flush()
+					stream.lastSeen = time.Now()
+				}
 			}
 			stream.writeMu.Unlock()
 			if err != nil {
@@ -275,24 +497,97 @@ func (t *StreamableHTTPTransport) upgradeToSSE(w http.ResponseWriter, r *http.Re
 	}
 }
 
-// sendSSEMessage sends a message in SSE format
+// resumeStream replays the events of a stream after Last-Event-ID and, while the stream's
+// request is still running, continues the stream on this connection
+func (t *StreamableHTTPTransport) resumeStream(w http.ResponseWriter, r *http.Request, sess *httpSession, lastEventID string) {
+	streamID, seq, ok := parseEventID(lastEventID)
+	if !ok {
+		http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	t.mu.RLock()
+	stream := t.activeStreams[streamID]
+	t.mu.RUnlock()
+	if stream != nil && stream.session != sess.id {
+		stream = nil
+	}
+
+	flusher, ok := sseHeaders(w)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Replay and attach under the stream's write lock, so no event is missed or sent twice
+	if stream != nil {
+		stream.writeMu.Lock()
+	}
+	events, err := t.events.After(sess.id, streamID, seq)
+	for _, ev := range events {
+		if err = writeEvent(w, ev); err != nil {
+			break
+		}
+	}
+	flusher.Flush()
+	if stream == nil {
+		return
+	}
+	if err == nil {
+		stream.writer, stream.flusher = w, flusher
+		stream.lastSeen = time.Now()
+	}
+	stream.writeMu.Unlock()
+	if err == nil {
+		t.serveStream(stream, w, r)
+	}
+}
+
+// writeEvent writes one event in SSE format
+func writeEvent(w io.Writer, ev storedEvent) error {
+	var err error
+	if ev.Event == "" {
+		_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", ev.ID, ev.Data)
+	} else {
+		_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Event, ev.Data)
+	}
+	return err
+}
+
+// sendSSEMessage records a message in the event store and sends it, if the client is connected
 func (t *StreamableHTTPTransport) sendSSEMessage(stream *streamContext, eventType string, data interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
+	return t.sendEvent(stream, eventType, jsonData)
+}
 
-	// Generate event ID
-	eventID := fmt.Sprintf("%s-%d", stream.id, time.Now().UnixNano())
-
-	// Guard writes to ResponseWriter (not goroutine-safe)
+// sendEvent assigns the next event ID of a stream to an event, stores and sends it
+func (t *StreamableHTTPTransport) sendEvent(stream *streamContext, eventType string, data []byte) error {
+	// Guard writes to ResponseWriter (not goroutine-safe) and the event sequence
 	stream.writeMu.Lock()
 	defer stream.writeMu.Unlock()
 
-	// Send SSE formatted message
-	_, err = fmt.Fprintf(stream.writer, "id: %s\nevent: %s\ndata: %s\n\n",
-		eventID, eventType, jsonData)
-	if err != nil {
+	stream.seq++
+	ev := storedEvent{
+		ID:     eventID(stream.id, stream.seq),
+		Stream: stream.id,
+		Seq:    stream.seq,
+		Event:  eventType,
+		Data:   data,
+		Time:   time.Now(),
+	}
+	if err := t.events.Append(stream.session, ev); err != nil {
+		logger.ErrorContext(transport.WithSession(context.Background(), stream.session), "Error storing SSE event", "stream", stream.id, "error", err)
+	}
+
+	// A disconnected client gets the event when it resumes with Last-Event-ID
+	if stream.writer == nil {
+		return nil
+	}
+	if err := writeEvent(stream.writer, ev); err != nil {
+		stream.writer, stream.flusher = nil, nil
 		return err
 	}
 
@@ -314,7 +609,7 @@ func (t *StreamableHTTPTransport) handleLegacySSE(w http.ResponseWriter, r *http
 	t.handleMCP(w, r)
 }
 
-// cleanupStreams removes stale stream contexts
+// cleanupStreams removes stale stream contexts and expires idle sessions
 func (t *StreamableHTTPTransport) cleanupStreams(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -324,31 +619,120 @@ func (t *StreamableHTTPTransport) cleanupStreams(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			t.mu.Lock()
 			now := time.Now()
-			for id, stream := range t.activeStreams {
-				if now.Sub(stream.lastSeen) > 5*time.Minute {
-					stream.closeOnce.Do(func() { close(stream.done) })
-					delete(t.activeStreams, id)
-				}
-			}
-			t.mu.Unlock()
+			t.removeStaleStreams(now)
+			t.expireSessions(now)
 		}
 	}
 }
 
-// BroadcastMessage sends a message to all active SSE streams
+// removeStaleStreams ends streams that had no successful write for five minutes, such as
+// GET streams whose client went away without resuming
+func (t *StreamableHTTPTransport) removeStaleStreams(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, stream := range t.activeStreams {
+		stream.writeMu.Lock()
+		stale := now.Sub(stream.lastSeen) > 5*time.Minute
+		stream.writeMu.Unlock()
+		if stale {
+			stream.closeOnce.Do(func() { close(stream.done) })
+			delete(t.activeStreams, id)
+		}
+	}
+}
+
+// BroadcastMessage sends a message to the client of every session with an open stream
 func (t *StreamableHTTPTransport) BroadcastMessage(msg *transport.Message) error {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	for _, stream := range t.activeStreams {
-		go t.sendSSEMessage(stream, "broadcast", msg)
+	for id := range t.sessions {
+		if stream := t.sessionStreamLocked(id, ""); stream != nil {
+			go t.sendSSEMessage(stream, "message", msg)
+		}
 	}
 
 	return nil
 }
 
+// WriteMessageFor sends a message to the client of the request handled in ctx: on the
+// request's own stream, else on the session's GET stream or latest request stream
+func (t *StreamableHTTPTransport) WriteMessageFor(ctx context.Context, msg *transport.Message) error {
+	session := transport.SessionFromContext(ctx)
+	if session == "" {
+		return t.BroadcastMessage(msg)
+	}
+
+	t.mu.RLock()
+	stream := t.sessionStreamLocked(session, requestStream(ctx))
+	t.mu.RUnlock()
+	if stream == nil {
+		return fmt.Errorf("no open stream for session %s", session)
+	}
+	return t.sendSSEMessage(stream, "message", msg)
+}
+
+// CanSendRequestsFor reports whether the client of the request handled in ctx has a stream
+// that can carry server-initiated requests
+func (t *StreamableHTTPTransport) CanSendRequestsFor(ctx context.Context) bool {
+	session := transport.SessionFromContext(ctx)
+	if session == "" {
+		return t.CanSendRequests()
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.sessionStreamLocked(session, requestStream(ctx)) != nil
+}
+
+type streamKey struct{}
+
+// withRequestStream marks a request context with the stream that answers the request
+func withRequestStream(ctx context.Context, streamID string) context.Context {
+	return context.WithValue(ctx, streamKey{}, streamID)
+}
+
+// requestStream returns the stream answering the request handled in ctx, or ""
+func requestStream(ctx context.Context) string {
+	id, _ := ctx.Value(streamKey{}).(string)
+	return id
+}
+
+// sessionStreamLocked picks the stream for server messages to a session, preferring the
+// stream of the request the message belongs to, then the GET stream, then the request
+// stream that was written to last. Callers hold t.mu.
+func (t *StreamableHTTPTransport) sessionStreamLocked(session, preferred string) *streamContext {
+	if stream, ok := t.activeStreams[preferred]; ok && stream.session == session {
+		return stream
+	}
+
+	var latest *streamContext
+	var latestSeen time.Time
+	for _, stream := range t.activeStreams {
+		if stream.session != session {
+			continue
+		}
+		if stream.get {
+			return stream
+		}
+		stream.writeMu.Lock()
+		seen := stream.lastSeen
+		stream.writeMu.Unlock()
+		if latest == nil || seen.After(latestSeen) {
+			latest, latestSeen = stream, seen
+		}
+	}
+	return latest
+}
+
+// CanSendRequests reports whether an open SSE stream can receive server-initiated requests
+func (t *StreamableHTTPTransport) CanSendRequests() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.activeStreams) > 0
+}
+
 // ReadMessage reads a message from stdin (for stdio compatibility during testing)
 func (t *StreamableHTTPTransport) ReadMessage() (*transport.Message, error) {
 	// For HTTP transport, we don't read from stdin
@@ -0,0 +1,369 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/auth"
+	"github.com/zmcp/odata-mcp/internal/mcp"
+	"github.com/zmcp/odata-mcp/internal/progress"
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+// newTestStreamable returns a streamable transport in front of an MCP server
+func newTestStreamable(t *testing.T) (*StreamableHTTPTransport, *mcp.Server) {
+	t.Helper()
+	server := mcp.NewServer("test", "1.0")
+	trans := NewStreamableHTTP("localhost:0", server.HandleMessage, false)
+	trans.OnSessionClosed(server.CloseSession)
+	return trans, server
+}
+
+// post sends a JSON-RPC message to /mcp with an optional session ID
+func post(trans *StreamableHTTPTransport, sessionID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	rec := httptest.NewRecorder()
+	trans.handleMCP(rec, req)
+	return rec
+}
+
+func initialize(t *testing.T, trans *StreamableHTTPTransport, client string) string {
+	t.Helper()
+	rec := post(trans, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"clientInfo":{"name":"`+client+`"}}}`)
+	id := rec.Header().Get(SessionHeader)
+	if rec.Code != http.StatusOK || id == "" {
+		t.Fatalf("initialize: status %d, session %q", rec.Code, id)
+	}
+	return id
+}
+
+func TestStreamableSessions(t *testing.T) {
+	trans, server := newTestStreamable(t)
+	var closed []string
+	trans.OnSessionClosed(func(id string) {
+		closed = append(closed, id)
+		server.CloseSession(id)
+	})
+
+	if rec := post(trans, "", `{"jsonrpc":"2.0","id":1,"method":"ping"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("request without session: status %d, want 400", rec.Code)
+	}
+
+	a := initialize(t, trans, "client-a")
+	b := initialize(t, trans, "client-b")
+	if a == b {
+		t.Fatal("both clients got the same session")
+	}
+	infoOf := func(id string) interface{} {
+		return server.ClientInfo(transport.WithSession(context.Background(), id))["name"]
+	}
+	if infoOf(a) != "client-a" || infoOf(b) != "client-b" {
+		t.Errorf("client info per session = %v, %v", infoOf(a), infoOf(b))
+	}
+
+	if rec := post(trans, a, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); rec.Code != http.StatusOK {
+		t.Errorf("ping in session: status %d", rec.Code)
+	}
+	if rec := post(trans, "unknown", `{"jsonrpc":"2.0","id":2,"method":"ping"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown session: status %d, want 404", rec.Code)
+	}
+
+	del := httptest.NewRequest(http.MethodDelete, "http://localhost/mcp", nil)
+	del.Header.Set(SessionHeader, a)
+	rec := httptest.NewRecorder()
+	trans.handleMCP(rec, del)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status %d, want 204", rec.Code)
+	}
+	if rec := post(trans, a, `{"jsonrpc":"2.0","id":3,"method":"ping"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("deleted session: status %d, want 404", rec.Code)
+	}
+	if infoOf(a) != nil {
+		t.Error("server kept the state of the deleted session")
+	}
+
+	trans.expireSessions(time.Now().Add(DefaultSessionTimeout + time.Minute))
+	if rec := post(trans, b, `{"jsonrpc":"2.0","id":4,"method":"ping"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expired session: status %d, want 404", rec.Code)
+	}
+	if len(closed) != 2 || closed[0] != a || closed[1] != b {
+		t.Errorf("closed sessions = %v", closed)
+	}
+}
+
+func TestStreamableSessionOwner(t *testing.T) {
+	trans, _ := newTestStreamable(t)
+	as := func(subject string, req *http.Request) *http.Request {
+		return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Method: auth.MethodOAuth}))
+	}
+
+	req := as("alice", httptest.NewRequest(http.MethodPost, "http://localhost/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)))
+	rec := httptest.NewRecorder()
+	trans.handleMCP(rec, req)
+	id := rec.Header().Get(SessionHeader)
+
+	for subject, want := range map[string]int{"alice": http.StatusOK, "mallory": http.StatusNotFound} {
+		req := as(subject, httptest.NewRequest(http.MethodPost, "http://localhost/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)))
+		req.Header.Set(SessionHeader, id)
+		rec := httptest.NewRecorder()
+		trans.handleMCP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s using alice's session: status %d, want %d", subject, rec.Code, want)
+		}
+	}
+}
+
+// readEvent reads the next SSE event of a stream
+func TestStreamableBatch(t *testing.T) {
+	trans, _ := newTestStreamable(t)
+	id := initialize(t, trans, "client")
+
+	rec := post(trans, id, `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"method":"initialize","params":{}},{"jsonrpc":"2.0","id":3,"method":"tools/list"}]`)
+	var responses []transport.Message
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &responses) != nil {
+		t.Fatalf("batch: status %d, body %s", rec.Code, rec.Body)
+	}
+	if len(responses) != 3 || string(responses[0].ID) != "1" || string(responses[1].ID) != "2" || string(responses[2].ID) != "3" {
+		t.Fatalf("unexpected batch response: %s", rec.Body)
+	}
+	if responses[0].Error != nil || responses[2].Error != nil {
+		t.Errorf("batched requests failed: %s", rec.Body)
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != transport.CodeInvalidRequest {
+		t.Errorf("batched initialize was not rejected: %s", rec.Body)
+	}
+
+	if rec := post(trans, id, `[{"jsonrpc":"2.0","method":"notifications/progress","params":{}}]`); rec.Code != http.StatusAccepted {
+		t.Errorf("notification batch: status %d, want 202", rec.Code)
+	}
+	for body, code := range map[string]int{`[]`: transport.CodeInvalidRequest, `{"jsonrpc"`: transport.CodeParseError} {
+		rec := post(trans, id, body)
+		var resp transport.Message
+		if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Error == nil || resp.Error.Code != code || string(resp.ID) != "null" {
+			t.Errorf("%s: status %d, body %s", body, rec.Code, rec.Body)
+		}
+	}
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) (id, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && id != "":
+			return id, data
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamableResume(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		release := make(chan struct{})
+		trans := NewStreamableHTTP("localhost:0", func(ctx context.Context, msg *transport.Message) (*transport.Message, error) {
+			if msg.Method == "tools/call" {
+				<-release
+			}
+			return &transport.Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`{"done":true}`)}, nil
+		}, false)
+		if err := trans.SetEventStore(dir, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(http.HandlerFunc(trans.handleMCP))
+		sessionID := initialize(t, trans, "client")
+
+		// Start a tool call and drop the connection after the stream opened
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{}}`))
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set(SessionHeader, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastID, _ := readEvent(t, bufio.NewReader(resp.Body))
+		cancel()
+		resp.Body.Close()
+
+		// Resume while the call is still running, then let it finish
+		req, _ = http.NewRequest(http.MethodGet, server.URL+"/mcp", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(SessionHeader, sessionID)
+		req.Header.Set("Last-Event-ID", lastID)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		close(release)
+		id, data := readEvent(t, bufio.NewReader(resp.Body))
+		resp.Body.Close()
+		if !strings.Contains(data, `"done":true`) || id == lastID {
+			t.Errorf("store %q: resumed event %s = %s", dir, id, data)
+		}
+
+		// The finished stream can still be replayed from the event store
+		req, _ = http.NewRequest(http.MethodGet, server.URL+"/mcp", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(SessionHeader, sessionID)
+		req.Header.Set("Last-Event-ID", lastID)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, data := readEvent(t, bufio.NewReader(resp.Body)); !strings.Contains(data, `"done":true`) {
+			t.Errorf("store %q: replayed event = %s", dir, data)
+		}
+		resp.Body.Close()
+		server.Close()
+	}
+}
+
+func TestStreamableGetStream(t *testing.T) {
+	trans, server := newTestStreamable(t)
+	server.SetTransport(trans)
+	httpServer := httptest.NewServer(http.HandlerFunc(trans.handleMCP))
+	defer httpServer.Close()
+
+	a := initialize(t, trans, "client-a")
+	b := initialize(t, trans, "client-b")
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/mcp", nil)
+	req.Header.Set(SessionHeader, a)
+	rec := httptest.NewRecorder()
+	trans.handleMCP(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("GET without Accept: status %d, want 406", rec.Code)
+	}
+
+	open := func(sessionID string) (*bufio.Reader, func()) {
+		req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/mcp", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(SessionHeader, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := bufio.NewReader(resp.Body)
+		readEvent(t, r) // Event ID without data
+		return r, func() { resp.Body.Close() }
+	}
+	streamA, closeA := open(a)
+	streamB, closeB := open(b)
+	defer closeB()
+
+	if err := server.SendNotification(transport.WithSession(context.Background(), a), "notifications/message", map[string]string{"data": "for a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SendNotification(context.Background(), "notifications/tools/list_changed", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, data := readEvent(t, streamA); !strings.Contains(data, "for a") {
+		t.Errorf("session a got %s", data)
+	}
+	if _, data := readEvent(t, streamB); !strings.Contains(data, "list_changed") {
+		t.Errorf("session b got %s, want only the broadcast", data)
+	}
+
+	// A GET stream whose client went away is removed by the cleanup
+	closeA()
+	ctxA := transport.WithSession(context.Background(), a)
+	if !trans.CanSendRequestsFor(ctxA) {
+		t.Fatal("disconnected GET stream removed before it went stale")
+	}
+	trans.removeStaleStreams(time.Now().Add(6 * time.Minute))
+	if trans.CanSendRequestsFor(ctxA) {
+		t.Error("stale GET stream was not removed")
+	}
+}
+
+func TestStreamableProgress(t *testing.T) {
+	trans, server := newTestStreamable(t)
+	server.SetTransport(trans)
+	server.AddTool(&mcp.Tool{Name: "slow"}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		progress.Report(ctx, 50, 100, "half way")
+		return "done", nil
+	})
+	httpServer := httptest.NewServer(http.HandlerFunc(trans.handleMCP))
+	defer httpServer.Close()
+
+	sessionID := initialize(t, trans, "client")
+	// A GET stream is open too; progress still belongs on the request's stream
+	get, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/mcp", nil)
+	get.Header.Set("Accept", "text/event-stream")
+	get.Header.Set(SessionHeader, sessionID)
+	getResp, err := http.DefaultClient.Do(get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer getResp.Body.Close()
+	readEvent(t, bufio.NewReader(getResp.Body))
+
+	req, _ := http.NewRequest(http.MethodPost, httpServer.URL+"/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"progressToken":"tok"}}}`))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set(SessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	readEvent(t, events) // Event ID without data
+
+	if _, data := readEvent(t, events); !strings.Contains(data, `"notifications/progress"`) ||
+		!strings.Contains(data, `"progressToken":"tok"`) || !strings.Contains(data, `"half way"`) {
+		t.Errorf("progress event = %s", data)
+	}
+	if _, data := readEvent(t, events); !strings.Contains(data, `"id":5`) {
+		t.Errorf("response event = %s", data)
+	}
+}
+
+func TestStreamableStreamSelection(t *testing.T) {
+	trans, _ := newTestStreamable(t)
+	now := time.Now()
+	older := &streamContext{id: newStreamID(), session: "s1", lastSeen: now}
+	newer := &streamContext{id: newStreamID(), session: "s1", lastSeen: now.Add(time.Second)}
+	if older.id == newer.id || !strings.HasPrefix(older.id, "stream-") {
+		t.Fatalf("stream IDs must be unique: %s, %s", older.id, newer.id)
+	}
+	if _, _, ok := parseEventID(eventID(older.id, 3)); !ok {
+		t.Errorf("event IDs of stream %s do not parse", older.id)
+	}
+	trans.activeStreams[older.id] = older
+	trans.activeStreams[newer.id] = newer
+	trans.activeStreams["other"] = &streamContext{id: "other", session: "s2", lastSeen: now.Add(time.Hour)}
+
+	// The request stream used last wins, whatever the order of the IDs
+	if got := trans.sessionStreamLocked("s1", ""); got != newer {
+		t.Errorf("picked %s, want the most recently used stream %s", got.id, newer.id)
+	}
+	older.lastSeen = now.Add(2 * time.Second)
+	if got := trans.sessionStreamLocked("s1", ""); got != older {
+		t.Errorf("picked %s, want the most recently used stream %s", got.id, older.id)
+	}
+	if got := trans.sessionStreamLocked("s1", newer.id); got != newer {
+		t.Errorf("the request's own stream must be preferred, got %s", got.id)
+	}
+	if got := trans.sessionStreamLocked("s3", ""); got != nil {
+		t.Errorf("unknown session got stream %s", got.id)
+	}
+}
@@ -2,30 +2,49 @@ package stdio
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/zmcp/odata-mcp/internal/debug"
 	"github.com/zmcp/odata-mcp/internal/transport"
 )
 
+// DefaultWorkers is the number of requests handled concurrently by default
+const DefaultWorkers = 4
+
 // StdioTransport implements the Transport interface for stdio communication
 type StdioTransport struct {
 	reader  *bufio.Reader
 	writer  io.Writer
 	handler transport.Handler
 	tracer  *debug.TraceLogger
+	writeMu sync.Mutex // Serializes writes from responses and server-initiated messages
+	workers int        // Maximum number of requests handled concurrently
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightRequest // By request ID (raw JSON)
+}
+
+// inFlightRequest is a request that was read and has not been answered yet
+type inFlightRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool // Cancelled by the client; no response is sent
 }
 
 // New creates a new stdio transport
 func New(handler transport.Handler) *StdioTransport {
 	return &StdioTransport{
-		reader:  bufio.NewReader(os.Stdin),
-		writer:  os.Stdout,
-		handler: handler,
+		reader:   bufio.NewReader(os.Stdin),
+		writer:   os.Stdout,
+		handler:  handler,
+		workers:  DefaultWorkers,
+		inFlight: make(map[string]*inFlightRequest),
 	}
 }
 
@@ -34,60 +53,225 @@ func (t *StdioTransport) SetTracer(tracer *debug.TraceLogger) {
 	t.tracer = tracer
 }
 
-// Start begins processing messages from stdio
+// SetWorkers sets how many requests are handled concurrently
+func (t *StdioTransport) SetWorkers(n int) {
+	if n > 0 {
+		t.workers = n
+	}
+}
+
+// Start begins processing messages from stdio. Requests are handled concurrently, up to
+// the worker limit; notifications and responses are handled in the order they arrive.
+// A line may also hold a JSON-RPC batch, which is answered with one line.
 func (t *StdioTransport) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	readDone := make(chan struct{})
+	go t.readLoop(ctx, &wg, readDone)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-readDone:
+		// Answer the requests still running before stdin's EOF ends the server
+		wg.Wait()
+		return nil
+	}
+}
+
+// readLoop reads messages until EOF and dispatches requests to workers
+func (t *StdioTransport) readLoop(ctx context.Context, wg *sync.WaitGroup, done chan<- struct{}) {
+	defer close(done)
+	slots := make(chan struct{}, t.workers)
+
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			msg, err := t.ReadMessage()
-			if err != nil {
-				if err == io.EOF {
-					return nil
-				}
-				// Continue processing without logging to avoid stderr interference
+		msgs, batch, err := t.readMessages()
+		if err != nil {
+			if err == io.EOF {
+				return
+			}
+			// Lines that are not JSON-RPC are answered with an error for a null ID
+			var decodeErr *decodeError
+			if errors.As(err, &decodeErr) {
+				t.WriteMessage(transport.DecodeErrorResponse(decodeErr.err))
+			}
+			continue
+		}
+
+		if t.handler == nil {
+			continue
+		}
+
+		if batch {
+			wg.Add(1)
+			go func(msgs []*transport.Message) {
+				defer wg.Done()
+				t.serveBatch(ctx, msgs, slots)
+			}(msgs)
+			continue
+		}
+		msg := msgs[0]
+
+		// Notifications and responses to server-initiated requests (e.g. elicitation) are
+		// handled right away, so they reach requests that are waiting for them
+		if len(msg.ID) == 0 || msg.Method == "" {
+			if msg.Method == "notifications/cancelled" {
+				t.cancelRequest(msg)
 				continue
 			}
+			t.handler(ctx, msg)
+			continue
+		}
+
+		// Requests wait for a free worker without holding up the reader
+		reqCtx, req := t.track(ctx, msg)
+		wg.Add(1)
+		go func(msg *transport.Message) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-reqCtx.Done():
+			}
+			t.serve(reqCtx, req, msg)
+		}(msg)
+	}
+}
+
+// track registers a request in the in-flight table and returns its context
+func (t *StdioTransport) track(ctx context.Context, msg *transport.Message) (context.Context, *inFlightRequest) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	req := &inFlightRequest{cancel: cancel}
+	t.mu.Lock()
+	t.inFlight[string(msg.ID)] = req
+	t.mu.Unlock()
+	return reqCtx, req
+}
+
+// serve handles a request and writes its response, unless the client cancelled it
+func (t *StdioTransport) serve(ctx context.Context, req *inFlightRequest, msg *transport.Message) {
+	if response := t.respond(ctx, req, msg); response != nil {
+		if err := t.WriteMessage(response); err != nil {
+			// Silently continue to avoid stderr interference
+		}
+	}
+}
 
-			// Process request if it has a method
-			if msg.Method != "" && t.handler != nil {
-				response, err := t.handler(ctx, msg)
-				if err != nil {
-					// Ensure ID is not null for error responses
-					msgID := msg.ID
-					if msgID == nil || string(msgID) == "null" {
-						msgID = json.RawMessage("0")
-					}
-
-					// Send error response
-					errorResponse := &transport.Message{
-						JSONRPC: "2.0",
-						ID:      msgID,
-						Error: &transport.Error{
-							Code:    -32603,
-							Message: err.Error(),
-						},
-					}
-					if err := t.WriteMessage(errorResponse); err != nil {
-						// Silently continue to avoid stderr interference
-					}
-				} else if response != nil {
-					if err := t.WriteMessage(response); err != nil {
-						// Silently continue to avoid stderr interference
-					}
-				}
+// serveBatch handles the messages of a batch like single ones, each request on a worker,
+// and writes their responses as one array
+func (t *StdioTransport) serveBatch(ctx context.Context, msgs []*transport.Message, slots chan struct{}) {
+	handler := func(ctx context.Context, msg *transport.Message) (*transport.Message, error) {
+		if len(msg.ID) == 0 || msg.Method == "" {
+			if msg.Method == "notifications/cancelled" {
+				t.cancelRequest(msg)
+				return nil, nil
 			}
+			return t.handler(ctx, msg)
+		}
+		reqCtx, req := t.track(ctx, msg)
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-reqCtx.Done():
+		}
+		return t.respond(reqCtx, req, msg), nil
+	}
+
+	if responses := transport.HandleBatch(ctx, handler, msgs); len(responses) > 0 {
+		if err := t.writeBatch(responses); err != nil {
+			// Silently continue to avoid stderr interference
+		}
+	}
+}
+
+// respond handles a request and returns its response, or nil if the client cancelled it
+func (t *StdioTransport) respond(ctx context.Context, req *inFlightRequest, msg *transport.Message) *transport.Message {
+	var response *transport.Message
+	var err error
+	if ctx.Err() == nil {
+		response, err = t.handler(ctx, msg)
+	}
+
+	t.mu.Lock()
+	if t.inFlight[string(msg.ID)] == req {
+		delete(t.inFlight, string(msg.ID))
+	}
+	cancelled := req.cancelled
+	t.mu.Unlock()
+	req.cancel()
+	if cancelled {
+		return nil
+	}
+
+	if err != nil {
+		return transport.ErrorResponse(msg.ID, transport.CodeInternalError, err.Error())
+	}
+	return response
+}
+
+// cancelRequest handles notifications/cancelled by cancelling the context of the named
+// request, which aborts its OData calls
+func (t *StdioTransport) cancelRequest(msg *transport.Message) {
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+		Reason    string          `json:"reason"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil || len(params.RequestID) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	req, ok := t.inFlight[string(params.RequestID)]
+	if ok {
+		req.cancelled = true
+	}
+	t.mu.Unlock()
+
+	if ok {
+		req.cancel()
+		if t.tracer != nil {
+			t.tracer.Log("TRANSPORT_CANCEL", "Request cancelled by client", map[string]interface{}{
+				"id":     params.RequestID,
+				"reason": params.Reason,
+			})
 		}
 	}
 }
 
+// decodeError is a line that is not a JSON-RPC message or batch
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("failed to unmarshal message: %v", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
 // ReadMessage reads a line-delimited JSON message from stdin
 func (t *StdioTransport) ReadMessage() (*transport.Message, error) {
-	line, err := t.reader.ReadBytes('\n')
+	msgs, batch, err := t.readMessages()
 	if err != nil {
 		return nil, err
 	}
+	if batch {
+		return nil, &decodeError{errors.New("batch where a single message was expected")}
+	}
+	return msgs[0], nil
+}
+
+// readMessages reads a line from stdin that holds one message or a batch; blank lines are
+// skipped
+func (t *StdioTransport) readMessages() ([]*transport.Message, bool, error) {
+	var line []byte
+	for len(bytes.TrimSpace(line)) == 0 {
+		var err error
+		if line, err = t.reader.ReadBytes('\n'); err != nil {
+			return nil, false, err
+		}
+	}
 
 	// Trace raw input
 	if t.tracer != nil {
@@ -97,27 +281,30 @@ func (t *StdioTransport) ReadMessage() (*transport.Message, error) {
 		})
 	}
 
-	var msg transport.Message
-	if err := json.Unmarshal(line, &msg); err != nil {
+	msgs, batch, err := transport.DecodeMessages(line)
+	if err != nil {
 		if t.tracer != nil {
 			t.tracer.LogError("Failed to unmarshal message", err, map[string]interface{}{
 				"raw": string(line),
 			})
 		}
-		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		return nil, batch, &decodeError{err}
 	}
 
-	// Trace parsed message
+	// Trace parsed messages
 	if t.tracer != nil {
-		t.tracer.Log("TRANSPORT_PARSED", "Message parsed", map[string]interface{}{
-			"method":     msg.Method,
-			"id":         msg.ID,
-			"jsonrpc":    msg.JSONRPC,
-			"has_params": len(msg.Params) > 0,
-		})
+		for _, msg := range msgs {
+			t.tracer.Log("TRANSPORT_PARSED", "Message parsed", map[string]interface{}{
+				"method":     msg.Method,
+				"id":         msg.ID,
+				"jsonrpc":    msg.JSONRPC,
+				"has_params": len(msg.Params) > 0,
+				"batch":      batch,
+			})
+		}
 	}
 
-	return &msg, nil
+	return msgs, batch, nil
 }
 
 // WriteMessage writes a JSON message to stdout
@@ -131,11 +318,25 @@ func (t *StdioTransport) WriteMessage(msg *transport.Message) error {
 			"method":     msg.Method,
 		})
 	}
+	return t.writeJSON(msg)
+}
+
+// writeBatch writes the responses to a batch as one JSON array
+func (t *StdioTransport) writeBatch(msgs []*transport.Message) error {
+	if t.tracer != nil {
+		t.tracer.Log("TRANSPORT_OUT", "Sending batch", map[string]interface{}{
+			"responses": len(msgs),
+		})
+	}
+	return t.writeJSON(msgs)
+}
 
-	data, err := json.Marshal(msg)
+// writeJSON writes a message or batch as one line to stdout
+func (t *StdioTransport) writeJSON(v interface{}) error {
+	data, err := json.Marshal(v)
 	if err != nil {
 		if t.tracer != nil {
-			t.tracer.LogError("Failed to marshal message", err, msg)
+			t.tracer.LogError("Failed to marshal message", err, v)
 		}
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
@@ -148,17 +349,21 @@ func (t *StdioTransport) WriteMessage(msg *transport.Message) error {
 		})
 	}
 
-	if _, err := t.writer.Write(data); err != nil {
-		return err
-	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
 
-	if _, err := t.writer.Write([]byte("\n")); err != nil {
+	if _, err := t.writer.Write(append(data, '\n')); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// CanSendRequests reports that stdio can deliver server-initiated requests at any time
+func (t *StdioTransport) CanSendRequests() bool {
+	return true
+}
+
 // Close closes the transport (no-op for stdio)
 func (t *StdioTransport) Close() error {
 	return nil
@@ -0,0 +1,129 @@
+package stdio
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/zmcp/odata-mcp/internal/transport"
+)
+
+func TestStdioConcurrentRequests(t *testing.T) {
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	handler := func(ctx context.Context, msg *transport.Message) (*transport.Message, error) {
+		if msg.Method == "slow" {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}
+		return &transport.Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`{}`)}, nil
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	trans := New(handler)
+	trans.reader = bufio.NewReader(inR)
+	trans.writer = outW
+	trans.SetWorkers(2)
+
+	done := make(chan error, 1)
+	go func() { done <- trans.Start(context.Background()) }()
+	out := bufio.NewScanner(outR)
+
+	// The slow request must not block the ping behind it
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"slow"}`+"\n")
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n")
+	if !out.Scan() {
+		t.Fatal("no response")
+	}
+	var resp transport.Message
+	json.Unmarshal(out.Bytes(), &resp)
+	if string(resp.ID) != "2" {
+		t.Errorf("first response is for %s, want the ping", resp.ID)
+	}
+
+	<-started
+	io.WriteString(inW, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"user"}}`+"\n")
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancellation did not reach the request")
+	}
+
+	// The cancelled request gets no response; the next one does
+	io.WriteString(inW, `{"jsonrpc":"2.0","id":3,"method":"ping"}`+"\n")
+	if !out.Scan() {
+		t.Fatal("no response")
+	}
+	json.Unmarshal(out.Bytes(), &resp)
+	if string(resp.ID) != "3" {
+		t.Errorf("got response for %s, want 3", resp.ID)
+	}
+
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Start = %v", err)
+	}
+}
+
+func TestStdioBatch(t *testing.T) {
+	handler := func(ctx context.Context, msg *transport.Message) (*transport.Message, error) {
+		if msg.JSONRPC != "2.0" {
+			return transport.ErrorResponse(msg.ID, transport.CodeInvalidRequest, "Invalid Request"), nil
+		}
+		if len(msg.ID) == 0 {
+			return nil, nil
+		}
+		return &transport.Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`{}`)}, nil
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	trans := New(handler)
+	trans.reader = bufio.NewReader(inR)
+	trans.writer = outW
+
+	done := make(chan error, 1)
+	go func() { done <- trans.Start(context.Background()) }()
+	out := bufio.NewScanner(outR)
+
+	// Responses come back as one array in batch order, without the notification
+	io.WriteString(inW, `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/progress"},1,{"jsonrpc":"2.0","id":"b","method":"ping"}]`+"\n")
+	if !out.Scan() {
+		t.Fatal("no response")
+	}
+	var responses []transport.Message
+	if err := json.Unmarshal(out.Bytes(), &responses); err != nil {
+		t.Fatalf("response is not an array: %s", out.Bytes())
+	}
+	if len(responses) != 3 || string(responses[0].ID) != "1" || string(responses[1].ID) != "null" || string(responses[2].ID) != `"b"` {
+		t.Fatalf("unexpected batch response: %s", out.Bytes())
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != transport.CodeInvalidRequest {
+		t.Errorf("invalid batch element not rejected: %s", out.Bytes())
+	}
+
+	// A batch of notifications gets no response at all; malformed lines and empty
+	// batches are answered for a null ID
+	io.WriteString(inW, `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`+"\n")
+	for line, code := range map[string]int{`{"jsonrpc":`: transport.CodeParseError, `[]`: transport.CodeInvalidRequest} {
+		io.WriteString(inW, line+"\n")
+		if !out.Scan() {
+			t.Fatal("no response")
+		}
+		var resp transport.Message
+		json.Unmarshal(out.Bytes(), &resp)
+		if string(resp.ID) != "null" || resp.Error == nil || resp.Error.Code != code {
+			t.Errorf("got %s, want error %d for a null ID", out.Bytes(), code)
+		}
+	}
+
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Start = %v", err)
+	}
+}
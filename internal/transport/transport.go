@@ -37,5 +37,39 @@ type Transport interface {
 	Close() error
 }
 
+// RequestSender is implemented by transports that can deliver server-initiated
+// requests (such as elicitation) to the client while a call is in progress
+type RequestSender interface {
+	CanSendRequests() bool
+}
+
+// ContextWriter is implemented by transports that serve several clients, so a message
+// sent while handling a request reaches that request's client. Messages for a context
+// without a client go to every client.
+type ContextWriter interface {
+	// WriteMessageFor sends a message to the client of the request handled in ctx
+	WriteMessageFor(ctx context.Context, msg *Message) error
+
+	// CanSendRequestsFor reports whether that client can receive server-initiated requests
+	CanSendRequestsFor(ctx context.Context) bool
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context for messages of the given transport session
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// SessionFromContext returns the transport session of a message, or "" for transports
+// that serve a single client
+func SessionFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
 // Handler processes incoming messages and returns responses
 type Handler func(ctx context.Context, msg *Message) (*Message, error)